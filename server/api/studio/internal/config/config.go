@@ -46,6 +46,10 @@ type Config struct {
 	File        struct {
 		UploadDir string
 		TasksDir  string
+		// ChunkSize is the most bytes of a chunk of the chunked uploads
+		ChunkSize int64 `json:",default=8388608"`
+		// UploadExpire is the seconds after the last chunk that the chunked upload not completed is removed
+		UploadExpire int64 `json:",default=86400"`
 	} `json:",optional"`
 
 	WebSocket struct {
@@ -81,12 +85,66 @@ type Config struct {
 		MaxIdleConns              int    `json:",default=10"`
 	}
 
+	Import struct {
+		// AutoResume resumes the tasks interrupted by the last restart when the service starts
+		AutoResume bool `json:",default=false"`
+		// MaxRunningTasks limits the tasks running at the same time, the others wait in the queue, 0 means no limit
+		MaxRunningTasks int `json:",default=0"`
+		// MaxRunningTasksPerAddress limits the tasks running against the same graphd at the same time, 0 means no limit
+		MaxRunningTasksPerAddress int `json:",default=0"`
+	} `json:",optional"`
+
 	LLM struct {
 		GQLPath        string `json:",default=./data/llm"`
 		GQLBatchSize   int    `json:",default=100"`
 		MaxBlockSize   int    `json:",default=0"`
 		PromptTemplate string `json:",default="`
 	} `json:",optional"`
+
+	Datasource struct {
+		// LocalRoots are the dirs of the server which the local datasources and the sqlite files can read, e.g. the mounted NFS shares, no dir can be read if it is empty
+		LocalRoots []string `json:",optional"`
+		// StoreIdleTimeout (second) closes the connection of the datasource browsed which is not used for it, 0 means the connections are not reused
+		StoreIdleTimeout int64 `json:",default=300"`
+		// StoreCheckInterval (second) is the time after the last check that the connection reused is checked to be alive, 0 means it is checked every time
+		StoreCheckInterval int64 `json:",default=30"`
+	} `json:",optional"`
+
+	Webhook WebhookConfig `json:",optional"`
+
+	Secrets SecretsConfig `json:",optional"`
+}
+
+type SecretsConfig struct {
+	// Key is the master key encrypting the secrets saved, e.g. the passwords of the datasources and the keys of the LLM APIs,
+	// the environment variable STUDIO_SECRETS_KEY is preferred to it
+	Key string `json:",optional"`
+	// KeyFile is the file of the master key, which is read if there is no key
+	KeyFile string `json:",optional"`
+	// PreviousKeys are the master keys before the rotation, which decrypt the secrets until they are rotated
+	PreviousKeys []string `json:",optional"`
+}
+
+type WebhookConfig struct {
+	// Global are the webhooks notified of the events of all the users
+	Global []WebhookTarget `json:",optional"`
+	// AllowedHosts are the hosts or the CIDRs in the internal network which the webhooks of the users can reach,
+	// the loopback, private and link-local addresses are refused for the webhooks of the users without them
+	AllowedHosts []string `json:",optional"`
+	// MaxRetries is the retries after the first delivery fails
+	MaxRetries int `json:",default=3"`
+	// RetryInterval (second) is the wait before the first retry, which is doubled after each retry
+	RetryInterval int64 `json:",default=5"`
+	// Timeout (second) of each request
+	Timeout int64 `json:",default=10"`
+}
+
+type WebhookTarget struct {
+	URL string
+	// Secret signs the requests by HMAC-SHA256, the requests are not signed without it
+	Secret string `json:",optional"`
+	// Events are the patterns of the events notified, e.g. task.* or llm_job.failed, all the events are notified if it is empty
+	Events []string `json:",optional"`
 }
 
 type PathValidator struct {
@@ -5,14 +5,18 @@ import (
 	"net/http"
 
 	datasource "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/datasource"
+	exporttask "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/exporttask"
 	favorite "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/favorite"
 	file "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/file"
 	gateway "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/gateway"
 	health "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/health"
+	importschedule "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/importschedule"
 	importtask "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/importtask"
 	llm "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/llm"
 	schema "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/schema"
 	sketches "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/sketches"
+	stagetask "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/stagetask"
+	webhook "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler/webhook"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
 
 	"github.com/zeromicro/go-zero/rest"
@@ -83,6 +87,36 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/files/update",
 				Handler: file.FileConfigUpdateHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/files/preview",
+				Handler: file.FilePreviewHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/files/uploads",
+				Handler: file.FileUploadInitHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/files/uploads/:id",
+				Handler: file.FileUploadStatusHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/api/files/uploads/:id",
+				Handler: file.FileUploadChunkHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/files/uploads/:id/complete",
+				Handler: file.FileUploadCompleteHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/files/uploads/:id",
+				Handler: file.FileUploadAbortHandler(serverCtx),
+			},
 		},
 	)
 
@@ -93,6 +127,16 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/import-tasks",
 				Handler: importtask.CreateImportTaskHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/import-tasks/validate",
+				Handler: importtask.ValidateImportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/import-tasks/mapping-suggestion",
+				Handler: importtask.SuggestImportMappingHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodPost,
 				Path:    "/api/import-tasks/draft",
@@ -148,6 +192,61 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/import-tasks/working-dir",
 				Handler: importtask.GetWorkingDirHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/import-tasks/ngql",
+				Handler: importtask.CreateNGQLImportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/import-tasks/:id/resume",
+				Handler: importtask.ResumeImportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/import-tasks/:id/retry",
+				Handler: importtask.RetryImportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/import-tasks/:id/clone",
+				Handler: importtask.CloneImportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-tasks/:id/failed-files",
+				Handler: importtask.GetImportTaskFailedFilesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-tasks/:id/failed-files/preview",
+				Handler: importtask.PreviewImportTaskFailedFileHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-tasks/:id/download-failed-file",
+				Handler: importtask.DownloadImportTaskFailedFileHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-tasks/:id/download-failed-files",
+				Handler: importtask.DownloadImportTaskFailedFilesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/import-tasks/:id/failed-files/reimport",
+				Handler: importtask.ReimportImportTaskFailedFilesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-tasks/:id/metrics",
+				Handler: importtask.GetImportTaskMetricsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-tasks/:id/report",
+				Handler: importtask.GetImportTaskReportHandler(serverCtx),
+			},
 		},
 	)
 
@@ -253,6 +352,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/datasources/:id/file-preview",
 				Handler: datasource.DatasourcePreviewFileHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/datasources/:id/rows-preview",
+				Handler: datasource.DatasourcePreviewRowsHandler(serverCtx),
+			},
 		},
 	)
 
@@ -305,4 +409,149 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 			},
 		},
 	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/export-tasks",
+				Handler: exporttask.CreateExportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/export-tasks/:id",
+				Handler: exporttask.GetExportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/export-tasks",
+				Handler: exporttask.GetManyExportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/export-tasks/:id/stop",
+				Handler: exporttask.StopExportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/export-tasks/:id/resume",
+				Handler: exporttask.ResumeExportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/export-tasks/:id",
+				Handler: exporttask.DeleteExportTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/export-tasks/:id/logs",
+				Handler: exporttask.GetExportTaskLogHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/export-tasks/:id/download",
+				Handler: exporttask.DownloadExportTaskHandler(serverCtx),
+			},
+		},
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/import-schedules",
+				Handler: importschedule.CreateImportScheduleHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/api/import-schedules/:id",
+				Handler: importschedule.UpdateImportScheduleHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-schedules/:id",
+				Handler: importschedule.GetImportScheduleHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-schedules",
+				Handler: importschedule.GetManyImportScheduleHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/import-schedules/:id",
+				Handler: importschedule.DeleteImportScheduleHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/import-schedules/:id/runs",
+				Handler: importschedule.GetImportScheduleRunsHandler(serverCtx),
+			},
+		},
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/webhooks",
+				Handler: webhook.CreateWebhookHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/api/webhooks/:id",
+				Handler: webhook.UpdateWebhookHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/webhooks",
+				Handler: webhook.GetManyWebhookHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/webhooks/:id",
+				Handler: webhook.DeleteWebhookHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/webhook-deliveries",
+				Handler: webhook.GetWebhookDeliveriesHandler(serverCtx),
+			},
+		},
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/stage-tasks",
+				Handler: stagetask.CreateStageTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/stage-tasks/:id",
+				Handler: stagetask.GetStageTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/stage-tasks",
+				Handler: stagetask.GetManyStageTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/stage-tasks/:id/stop",
+				Handler: stagetask.StopStageTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/stage-tasks/:id/resume",
+				Handler: stagetask.ResumeStageTaskHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/stage-tasks/:id",
+				Handler: stagetask.DeleteStageTaskHandler(serverCtx),
+			},
+		},
+	)
 }
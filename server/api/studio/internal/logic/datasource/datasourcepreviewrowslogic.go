@@ -0,0 +1,29 @@
+package datasource
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DatasourcePreviewRowsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDatasourcePreviewRowsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DatasourcePreviewRowsLogic {
+	return &DatasourcePreviewRowsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DatasourcePreviewRowsLogic) DatasourcePreviewRows(req types.DatasourcePreviewRowsRequest) (resp *types.DatasourcePreviewRowsData, err error) {
+	return service.NewDatasourceService(l.ctx, l.svcCtx).PreviewRows(&req)
+}
@@ -0,0 +1,29 @@
+package exporttask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteExportTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteExportTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteExportTaskLogic {
+	return &DeleteExportTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteExportTaskLogic) DeleteExportTask(req types.DeleteExportTaskRequest) error {
+	return service.NewExportService(l.ctx, l.svcCtx).DeleteExportTask(&req)
+}
@@ -0,0 +1,29 @@
+package exporttask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DownloadExportTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDownloadExportTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadExportTaskLogic {
+	return &DownloadExportTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DownloadExportTaskLogic) DownloadExportTask(req types.DownloadExportTaskRequest) error {
+	return service.NewExportService(l.ctx, l.svcCtx).DownloadExportTask(&req)
+}
@@ -0,0 +1,29 @@
+package exporttask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetExportTaskLogLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetExportTaskLogLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetExportTaskLogLogic {
+	return &GetExportTaskLogLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetExportTaskLogLogic) GetExportTaskLog(req types.GetExportTaskLogRequest) (resp *types.GetExportTaskLogData, err error) {
+	return service.NewExportService(l.ctx, l.svcCtx).GetExportTaskLog(&req)
+}
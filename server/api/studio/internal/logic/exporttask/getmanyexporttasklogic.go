@@ -0,0 +1,29 @@
+package exporttask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetManyExportTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetManyExportTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetManyExportTaskLogic {
+	return &GetManyExportTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetManyExportTaskLogic) GetManyExportTask(req types.GetManyExportTaskRequest) (resp *types.GetManyExportTaskData, err error) {
+	return service.NewExportService(l.ctx, l.svcCtx).GetManyExportTask(&req)
+}
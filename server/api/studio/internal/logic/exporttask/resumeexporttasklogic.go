@@ -0,0 +1,29 @@
+package exporttask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ResumeExportTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResumeExportTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResumeExportTaskLogic {
+	return &ResumeExportTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ResumeExportTaskLogic) ResumeExportTask(req types.ResumeExportTaskRequest) error {
+	return service.NewExportService(l.ctx, l.svcCtx).ResumeExportTask(&req)
+}
@@ -0,0 +1,29 @@
+package file
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type FilePreviewLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFilePreviewLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FilePreviewLogic {
+	return &FilePreviewLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FilePreviewLogic) FilePreview(req types.FilePreviewRequest) (resp *types.FilePreviewData, err error) {
+	return service.NewFileService(l.ctx, l.svcCtx).FilePreview(req)
+}
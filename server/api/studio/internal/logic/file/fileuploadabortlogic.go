@@ -0,0 +1,29 @@
+package file
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type FileUploadAbortLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFileUploadAbortLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FileUploadAbortLogic {
+	return &FileUploadAbortLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FileUploadAbortLogic) FileUploadAbort(req types.FileUploadAbortRequest) error {
+	return service.NewFileService(l.ctx, l.svcCtx).FileUploadAbort(req)
+}
@@ -0,0 +1,29 @@
+package file
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type FileUploadStatusLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFileUploadStatusLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FileUploadStatusLogic {
+	return &FileUploadStatusLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FileUploadStatusLogic) FileUploadStatus(req types.FileUploadStatusRequest) (resp *types.FileUploadData, err error) {
+	return service.NewFileService(l.ctx, l.svcCtx).FileUploadStatus(req)
+}
@@ -0,0 +1,29 @@
+package importschedule
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateImportScheduleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateImportScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateImportScheduleLogic {
+	return &CreateImportScheduleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateImportScheduleLogic) CreateImportSchedule(req types.CreateImportScheduleRequest) (resp *types.CreateImportScheduleData, err error) {
+	return service.NewImportScheduleService(l.ctx, l.svcCtx).CreateImportSchedule(&req)
+}
@@ -0,0 +1,29 @@
+package importschedule
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteImportScheduleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteImportScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteImportScheduleLogic {
+	return &DeleteImportScheduleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteImportScheduleLogic) DeleteImportSchedule(req types.DeleteImportScheduleRequest) error {
+	return service.NewImportScheduleService(l.ctx, l.svcCtx).DeleteImportSchedule(&req)
+}
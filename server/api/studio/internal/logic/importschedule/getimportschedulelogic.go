@@ -0,0 +1,29 @@
+package importschedule
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetImportScheduleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetImportScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetImportScheduleLogic {
+	return &GetImportScheduleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetImportScheduleLogic) GetImportSchedule(req types.GetImportScheduleRequest) (resp *types.ImportScheduleData, err error) {
+	return service.NewImportScheduleService(l.ctx, l.svcCtx).GetImportSchedule(&req)
+}
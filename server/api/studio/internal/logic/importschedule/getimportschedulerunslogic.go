@@ -0,0 +1,29 @@
+package importschedule
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetImportScheduleRunsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetImportScheduleRunsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetImportScheduleRunsLogic {
+	return &GetImportScheduleRunsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetImportScheduleRunsLogic) GetImportScheduleRuns(req types.GetImportScheduleRunsRequest) (resp *types.GetManyImportTaskData, err error) {
+	return service.NewImportScheduleService(l.ctx, l.svcCtx).GetImportScheduleRuns(&req)
+}
@@ -0,0 +1,29 @@
+package importschedule
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetManyImportScheduleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetManyImportScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetManyImportScheduleLogic {
+	return &GetManyImportScheduleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetManyImportScheduleLogic) GetManyImportSchedule(req types.GetManyImportScheduleRequest) (resp *types.GetManyImportScheduleData, err error) {
+	return service.NewImportScheduleService(l.ctx, l.svcCtx).GetManyImportSchedule(&req)
+}
@@ -0,0 +1,29 @@
+package importschedule
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateImportScheduleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateImportScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateImportScheduleLogic {
+	return &UpdateImportScheduleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateImportScheduleLogic) UpdateImportSchedule(req types.UpdateImportScheduleRequest) error {
+	return service.NewImportScheduleService(l.ctx, l.svcCtx).UpdateImportSchedule(&req)
+}
@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CloneImportTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCloneImportTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CloneImportTaskLogic {
+	return &CloneImportTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CloneImportTaskLogic) CloneImportTask(req types.CloneImportTaskRequest) (resp *types.CreateImportTaskData, err error) {
+	return service.NewImportService(l.ctx, l.svcCtx).CloneImportTask(&req)
+}
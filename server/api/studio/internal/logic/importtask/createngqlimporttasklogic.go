@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateNGQLImportTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateNGQLImportTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateNGQLImportTaskLogic {
+	return &CreateNGQLImportTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateNGQLImportTaskLogic) CreateNGQLImportTask(req types.CreateNGQLImportTaskRequest) (resp *types.CreateImportTaskData, err error) {
+	return service.NewImportService(l.ctx, l.svcCtx).CreateNGQLImportTask(&req)
+}
@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DownloadImportTaskFailedFileLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDownloadImportTaskFailedFileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadImportTaskFailedFileLogic {
+	return &DownloadImportTaskFailedFileLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DownloadImportTaskFailedFileLogic) DownloadImportTaskFailedFile(req types.DownloadImportTaskFailedFileRequest) error {
+	return service.NewImportService(l.ctx, l.svcCtx).DownloadImportTaskFailedFile(&req)
+}
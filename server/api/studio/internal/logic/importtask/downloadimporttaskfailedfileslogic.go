@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DownloadImportTaskFailedFilesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDownloadImportTaskFailedFilesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadImportTaskFailedFilesLogic {
+	return &DownloadImportTaskFailedFilesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DownloadImportTaskFailedFilesLogic) DownloadImportTaskFailedFiles(req types.DownloadImportTaskFailedFilesRequest) error {
+	return service.NewImportService(l.ctx, l.svcCtx).DownloadImportTaskFailedFiles(&req)
+}
@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetImportTaskFailedFilesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetImportTaskFailedFilesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetImportTaskFailedFilesLogic {
+	return &GetImportTaskFailedFilesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetImportTaskFailedFilesLogic) GetImportTaskFailedFiles(req types.GetImportTaskFailedFilesRequest) (resp *types.GetImportTaskFailedFilesData, err error) {
+	return service.NewImportService(l.ctx, l.svcCtx).GetImportTaskFailedFiles(&req)
+}
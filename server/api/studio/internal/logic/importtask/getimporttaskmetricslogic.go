@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetImportTaskMetricsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetImportTaskMetricsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetImportTaskMetricsLogic {
+	return &GetImportTaskMetricsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetImportTaskMetricsLogic) GetImportTaskMetrics(req types.GetImportTaskMetricsRequest) (resp *types.GetImportTaskMetricsData, err error) {
+	return service.NewImportService(l.ctx, l.svcCtx).GetImportTaskMetrics(&req)
+}
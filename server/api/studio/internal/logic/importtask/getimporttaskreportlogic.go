@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetImportTaskReportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetImportTaskReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetImportTaskReportLogic {
+	return &GetImportTaskReportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetImportTaskReportLogic) GetImportTaskReport(req types.GetImportTaskReportRequest) (resp *types.GetImportTaskReportData, err error) {
+	return service.NewImportService(l.ctx, l.svcCtx).GetImportTaskReport(&req)
+}
@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PreviewImportTaskFailedFileLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPreviewImportTaskFailedFileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PreviewImportTaskFailedFileLogic {
+	return &PreviewImportTaskFailedFileLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *PreviewImportTaskFailedFileLogic) PreviewImportTaskFailedFile(req types.PreviewImportTaskFailedFileRequest) (resp *types.PreviewImportTaskFailedFileData, err error) {
+	return service.NewImportService(l.ctx, l.svcCtx).PreviewImportTaskFailedFile(&req)
+}
@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ReimportImportTaskFailedFilesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReimportImportTaskFailedFilesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReimportImportTaskFailedFilesLogic {
+	return &ReimportImportTaskFailedFilesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ReimportImportTaskFailedFilesLogic) ReimportImportTaskFailedFiles(req types.ReimportImportTaskFailedFilesRequest) (resp *types.CreateImportTaskData, err error) {
+	return service.NewImportService(l.ctx, l.svcCtx).ReimportImportTaskFailedFiles(&req)
+}
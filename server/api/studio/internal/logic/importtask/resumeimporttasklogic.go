@@ -0,0 +1,29 @@
+package importtask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ResumeImportTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResumeImportTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResumeImportTaskLogic {
+	return &ResumeImportTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ResumeImportTaskLogic) ResumeImportTask(req types.ResumeImportTaskRequest) (resp *types.CreateImportTaskData, err error) {
+	return service.NewImportService(l.ctx, l.svcCtx).ResumeImportTask(&req)
+}
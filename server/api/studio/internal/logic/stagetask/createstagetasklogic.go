@@ -0,0 +1,29 @@
+package stagetask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateStageTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateStageTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateStageTaskLogic {
+	return &CreateStageTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateStageTaskLogic) CreateStageTask(req types.CreateStageTaskRequest) (resp *types.CreateStageTaskData, err error) {
+	return service.NewStageService(l.ctx, l.svcCtx).CreateStageTask(&req)
+}
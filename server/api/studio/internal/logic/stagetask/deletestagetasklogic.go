@@ -0,0 +1,29 @@
+package stagetask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteStageTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteStageTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteStageTaskLogic {
+	return &DeleteStageTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteStageTaskLogic) DeleteStageTask(req types.DeleteStageTaskRequest) error {
+	return service.NewStageService(l.ctx, l.svcCtx).DeleteStageTask(&req)
+}
@@ -0,0 +1,29 @@
+package stagetask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetManyStageTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetManyStageTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetManyStageTaskLogic {
+	return &GetManyStageTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetManyStageTaskLogic) GetManyStageTask(req types.GetManyStageTaskRequest) (resp *types.GetManyStageTaskData, err error) {
+	return service.NewStageService(l.ctx, l.svcCtx).GetManyStageTask(&req)
+}
@@ -0,0 +1,29 @@
+package stagetask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetStageTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetStageTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetStageTaskLogic {
+	return &GetStageTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetStageTaskLogic) GetStageTask(req types.GetStageTaskRequest) (resp *types.GetStageTaskData, err error) {
+	return service.NewStageService(l.ctx, l.svcCtx).GetStageTask(&req)
+}
@@ -0,0 +1,29 @@
+package stagetask
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ResumeStageTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResumeStageTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResumeStageTaskLogic {
+	return &ResumeStageTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ResumeStageTaskLogic) ResumeStageTask(req types.ResumeStageTaskRequest) error {
+	return service.NewStageService(l.ctx, l.svcCtx).ResumeStageTask(&req)
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetManyWebhookLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetManyWebhookLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetManyWebhookLogic {
+	return &GetManyWebhookLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetManyWebhookLogic) GetManyWebhook(req types.GetManyWebhookRequest) (resp *types.GetManyWebhookData, err error) {
+	return service.NewWebhookService(l.ctx, l.svcCtx).GetManyWebhook(&req)
+}
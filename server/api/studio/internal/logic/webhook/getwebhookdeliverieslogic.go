@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetWebhookDeliveriesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetWebhookDeliveriesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetWebhookDeliveriesLogic {
+	return &GetWebhookDeliveriesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetWebhookDeliveriesLogic) GetWebhookDeliveries(req types.GetWebhookDeliveriesRequest) (resp *types.GetWebhookDeliveriesData, err error) {
+	return service.NewWebhookService(l.ctx, l.svcCtx).GetWebhookDeliveries(&req)
+}
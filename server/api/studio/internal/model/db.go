@@ -115,13 +115,20 @@ func InitDB(config *config.Config, db *gorm.DB) {
 		err = db.AutoMigrate(
 			&Datasource{},
 			&TaskInfo{},
+			&TaskCheckpoint{},
+			&TaskSample{},
+			&TaskReport{},
 			&TaskEffect{},
+			&ImportSchedule{},
 			&Sketch{},
 			&SchemaSnapshot{},
 			&Favorite{},
 			&File{},
+			&FileUpload{},
 			&LLMConfig{},
 			&LLMJob{},
+			&Webhook{},
+			&WebhookDelivery{},
 		)
 		if err != nil {
 			zap.L().Fatal(fmt.Sprintf("init taskInfo table fail: %s", err))
@@ -10,8 +10,31 @@ type File struct {
 	Name       string    `gorm:"column:name;type:varchar(128);unique"`
 	WithHeader bool      `gorm:"column:with_header;type:boolean;default:false;"`
 	Delimiter  string    `gorm:"column:delimiter;default:',';"`
+	Quote      string    `gorm:"column:quote;type:varchar(8);comment:detected quote of the fields"`
+	Encoding   string    `gorm:"column:encoding;type:varchar(32);comment:detected encoding"`
+	Columns    string    `gorm:"column:columns;type:text;comment:detected names and types of the columns in json"`
 	Host       string    `gorm:"column:host;type:varchar(128);not null"`
 	Username   string    `gorm:"column:username;type:varchar(128);not null"`
 	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime"`
 	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime"`
 }
+
+// FileUpload is the chunked upload in progress, the chunks are appended to the part file until it is completed
+type FileUpload struct {
+	ID   int    `gorm:"column:id;primaryKey;autoIncrement"`
+	BID  string `gorm:"column:b_id;not null;type:char(32);uniqueIndex;comment:upload id"`
+	Name string `gorm:"column:name;type:varchar(128);not null"`
+	Size int64  `gorm:"column:size;not null"`
+	// Offset is the bytes received, the part file is checked against it
+	Offset    int64  `gorm:"column:byte_offset;not null;default:0"`
+	ChunkSize int64  `gorm:"column:chunk_size;not null"`
+	Checksum  string `gorm:"column:checksum;type:varchar(64);comment:sha256 of the whole file given by the client"`
+	// HasConfig tells whether the settings are given, they are detected on completion otherwise
+	HasConfig  bool      `gorm:"column:has_config;type:boolean;default:false;"`
+	WithHeader bool      `gorm:"column:with_header;type:boolean;default:false;"`
+	Delimiter  string    `gorm:"column:delimiter;type:varchar(8)"`
+	Host       string    `gorm:"column:host;type:varchar(128);not null"`
+	Username   string    `gorm:"column:username;type:varchar(128);not null"`
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime"`
+	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime;index"`
+}
@@ -17,6 +17,7 @@ type LLMJob struct {
 	JobType    string         `json:"job_type"`
 	Status     base.LLMStatus `json:"status"`
 	UserPrompt string         `json:"user_prompt"`
+	FilePath   string         `json:"file_path"`
 	Process    datatypes.JSON `json:"process"`
 	CreateTime time.Time      `json:"create_time" gorm:"column:create_time;type:datetime;autoCreateTime"`
 	UpdateTime time.Time      `json:"update_ime" gorm:"column:update_time;type:datetime;autoUpdateTime"`
@@ -9,6 +9,7 @@ type APIType string
 
 const OpenAI APIType = "openai"
 
+// LLMConfig is the LLM API of the user, the key is encrypted
 type LLMConfig struct {
 	ID                 int     `json:"" gorm:"primaryKey;autoIncrement"`
 	URL                string  `json:"url"`
@@ -0,0 +1,37 @@
+package db
+
+import "time"
+
+const (
+	OverlapPolicySkip  = "skip"
+	OverlapPolicyQueue = "queue"
+)
+
+// ImportSchedule creates a new import task from the saved config each time the cron expression fires
+type ImportSchedule struct {
+	ID           int    `gorm:"column:id;primaryKey;autoIncrement;"`
+	BID          string `gorm:"column:b_id;not null;type:char(32);uniqueIndex;comment:schedule id"`
+	Name         string `gorm:"column:name;type:varchar(255);"`
+	Address      string `gorm:"column:address;type:varchar(255);"`
+	User         string `gorm:"column:user;"`
+	Space        string `gorm:"column:space;type:varchar(255);"`
+	TaskType     string `gorm:"column:task_type;type:varchar(32);default:import;"`
+	SourceTaskID string `gorm:"column:source_task_id;type:char(32);comment:the draft or task the config is saved from"`
+	RawConfig    string `gorm:"column:raw_config;type:mediumtext;"`
+	Config       string `gorm:"column:config;type:mediumtext;comment:encrypted config of the import task"`
+	Secret       string `gorm:"column:secret;type:varchar(255);comment:encrypted password of the nebula user"`
+
+	CronExpr      string `gorm:"column:cron_expr;type:varchar(255);not null"`
+	TimeZone      string `gorm:"column:time_zone;type:varchar(64);"`
+	OverlapPolicy string `gorm:"column:overlap_policy;type:varchar(32);default:skip;"`
+	Enabled       bool   `gorm:"column:enabled;"`
+	// Queued is set when a run is waiting for the last run to finish
+	Queued      bool       `gorm:"column:queued;"`
+	NextRunTime time.Time  `gorm:"column:next_run_time;type:datetime;index"`
+	LastRunTime *time.Time `gorm:"column:last_run_time;type:datetime"`
+	LastTaskID  string     `gorm:"column:last_task_id;type:char(32);"`
+	LastMessage string     `gorm:"column:last_message;type:text;"`
+
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime"`
+	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime"`
+}
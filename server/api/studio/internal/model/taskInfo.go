@@ -4,6 +4,13 @@ import (
 	"time"
 )
 
+const (
+	TaskTypeImport = "import"
+	TaskTypeExport = "export"
+	TaskTypeNGQL   = "ngql"
+	TaskTypeStage  = "stage"
+)
+
 type Stats struct {
 	ProcessedBytes  int64         `gorm:"column:processed_bytes;"`
 	TotalBytes      int64         `gorm:"column:total_bytes;"`
@@ -31,6 +38,11 @@ type TaskInfo struct {
 	TaskMessage   string `gorm:"column:task_message;"`
 	Stats         Stats  `gorm:"embedded"`
 	RawConfig     string `gorm:"column:raw_config;type:mediumtext;"`
+	TaskType      string `gorm:"column:task_type;type:varchar(32);default:import;"`
+	ScheduleID    string `gorm:"column:schedule_id;type:varchar(32);index;comment:the schedule which creates the task"`
+	ParentTaskID  string `gorm:"column:parent_task_id;type:varchar(32);index;comment:the task which is resumed, retried or cloned by the task"`
+	RunType       string `gorm:"column:run_type;type:varchar(32);comment:resume, retry or clone"`
+	Priority      int    `gorm:"column:priority;default:0;comment:the task with higher priority leaves the queue earlier"`
 
 	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime"`
 	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime"`
@@ -42,6 +54,25 @@ type TaskEffect struct {
 	BID    string `gorm:"column:task_id;not null;type:char(32);uniqueIndex;comment:task id"`
 	Log    string `gorm:"column:log;type:mediumtext;comment:partial task log"`
 	Config string `gorm:"column:config;type:mediumtext;comment:task config.yaml"`
+	// ImportConfig is the encrypted config of the request, which is used to resume or retry the task
+	ImportConfig string `gorm:"column:import_config;type:mediumtext;comment:encrypted import config"`
+	// Secret is the encrypted password of the user, which is used to resume the task after the service restarts
+	Secret string `gorm:"column:secret;type:varchar(255);comment:encrypted password of the nebula user"`
 
 	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime"`
 }
+
+// TaskCheckpoint records how far a task has gone on one unit of its work,
+// e.g. one tag of an export task, so that the task can be resumed later.
+type TaskCheckpoint struct {
+	ID         int    `gorm:"column:id;primaryKey;autoIncrement;"`
+	TaskID     string `gorm:"column:task_id;not null;type:char(32);uniqueIndex:idx_task_checkpoint;comment:task id"`
+	Name       string `gorm:"column:name;not null;type:varchar(255);uniqueIndex:idx_task_checkpoint;comment:unit of work"`
+	Cursor     string `gorm:"column:cursor_value;type:text;comment:position to continue from"`
+	Offset     int64  `gorm:"column:byte_offset;comment:bytes written or consumed"`
+	Records    int64  `gorm:"column:records;"`
+	Failed     int64  `gorm:"column:failed_records;"`
+	IsFinished bool   `gorm:"column:is_finished;"`
+
+	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime"`
+}
@@ -0,0 +1,42 @@
+package db
+
+import "time"
+
+// TaskSample is the cumulative stats of a running task at a time, the rates are the differences between the samples
+type TaskSample struct {
+	ID             int    `gorm:"column:id;primaryKey;autoIncrement;"`
+	TaskID         string `gorm:"column:task_id;not null;type:char(32);index:idx_task_sample;comment:task id"`
+	Time           int64  `gorm:"column:sample_time;index:idx_task_sample;comment:unix milliseconds"`
+	ProcessedBytes int64  `gorm:"column:processed_bytes;"`
+	TotalRecords   int64  `gorm:"column:total_records;"`
+	FailedRecords  int64  `gorm:"column:failed_records;"`
+	TotalRequest   int64  `gorm:"column:total_request;"`
+	FailedRequest  int64  `gorm:"column:failed_request;"`
+	// TotalLatency is the sum of the latency of the requests in microseconds
+	TotalLatency int64 `gorm:"column:total_latency;"`
+}
+
+// TaskReport is the performance summary of a finished task, which is compared with the previous runs of the same config
+type TaskReport struct {
+	ID         int    `gorm:"column:id;primaryKey;autoIncrement;"`
+	TaskID     string `gorm:"column:task_id;not null;type:char(32);uniqueIndex;comment:task id"`
+	Address    string `gorm:"column:address;type:varchar(255);index:idx_task_report_config"`
+	User       string `gorm:"column:user;type:varchar(128);index:idx_task_report_config"`
+	ConfigHash string `gorm:"column:config_hash;type:char(64);index:idx_task_report_config;comment:sha256 of the raw config"`
+	TaskStatus string `gorm:"column:task_status;"`
+	StartTime  int64  `gorm:"column:start_time;comment:unix milliseconds"`
+	// Duration is the running time in milliseconds, the time in the queue is excluded
+	Duration          int64   `gorm:"column:duration;"`
+	Records           int64   `gorm:"column:records;"`
+	Bytes             int64   `gorm:"column:bytes;"`
+	FailedRecords     int64   `gorm:"column:failed_records;"`
+	RowsPerSecond     float64 `gorm:"column:rows_per_second;"`
+	BytesPerSecond    float64 `gorm:"column:bytes_per_second;"`
+	PeakRowsPerSecond float64 `gorm:"column:peak_rows_per_second;"`
+	LatencyP50        int64   `gorm:"column:latency_p50;"`
+	LatencyP90        int64   `gorm:"column:latency_p90;"`
+	LatencyP99        int64   `gorm:"column:latency_p99;"`
+	FailureRate       float64 `gorm:"column:failure_rate;"`
+
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime"`
+}
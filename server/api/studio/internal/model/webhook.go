@@ -0,0 +1,45 @@
+package db
+
+import "time"
+
+const (
+	WebhookDeliveryPending = "pending"
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryFailed  = "failed"
+)
+
+// Webhook is notified of the events of the tasks and the jobs of its user
+type Webhook struct {
+	ID       int    `gorm:"column:id;primaryKey;autoIncrement;"`
+	BID      string `gorm:"column:b_id;not null;type:char(32);uniqueIndex;comment:webhook id"`
+	Name     string `gorm:"column:name;type:varchar(255);"`
+	Host     string `gorm:"column:host;type:varchar(128);not null;index:idx_webhook_user"`
+	Username string `gorm:"column:username;type:varchar(128);not null;index:idx_webhook_user"`
+	URL      string `gorm:"column:url;type:text;not null"`
+	Secret   string `gorm:"column:secret;type:text;comment:encrypted secret to sign the requests"`
+	// Events are the patterns of the events notified, e.g. task.* or llm_job.failed, all the events are notified if it is empty
+	Events  string `gorm:"column:events;type:text;comment:comma separated event patterns"`
+	Enabled bool   `gorm:"column:enabled;"`
+
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime"`
+	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime"`
+}
+
+// WebhookDelivery is the log of an event sent to a webhook, the webhook id is empty for the global webhooks in the config
+type WebhookDelivery struct {
+	ID           int    `gorm:"column:id;primaryKey;autoIncrement;"`
+	BID          string `gorm:"column:b_id;not null;type:char(32);uniqueIndex;comment:delivery id"`
+	WebhookID    string `gorm:"column:webhook_id;type:char(32);index"`
+	Host         string `gorm:"column:host;type:varchar(128);index:idx_webhook_delivery_user"`
+	Username     string `gorm:"column:username;type:varchar(128);index:idx_webhook_delivery_user"`
+	URL          string `gorm:"column:url;type:text;"`
+	Event        string `gorm:"column:event;type:varchar(64);"`
+	Payload      string `gorm:"column:payload;type:text;"`
+	Status       string `gorm:"column:status;type:varchar(32);"`
+	Attempts     int    `gorm:"column:attempts;"`
+	ResponseCode int    `gorm:"column:response_code;"`
+	Error        string `gorm:"column:error;type:text;"`
+
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime"`
+	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime"`
+}
@@ -1,11 +1,22 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
@@ -13,7 +24,10 @@ import (
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/preview"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+	"github.com/xitongsys/parquet-go/source"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -26,6 +40,7 @@ type (
 		BatchRemove(request types.DatasourceBatchRemoveRequest) error
 		ListContents(request types.DatasourceListContentsRequest) (*types.DatasourceListContentsData, error)
 		PreviewFile(request types.DatasourcePreviewFileRequest) (*types.DatasourcePreviewFileData, error)
+		PreviewRows(request *types.DatasourcePreviewRowsRequest) (*types.DatasourcePreviewRowsData, error)
 	}
 
 	datasourceService struct {
@@ -36,9 +51,6 @@ type (
 	}
 )
 
-// TODO: make it configurable
-const cipher = "6b6579736f6d6574616c6b6579736f6d"
-
 func NewDatasourceService(ctx context.Context, svcCtx *svc.ServiceContext) DatasourceService {
 	return &datasourceService{
 		Logger:           logx.WithContext(ctx),
@@ -51,12 +63,34 @@ func NewDatasourceService(ctx context.Context, svcCtx *svc.ServiceContext) Datas
 func (d *datasourceService) Add(request types.DatasourceAddRequest) (*types.DatasourceAddData, error) {
 	typ := request.Type
 	platform := request.Platform
-	var cfg interface{}
+	var (
+		cfg interface{}
+		key filestore.SftpKey
+	)
 	switch typ {
 	case "s3":
 		cfg = request.S3Config
 	case "sftp":
+		if request.SFTPConfig == nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "sftpConfig is required")
+		}
 		cfg = request.SFTPConfig
+		key = filestore.SftpKey{PrivateKey: request.SFTPConfig.PrivateKey, Passphrase: request.SFTPConfig.Passphrase}
+	case "sql":
+		if request.SQLConfig == nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "sqlConfig is required")
+		}
+		cfg = request.SQLConfig
+	case "local":
+		if request.LocalConfig == nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "localConfig is required")
+		}
+		cfg = request.LocalConfig
+	case "http":
+		if request.HTTPConfig == nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "httpConfig is required")
+		}
+		cfg = request.HTTPConfig
 	default:
 		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "Invalid datasource type")
 	}
@@ -64,7 +98,7 @@ func (d *datasourceService) Add(request types.DatasourceAddRequest) (*types.Data
 	if err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
 	}
-	id, err := d.save(request.Type, request.Name, request.Platform, cfgStr, crypto)
+	id, err := d.save(request.Type, request.Name, request.Platform, cfgStr, crypto, key)
 	if err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
 	}
@@ -81,7 +115,10 @@ func (d *datasourceService) Update(request types.DatasourceUpdateRequest) error
 	}
 	typ := request.Type
 	platform := request.Platform
-	var cfg interface{}
+	var (
+		cfg interface{}
+		key filestore.SftpKey
+	)
 	switch typ {
 	case "s3":
 		s3Config := request.S3Config
@@ -97,14 +134,67 @@ func (d *datasourceService) Update(request types.DatasourceUpdateRequest) error
 		}
 	case "sftp":
 		sftpCfg := request.SFTPConfig
+		if sftpCfg == nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "sftpConfig is required")
+		}
 		if sftpCfg.Password == "" {
 			sftpCfg.Password = dbs.Secret
 		}
+		key = filestore.SftpKey{PrivateKey: sftpCfg.PrivateKey, Passphrase: sftpCfg.Passphrase}
+		if key.PrivateKey == "" {
+			key.PrivateKey = dbs.PrivateKey
+		}
+		if key.Passphrase == "" {
+			key.Passphrase = dbs.Passphrase
+		}
 		cfg = &types.DatasourceSFTPConfig{
-			Host:     sftpCfg.Host,
-			Port:     sftpCfg.Port,
-			Username: sftpCfg.Username,
-			Password: sftpCfg.Password,
+			Host:               sftpCfg.Host,
+			Port:               sftpCfg.Port,
+			Username:           sftpCfg.Username,
+			Password:           sftpCfg.Password,
+			PrivateKey:         key.PrivateKey,
+			Passphrase:         key.Passphrase,
+			HostKeyFingerprint: sftpCfg.HostKeyFingerprint,
+			KnownHosts:         sftpCfg.KnownHosts,
+		}
+	case "sql":
+		sqlCfg := request.SQLConfig
+		if sqlCfg == nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "sqlConfig is required")
+		}
+		if sqlCfg.Password == "" {
+			sqlCfg.Password = dbs.Secret
+		}
+		cfg = &types.DatasourceSQLConfig{
+			Driver:   sqlCfg.Driver,
+			Host:     sqlCfg.Host,
+			Port:     sqlCfg.Port,
+			Database: sqlCfg.Database,
+			Username: sqlCfg.Username,
+			Password: sqlCfg.Password,
+			Params:   sqlCfg.Params,
+		}
+	case "local":
+		if request.LocalConfig == nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "localConfig is required")
+		}
+		cfg = request.LocalConfig
+	case "http":
+		httpCfg := request.HTTPConfig
+		if httpCfg == nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "httpConfig is required")
+		}
+		// the password or the token is kept if it is not changed
+		if httpCfg.Password == "" && httpCfg.Token == "" {
+			httpCfg.Password, httpCfg.Token = dbs.Secret, dbs.Secret
+		}
+		cfg = &types.DatasourceHTTPConfig{
+			URL:      httpCfg.URL,
+			URLs:     httpCfg.URLs,
+			AuthType: httpCfg.AuthType,
+			Username: httpCfg.Username,
+			Password: httpCfg.Password,
+			Token:    httpCfg.Token,
 		}
 	default:
 		return ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "Invalid datasource type")
@@ -113,10 +203,11 @@ func (d *datasourceService) Update(request types.DatasourceUpdateRequest) error
 	if err != nil {
 		return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
 	}
-	err = d.update(datasourceId, request.Type, request.Platform, request.Name, cfgStr, crypto)
+	err = d.update(datasourceId, request.Type, request.Platform, request.Name, cfgStr, crypto, key)
 	if err != nil {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
+	invalidateDatasourceStore(d.svcCtx, datasourceId)
 	return nil
 }
 
@@ -155,6 +246,23 @@ func (d *datasourceService) List(request types.DatasourceListRequest) (*types.Da
 			if err := json.Unmarshal([]byte(jsonConfig), &config.SFTPConfig); err != nil {
 				return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "parse json failed")
 			}
+			config.SFTPConfig.HasPrivateKey = item.PrivateKey != ""
+		case "sql":
+			config.SQLConfig = &types.DatasourceSQLConfig{}
+			jsonConfig := item.Config
+			if err := json.Unmarshal([]byte(jsonConfig), &config.SQLConfig); err != nil {
+				return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "parse json failed")
+			}
+		case "local":
+			config.LocalConfig = &types.DatasourceLocalConfig{}
+			if err := json.Unmarshal([]byte(item.Config), &config.LocalConfig); err != nil {
+				return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "parse json failed")
+			}
+		case "http":
+			config.HTTPConfig = &types.DatasourceHTTPConfig{}
+			if err := json.Unmarshal([]byte(item.Config), &config.HTTPConfig); err != nil {
+				return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "parse json failed")
+			}
 		}
 		items = append(items, config)
 	}
@@ -175,6 +283,7 @@ func (d *datasourceService) Remove(request types.DatasourceRemoveRequest) error
 	if result.RowsAffected == 0 {
 		return ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("test"), "there is available item to delete")
 	}
+	invalidateDatasourceStore(d.svcCtx, request.ID)
 
 	return nil
 }
@@ -200,35 +309,51 @@ func (d *datasourceService) BatchRemove(request types.DatasourceBatchRemoveReque
 	if result.RowsAffected == 0 {
 		return ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("no data found"))
 	}
+	invalidateDatasourceStore(d.svcCtx, request.IDs...)
 
 	return nil
 }
 
+// ListContents lists a page of the files in the dir of the datasource, filtered by the names and the types
 func (d *datasourceService) ListContents(request types.DatasourceListContentsRequest) (*types.DatasourceListContentsData, error) {
-	datasourceId := request.DatasourceID
-	dbs, err := d.findOne(datasourceId)
+	opts := filestore.ListOptions{
+		Prefix:  request.Prefix,
+		Marker:  request.Marker,
+		Limit:   request.PageSize,
+		Pattern: request.Pattern,
+	}
+	for _, t := range strings.Split(request.Types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			opts.Types = append(opts.Types, t)
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+	}
+	dbs, err := d.findOne(request.DatasourceID)
 	if err != nil {
 		return nil, err
 	}
-	store, err := d.getFileStore(dbs)
+	store, release, err := d.getFileStore(dbs)
 	if err != nil {
 		return nil, err
 	}
-	fileList, err := store.ListFiles(request.Path)
-	defer store.Close()
-	list := make([]types.FileConfig, 0)
-	for _, item := range fileList {
+	defer release()
+	page, err := store.ListPage(request.Path, opts)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "listFiles failed")
+	}
+	list := make([]types.FileConfig, 0, len(page.Files))
+	for _, item := range page.Files {
 		list = append(list, types.FileConfig{
 			Name: item.Name,
 			Size: item.Size,
 			Type: item.Type,
 		})
 	}
-	if err != nil {
-		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "listFiles failed")
-	}
 	return &types.DatasourceListContentsData{
-		List: list,
+		List:       list,
+		NextMarker: page.NextMarker,
 	}, nil
 }
 
@@ -237,22 +362,144 @@ func (d *datasourceService) PreviewFile(request types.DatasourcePreviewFileReque
 	if err != nil {
 		return nil, err
 	}
-	store, err := d.getFileStore(dbs)
+	store, release, err := d.getFileStore(dbs)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
+	if filestore.IsParquetFile(request.Path) {
+		opener, ok := store.(filestore.ParquetOpener)
+		if !ok {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "the parquet file can not be read from the datasource")
+		}
+		pf, err := opener.OpenParquetFile(request.Path)
+		if err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "readFiles failed")
+		}
+		data, err := previewParquetFile(pf, 3)
+		if err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "read the parquet file failed")
+		}
+		return data, nil
+	}
 	// read three lines
 	contents, err := store.ReadFile(request.Path, 0, 4)
-	defer store.Close()
 	if err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "readFiles failed")
 	}
-
-	return &types.DatasourcePreviewFileData{
+	data := &types.DatasourcePreviewFileData{
 		Contents: contents,
+	}
+	if sqlStore, ok := store.(*filestore.SQLStore); ok {
+		// the columns of the table are read from the header of the empty page
+		page, err := sqlStore.ReadRows(request.Path, "", 0, 0)
+		if err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "read the table failed")
+		}
+		data.Columns = sqlColumns(page.Columns)
+		return data, nil
+	}
+	if isDelimitedFile(request.Path) {
+		opts := preview.Options{
+			Rows:       request.Rows,
+			Delimiter:  request.Delimiter,
+			Quote:      request.Quote,
+			WithHeader: request.WithHeader,
+		}
+		if err := opts.Validate(); err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+		res, err := previewStoreFile(store, request.Path, opts)
+		if err != nil {
+			// the contents are still previewed, e.g. the file is binary
+			d.Infof("detect the settings of the file %s error: %v", request.Path, err)
+		} else {
+			data.Preview = filePreviewData(res)
+		}
+	}
+	return data, nil
+}
+
+// previewStoreFile detects the settings from the head of the file in the store, only the sample is transferred
+func previewStoreFile(store filestore.FileStore, path string, opts preview.Options) (*preview.Result, error) {
+	sampleSize := opts.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = preview.DefaultSampleSize
+	}
+	r, err := store.OpenRange(path, 0, int64(sampleSize)+1)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return preview.Detect(r, opts)
+}
+
+// PreviewRows reads a page of the rows in the table or selected by the query of the sql datasource
+func (d *datasourceService) PreviewRows(request *types.DatasourcePreviewRowsRequest) (*types.DatasourcePreviewRowsData, error) {
+	dbs, err := d.findOne(request.DatasourceID)
+	if err != nil {
+		return nil, err
+	}
+	if dbs.Type != "sql" {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "only the rows of the sql datasource can be previewed")
+	}
+	store, release, err := d.getFileStore(dbs)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	page, pageSize := request.Page, request.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	rows, err := store.(*filestore.SQLStore).ReadRows(request.Table, request.Query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "read the rows failed")
+	}
+	return &types.DatasourcePreviewRowsData{
+		Columns: sqlColumns(rows.Columns),
+		Rows:    rows.Rows,
 	}, nil
 }
 
+func sqlColumns(columns []filestore.SQLColumn) []types.DatasourceFileColumn {
+	list := make([]types.DatasourceFileColumn, 0, len(columns))
+	for _, c := range columns {
+		list = append(list, types.DatasourceFileColumn{Name: c.Name, Type: c.Type})
+	}
+	return list
+}
+
+// previewParquetFile reads the schema and the first rows of the parquet file, the contents are the header and the rows in csv
+func previewParquetFile(pf source.ParquetFile, rows int) (*types.DatasourcePreviewFileData, error) {
+	r, err := filestore.NewParquetReader(pf)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data := &types.DatasourcePreviewFileData{Columns: make([]types.DatasourceFileColumn, 0, len(r.Columns))}
+	header := make([]string, 0, len(r.Columns))
+	for _, column := range r.Columns {
+		header = append(header, column.Name)
+		data.Columns = append(data.Columns, types.DatasourceFileColumn{Name: column.Name, Type: column.Type})
+	}
+	values, err := r.Read(rows)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	for _, row := range append([][]string{header}, values...) {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		_ = w.Write(row)
+		w.Flush()
+		data.Contents = append(data.Contents, strings.TrimSuffix(buf.String(), "\n"))
+	}
+	return data, nil
+}
+
 func (d *datasourceService) findOne(datasourceId string) (*db.Datasource, error) {
 	var dbs db.Datasource
 	result := db.CtxDB.Model(&db.Datasource{}).Where("b_id = ?", datasourceId).
@@ -264,28 +511,66 @@ func (d *datasourceService) findOne(datasourceId string) (*db.Datasource, error)
 		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "datasource don't exist")
 	}
 
-	secret, err := utils.Decrypt(dbs.Secret, []byte(cipher))
-	if err != nil {
+	if err := decryptDatasource(&dbs); err != nil {
 		return nil, err
 	}
-	dbs.Secret = string(secret)
 
 	return &dbs, nil
 }
 
-func (d *datasourceService) save(typ, name, platform, config, secret string) (id string, err error) {
+// decryptDatasource decrypts the secret and the private key of the datasource in place
+func decryptDatasource(dbs *db.Datasource) error {
+	for _, field := range []*string{&dbs.Secret, &dbs.PrivateKey, &dbs.Passphrase} {
+		if *field == "" {
+			continue
+		}
+		plain, err := secrets.Decrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = plain
+	}
+	return nil
+}
+
+// encryptSftpKey encrypts the private key and the passphrase, which are saved besides the secret
+func encryptSftpKey(key filestore.SftpKey) (filestore.SftpKey, error) {
+	encrypted := filestore.SftpKey{}
+	for _, field := range []struct{ plain, encrypted *string }{
+		{&key.PrivateKey, &encrypted.PrivateKey},
+		{&key.Passphrase, &encrypted.Passphrase},
+	} {
+		if *field.plain == "" {
+			continue
+		}
+		crypto, err := secrets.Encrypt(*field.plain)
+		if err != nil {
+			return encrypted, fmt.Errorf("encrypt the private key error: %v", err)
+		}
+		*field.encrypted = crypto
+	}
+	return encrypted, nil
+}
+
+func (d *datasourceService) save(typ, name, platform, config, secret string, key filestore.SftpKey) (id string, err error) {
 	user := d.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
 	host := user.Address + ":" + strconv.Itoa(user.Port)
+	key, err = encryptSftpKey(key)
+	if err != nil {
+		return "", err
+	}
 	id = d.svcCtx.IDGenerator.Generate()
 	dbs := &db.Datasource{
-		BID:      id,
-		Type:     typ,
-		Platform: platform,
-		Name:     name,
-		Config:   config,
-		Secret:   secret,
-		Host:     host,
-		Username: user.Username,
+		BID:        id,
+		Type:       typ,
+		Platform:   platform,
+		Name:       name,
+		Config:     config,
+		Secret:     secret,
+		PrivateKey: key.PrivateKey,
+		Passphrase: key.Passphrase,
+		Host:       host,
+		Username:   user.Username,
 	}
 	result := db.CtxDB.Create(dbs)
 	if result.Error != nil {
@@ -293,17 +578,23 @@ func (d *datasourceService) save(typ, name, platform, config, secret string) (id
 	}
 	return id, nil
 }
-func (d *datasourceService) update(id, typ, platform, name, config, secret string) (err error) {
+func (d *datasourceService) update(id, typ, platform, name, config, secret string, key filestore.SftpKey) (err error) {
 	user := d.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
 	host := user.Address + ":" + strconv.Itoa(user.Port)
+	key, err = encryptSftpKey(key)
+	if err != nil {
+		return err
+	}
 	result := db.CtxDB.Model(&db.Datasource{}).Where("b_id = ?", id).Updates(map[string]interface{}{
-		"type":     typ,
-		"name":     name,
-		"platform": platform,
-		"config":   config,
-		"secret":   secret,
-		"host":     host,
-		"username": user.Username,
+		"type":        typ,
+		"name":        name,
+		"platform":    platform,
+		"config":      config,
+		"secret":      secret,
+		"private_key": key.PrivateKey,
+		"passphrase":  key.Passphrase,
+		"host":        host,
+		"username":    user.Username,
 	})
 	if result.Error != nil {
 		return d.gormErrorWrapper(result.Error)
@@ -311,13 +602,64 @@ func (d *datasourceService) update(id, typ, platform, name, config, secret strin
 	return nil
 }
 
-// TODO: cache the store connection to improve the request handle speed by the go-zero session
-func (d *datasourceService) getFileStore(dbs *db.Datasource) (filestore.FileStore, error) {
+var (
+	datasourceStoresOnce sync.Once
+	// datasourceStores keeps the stores of the datasources browsed, the stores are reused by the requests to list and preview the files
+	datasourceStores *filestore.StoreCache
+)
+
+// getDatasourceStores returns the cache of the stores, nil if the stores are not cached
+func getDatasourceStores(svcCtx *svc.ServiceContext) *filestore.StoreCache {
+	datasourceStoresOnce.Do(func() {
+		c := svcCtx.Config.Datasource
+		if c.StoreIdleTimeout > 0 {
+			datasourceStores = filestore.NewStoreCache(time.Duration(c.StoreIdleTimeout)*time.Second, time.Duration(c.StoreCheckInterval)*time.Second)
+		}
+	})
+	return datasourceStores
+}
+
+// invalidateDatasourceStore closes the store cached of the datasource updated or removed
+func invalidateDatasourceStore(svcCtx *svc.ServiceContext, ids ...string) {
+	if stores := getDatasourceStores(svcCtx); stores != nil {
+		for _, id := range ids {
+			stores.Invalidate(id)
+		}
+	}
+}
+
+// datasourceVersion is the fingerprint of the config and the secrets, the store cached is not reused once the datasource is changed by other instances
+func datasourceVersion(dbs *db.Datasource) string {
+	h := sha256.New()
+	for _, field := range []string{dbs.Type, dbs.Platform, dbs.Config, dbs.Secret, dbs.PrivateKey, dbs.Passphrase} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// getFileStore returns the store cached of the datasource, the store is shared by the requests, it should be released rather than closed
+func (d *datasourceService) getFileStore(dbs *db.Datasource) (filestore.FileStore, func(), error) {
+	stores := getDatasourceStores(d.svcCtx)
+	if stores == nil {
+		store, err := d.openFileStore(dbs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	}
+	return stores.Get(dbs.BID, datasourceVersion(dbs), func() (filestore.FileStore, error) {
+		return d.openFileStore(dbs)
+	})
+}
+
+// openFileStore connects the datasource, the store is owned by the caller, e.g. the task which closes it when it is done
+func (d *datasourceService) openFileStore(dbs *db.Datasource) (filestore.FileStore, error) {
 	var config interface{}
 	if err := json.Unmarshal([]byte(dbs.Config), &config); err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "parse the datasource config error")
 	}
-	store, err := filestore.NewFileStore(dbs.Type, dbs.Config, dbs.Secret, dbs.Platform)
+	store, err := filestore.NewFileStore(dbs.Type, dbs.Config, dbs.Secret, dbs.Platform, filestore.SftpKey{PrivateKey: dbs.PrivateKey, Passphrase: dbs.Passphrase})
 	if err != nil {
 		d.Logger.Errorf("create the file store error")
 		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "create the file store error")
@@ -326,12 +668,66 @@ func (d *datasourceService) getFileStore(dbs *db.Datasource) (filestore.FileStor
 	return store, nil
 }
 
+// openDatasourceStore connects the datasource for the tasks, no store is returned if the id is empty
+func openDatasourceStore(ctx context.Context, svcCtx *svc.ServiceContext, datasourceId string) (filestore.FileStore, error) {
+	if datasourceId == "" {
+		return nil, nil
+	}
+	d := NewDatasourceService(ctx, svcCtx).(*datasourceService)
+	dbs, err := d.findOne(datasourceId)
+	if err != nil {
+		return nil, err
+	}
+	return d.openFileStore(dbs)
+}
+
+/*
+FetchDatasourceFile returns the local path of the file in the datasource for the llm importer,
+the file of the local datasource is read in place, and the file of the http datasource is downloaded into the dir
+*/
+func FetchDatasourceFile(ctx context.Context, svcCtx *svc.ServiceContext, datasourceId, filePath, dir string) (string, error) {
+	store, err := openDatasourceStore(ctx, svcCtx, datasourceId)
+	if err != nil {
+		return "", err
+	}
+	defer store.Close()
+	switch s := store.(type) {
+	case *filestore.LocalStore:
+		local, err := s.Resolve(filePath)
+		if err != nil {
+			return "", ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+		return local, nil
+	case *filestore.HTTPStore:
+		r, err := s.Open(filePath)
+		if err != nil {
+			return "", ecode.WithErrorMessage(ecode.ErrBadRequest, err, "download the file failed")
+		}
+		defer r.Close()
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return "", ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		local := filepath.Join(dir, path.Base(strings.SplitN(filePath, "?", 2)[0]))
+		f, err := os.Create(local)
+		if err != nil {
+			return "", ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		defer f.Close()
+		if _, err := io.Copy(f, r); err != nil {
+			return "", ecode.WithErrorMessage(ecode.ErrBadRequest, err, "download the file failed")
+		}
+		return local, nil
+	default:
+		return "", ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "only the files of the local and the http datasources can be read by the llm importer")
+	}
+}
+
 func formatDatasourceConfig(config interface{}, password string) (string, string, error) {
 	cfgStr, err := json.Marshal(config)
 	if err != nil {
 		return "", "", fmt.Errorf("json stringify config error: %v", err)
 	}
-	crypto, err := utils.Encrypt([]byte(password), []byte(cipher))
+	crypto, err := secrets.Encrypt(password)
 	if err != nil {
 		return "", "", fmt.Errorf("encrypt password error: %v", err)
 	}
@@ -365,16 +761,50 @@ func validate(typ string, platform string, config interface{}) (string, string,
 			return "", "", err
 		}
 		secret := cfg.Password
+		// the private key is saved encrypted besides the config
+		cfg.Password, cfg.PrivateKey, cfg.Passphrase, cfg.HasPrivateKey = "", "", "", false
+		cfgStr, crypto, err := formatDatasourceConfig(config, secret)
+		return cfgStr, crypto, err
+	case "sql":
+		cfg := config.(*types.DatasourceSQLConfig)
+		err := validateSQL(cfg)
+		if err != nil {
+			return "", "", err
+		}
+		secret := cfg.Password
 		cfg.Password = ""
 		cfgStr, crypto, err := formatDatasourceConfig(config, secret)
 		return cfgStr, crypto, err
+	case "local":
+		cfg := config.(*types.DatasourceLocalConfig)
+		root, err := validateLocal(cfg)
+		if err != nil {
+			return "", "", err
+		}
+		cfg.Root = root
+		cfgStr, crypto, err := formatDatasourceConfig(config, "")
+		return cfgStr, crypto, err
+	case "http":
+		cfg := config.(*types.DatasourceHTTPConfig)
+		secret, err := validateHTTP(cfg)
+		if err != nil {
+			return "", "", err
+		}
+		cfg.Password, cfg.Token = "", ""
+		cfgStr, crypto, err := formatDatasourceConfig(config, secret)
+		return cfgStr, crypto, err
 	default:
 		return "", "", errors.New("unsupported datasource type")
 	}
 }
 
 func validateSftp(cfg *types.DatasourceSFTPConfig) error {
-	store, err := filestore.NewSftpStore(cfg.Host, cfg.Port, cfg.Username, cfg.Password)
+	store, err := filestore.NewSftpStoreWithAuth(cfg.Host, cfg.Port, cfg.Username, filestore.SftpAuth{
+		Password:           cfg.Password,
+		SftpKey:            filestore.SftpKey{PrivateKey: cfg.PrivateKey, Passphrase: cfg.Passphrase},
+		HostKeyFingerprint: cfg.HostKeyFingerprint,
+		KnownHosts:         cfg.KnownHosts,
+	})
 	if err != nil {
 		return fmt.Errorf("connect the sftp client error: %s", err)
 	}
@@ -382,6 +812,64 @@ func validateSftp(cfg *types.DatasourceSFTPConfig) error {
 	return nil
 }
 
+func validateSQL(cfg *types.DatasourceSQLConfig) error {
+	store, err := filestore.NewSQLStore(cfg.Driver, cfg.Host, cfg.Port, cfg.Database, cfg.Username, cfg.Password, cfg.Params, filestore.LocalRoots())
+	if err != nil {
+		return fmt.Errorf("connect the database error: %s", err)
+	}
+	store.Close()
+	return nil
+}
+
+// validateLocal checks the root is an allowed dir, and returns its absolute path
+func validateLocal(cfg *types.DatasourceLocalConfig) (string, error) {
+	store, err := filestore.NewLocalStore(cfg.Root, filestore.LocalRoots())
+	if err != nil {
+		return "", err
+	}
+	info, err := store.Stat("")
+	if err != nil {
+		return "", fmt.Errorf("read the dir error: %s", err)
+	}
+	if !info.IsDir {
+		return "", fmt.Errorf("%s is not a dir", cfg.Root)
+	}
+	return store.Root, nil
+}
+
+// validateHTTP checks the urls by reading the directory index or the first url, and returns the secret of the auth type
+func validateHTTP(cfg *types.DatasourceHTTPConfig) (string, error) {
+	if cfg.URL == "" && len(cfg.URLs) == 0 {
+		return "", errors.New("either the url or the urls of the http datasource is required")
+	}
+	if cfg.AuthType == "" {
+		cfg.AuthType = filestore.HTTPAuthNone
+	}
+	secret := ""
+	switch cfg.AuthType {
+	case filestore.HTTPAuthBasic:
+		secret = cfg.Password
+	case filestore.HTTPAuthBearer:
+		secret = cfg.Token
+	default:
+		cfg.Username = ""
+	}
+	store, err := filestore.NewHTTPStore(cfg.URL, cfg.URLs, cfg.AuthType, cfg.Username, secret)
+	if err != nil {
+		return "", err
+	}
+	defer store.Close()
+	if len(cfg.URLs) > 0 {
+		_, err = store.Stat(cfg.URLs[0])
+	} else {
+		_, err = store.ListFiles("")
+	}
+	if err != nil {
+		return "", fmt.Errorf("request the http server error: %s", err)
+	}
+	return secret, nil
+}
+
 func validateS3(platform string, cfg *types.DatasourceS3Config) error {
 	_, err := filestore.NewS3Store(platform, cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKeyID, cfg.AccessSecret)
 	if err != nil {
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vesoft-inc/go-pkg/middleware"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ ExportService = (*exportService)(nil)
+
+type (
+	ExportService interface {
+		CreateExportTask(*types.CreateExportTaskRequest) (*types.CreateExportTaskData, error)
+		GetExportTask(*types.GetExportTaskRequest) (*types.GetExportTaskData, error)
+		GetManyExportTask(*types.GetManyExportTaskRequest) (*types.GetManyExportTaskData, error)
+		StopExportTask(*types.StopExportTaskRequest) error
+		ResumeExportTask(*types.ResumeExportTaskRequest) error
+		DeleteExportTask(*types.DeleteExportTaskRequest) error
+		GetExportTaskLog(*types.GetExportTaskLogRequest) (*types.GetExportTaskLogData, error)
+		DownloadExportTask(*types.DownloadExportTaskRequest) error
+	}
+
+	exportService struct {
+		logx.Logger
+		ctx              context.Context
+		svcCtx           *svc.ServiceContext
+		gormErrorWrapper utils.GormErrorWrapper
+	}
+)
+
+func NewExportService(ctx context.Context, svcCtx *svc.ServiceContext) ExportService {
+	return &exportService{
+		Logger:           logx.WithContext(ctx),
+		ctx:              ctx,
+		svcCtx:           svcCtx,
+		gormErrorWrapper: utils.GormErrorWithLogger(ctx),
+	}
+}
+
+func (e *exportService) CreateExportTask(req *types.CreateExportTaskRequest) (*types.CreateExportTaskData, error) {
+	auth := e.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+
+	cfg := &importer.ExportConfig{
+		Space:  req.Space,
+		Format: req.Format,
+		Batch:  req.Batch,
+	}
+	for _, item := range req.Items {
+		cfg.Items = append(cfg.Items, importer.ExportItem{
+			Type:  item.Type,
+			Name:  item.Name,
+			Props: item.Props,
+		})
+	}
+	if req.DatasourceId != nil {
+		cfg.DatasourceId = *req.DatasourceId
+		if req.DatasourcePath != nil {
+			cfg.DatasourcePath = *req.DatasourcePath
+		}
+	}
+	// the task fails fast without the indexes, instead of failing after it is queued and started
+	if err := importer.CheckExportConfig(auth.NSID, cfg); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+	}
+	store, err := openDatasourceStore(e.ctx, e.svcCtx, cfg.DatasourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	id := e.svcCtx.IDGenerator.Generate()
+	if _, err := importer.CreateNewTaskDir(e.svcCtx.Config.File.TasksDir, id); err != nil {
+		return nil, err
+	}
+	exporter := importer.NewExporter(id, importer.ExportDir(e.svcCtx.Config.File.TasksDir, id), cfg, auth, store)
+	rawConfig, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+
+	taskMgr := importer.GetTaskMgr()
+	task, err := taskMgr.NewRunnerTask(&db.TaskInfo{
+		BID:           id,
+		Name:          req.Name,
+		Address:       host,
+		Space:         req.Space,
+		ImportAddress: host,
+		User:          auth.Username,
+		RawConfig:     string(rawConfig),
+		TaskType:      db.TaskTypeExport,
+	}, exporter)
+	if err != nil {
+		closeStore(store)
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	secret, err := secrets.Encrypt(auth.Password)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	if err = taskMgr.NewTaskEffect(&db.TaskEffect{BID: id, Secret: secret}); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+
+	if err = importer.StartRunnerTask(id); err != nil {
+		task.TaskInfo.TaskStatus = importer.Aborted.String()
+		task.TaskInfo.TaskMessage = err.Error()
+		taskMgr.AbortTask(id)
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return &types.CreateExportTaskData{
+		Id: id,
+	}, nil
+}
+
+// ResumeExportTask continues a stopped, failed or interrupted export task from its checkpoints
+func (e *exportService) ResumeExportTask(req *types.ResumeExportTaskRequest) error {
+	auth := e.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	taskInfo, err := importer.FindExportTask(req.Id, host, auth.Username)
+	if err != nil {
+		return err
+	}
+	switch taskInfo.TaskStatus {
+	case importer.Stoped.String(), importer.Aborted.String(), importer.Interrupted.String():
+	default:
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the task in %s status can not be resumed", taskInfo.TaskStatus))
+	}
+
+	cfg, err := importer.ParseExportConfig(taskInfo.RawConfig)
+	if err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	store, err := openDatasourceStore(e.ctx, e.svcCtx, cfg.DatasourceId)
+	if err != nil {
+		return err
+	}
+	exporter := importer.NewExporter(req.Id, importer.ExportDir(e.svcCtx.Config.File.TasksDir, req.Id), cfg, auth, store)
+	taskMgr := importer.GetTaskMgr()
+	task, err := taskMgr.NewRunnerTask(taskInfo, exporter)
+	if err != nil {
+		closeStore(store)
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+	}
+	if err = importer.StartRunnerTask(req.Id); err != nil {
+		task.TaskInfo.TaskStatus = importer.Aborted.String()
+		task.TaskInfo.TaskMessage = err.Error()
+		taskMgr.AbortTask(req.Id)
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return nil
+}
+
+func (e *exportService) GetExportTask(req *types.GetExportTaskRequest) (*types.GetExportTaskData, error) {
+	auth := e.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	return importer.GetExportTask(req.Id, host, auth.Username)
+}
+
+func (e *exportService) GetManyExportTask(req *types.GetManyExportTaskRequest) (*types.GetManyExportTaskData, error) {
+	auth := e.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	data, err := importer.GetManyExportTask(host, auth.Username, req.Space, req.Page, req.PageSize)
+	if err != nil {
+		return nil, e.gormErrorWrapper(err)
+	}
+	return data, nil
+}
+
+func (e *exportService) StopExportTask(req *types.StopExportTaskRequest) error {
+	auth := e.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	return importer.StopExportTask(req.Id, host, auth.Username)
+}
+
+func (e *exportService) DeleteExportTask(req *types.DeleteExportTaskRequest) error {
+	auth := e.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	return importer.DeleteExportTask(e.svcCtx.Config.File.TasksDir, req.Id, host, auth.Username)
+}
+
+func (e *exportService) GetExportTaskLog(req *types.GetExportTaskLogRequest) (*types.GetExportTaskLogData, error) {
+	auth := e.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	if _, err := importer.FindExportTask(req.Id, host, auth.Username); err != nil {
+		return nil, err
+	}
+	logPath := filepath.Join(e.svcCtx.Config.File.TasksDir, req.Id, importer.TaskLogName(db.TaskTypeExport))
+	lines, err := utils.ReadPartFile(logPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		// the task is deleted from disk, fall back to the log stored in db
+		var taskEffect db.TaskEffect
+		if err := db.CtxDB.Select("log").Where("task_id = ?", req.Id).First(&taskEffect).Error; err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalDatabase, err)
+		}
+		return &types.GetExportTaskLogData{Logs: taskEffect.Log}, nil
+	}
+	return &types.GetExportTaskLogData{Logs: strings.Join(lines, "\n")}, nil
+}
+
+// DownloadExportTask writes the exported files of the task into the response as a zip
+func (e *exportService) DownloadExportTask(req *types.DownloadExportTaskRequest) error {
+	auth := e.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	taskInfo, err := importer.FindExportTask(req.Id, host, auth.Username)
+	if err != nil {
+		return err
+	}
+	if taskInfo.TaskStatus != importer.Finished.String() {
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the task is not finished"))
+	}
+	httpResp, ok := middleware.GetResponseWriter(e.ctx)
+	if !ok {
+		return ecode.WithInternalServer(fmt.Errorf("unset KeepResponse Writer"))
+	}
+	exportDir := importer.ExportDir(e.svcCtx.Config.File.TasksDir, req.Id)
+	entries, err := os.ReadDir(exportDir)
+	if err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+
+	httpResp.Header().Set("Content-Type", "application/zip")
+	httpResp.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=export_%s.zip", req.Id))
+	httpResp.WriteHeader(http.StatusOK)
+	zw := zip.NewWriter(httpResp)
+	defer zw.Close()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := addFileToZip(zw, filepath.Join(exportDir, entry.Name())); err != nil {
+			// the header has been written, so the error can only be logged
+			e.Logger.Errorf("zip the exported file %s error: %s", entry.Name(), err)
+			return nil
+		}
+	}
+	return nil
+}
+
+func addFileToZip(zw *zip.Writer, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := zw.Create(filepath.Base(filePath))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func closeStore(store filestore.FileStore) {
+	if store != nil {
+		store.Close()
+	}
+}
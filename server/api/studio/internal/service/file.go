@@ -24,6 +24,8 @@ import (
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/preview"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
 	"github.com/zeromicro/go-zero/core/logx"
 	"go.uber.org/zap"
@@ -51,6 +53,12 @@ type (
 		FileDestroy(request types.FileDestroyRequest) error
 		FilesIndex() (*types.FilesIndexData, error)
 		FileConfigUpdate(request types.FileConfigUpdateRequest) error
+		FilePreview(request types.FilePreviewRequest) (*types.FilePreviewData, error)
+		FileUploadInit(request types.FileUploadInitRequest) (*types.FileUploadData, error)
+		FileUploadStatus(request types.FileUploadStatusRequest) (*types.FileUploadData, error)
+		FileUploadChunk(request types.FileUploadChunkRequest) (*types.FileUploadData, error)
+		FileUploadComplete(request types.FileUploadCompleteRequest) (*types.FileUploadData, error)
+		FileUploadAbort(request types.FileUploadAbortRequest) error
 	}
 
 	fileService struct {
@@ -122,6 +130,23 @@ func (f *fileService) FilesIndex() (data *types.FilesIndexData, err error) {
 			fileConfig.WithHeader = false
 		}
 		path := filepath.Join(dir, fileInfo.Name())
+		if filestore.IsParquetFile(fileInfo.Name()) {
+			// the sample of the parquet file is the header and the first rows in csv
+			sample := ""
+			if pf, err := filestore.OpenLocalParquetFile(path); err == nil {
+				if preview, err := previewParquetFile(pf, 4); err == nil {
+					sample = strings.Join(preview.Contents, "\r\n") + "\r\n"
+				}
+			}
+			data.List = append(data.List, types.FileStat{
+				Sample:     sample,
+				Name:       fileInfo.Name(),
+				Size:       fileInfo.Size(),
+				WithHeader: true,
+				Delimiter:  ",",
+			})
+			continue
+		}
 		file, err := os.Open(path)
 		if err != nil {
 			logx.Infof("open files error %v", err)
@@ -137,12 +162,21 @@ func (f *fileService) FilesIndex() (data *types.FilesIndexData, err error) {
 			sample += string(line) + "\r\n"
 		}
 		file.Close()
+		var columns []types.FileColumn
+		if fileConfig.Columns != "" {
+			if err := json.Unmarshal([]byte(fileConfig.Columns), &columns); err != nil {
+				logx.Errorf("parse the columns of the file %s error %v", fileInfo.Name(), err)
+			}
+		}
 		data.List = append(data.List, types.FileStat{
 			Sample:     sample,
 			Name:       fileInfo.Name(),
 			Size:       fileInfo.Size(),
 			WithHeader: fileConfig.WithHeader,
 			Delimiter:  fileConfig.Delimiter,
+			Quote:      fileConfig.Quote,
+			Encoding:   fileConfig.Encoding,
+			Columns:    columns,
 		})
 	}
 	return data, nil
@@ -180,6 +214,124 @@ func (f *fileService) FileConfigUpdate(request types.FileConfigUpdateRequest) er
 	return nil
 }
 
+// FilePreview parses the head of the uploaded file, the settings detected are saved unless the request overrides them
+func (f *fileService) FilePreview(request types.FilePreviewRequest) (*types.FilePreviewData, error) {
+	opts := preview.Options{
+		Rows:       request.Rows,
+		Delimiter:  request.Delimiter,
+		Quote:      request.Quote,
+		WithHeader: request.WithHeader,
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+	}
+	res, err := f.previewUploadedFile(request.Name, opts, request.Detect)
+	if err != nil {
+		return nil, err
+	}
+	return filePreviewData(res), nil
+}
+
+/*
+previewUploadedFile previews the file in the upload dir, the delimiter and the header saved are used unless the options set them or detect is set,
+the settings are saved if the options do not override them, the record is created for the file uploaded without the config
+*/
+func (f *fileService) previewUploadedFile(name string, opts preview.Options, detect bool) (*preview.Result, error) {
+	name = cleanFileName(name)
+	if !isDelimitedFile(name) {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the file %s is not delimited text", name))
+	}
+	overridden := opts.Delimiter != "" || opts.Quote != "" || opts.WithHeader != nil
+	file := &db.File{}
+	result := db.CtxDB.Where("name = ?", name).Limit(1).Find(file)
+	if result.Error != nil {
+		return nil, f.gormErrorWrapper(result.Error)
+	}
+	if result.RowsAffected > 0 && !overridden && !detect {
+		opts.Delimiter, opts.Quote = file.Delimiter, file.Quote
+		withHeader := file.WithHeader
+		opts.WithHeader = &withHeader
+	}
+
+	r, err := os.Open(filepath.Join(f.svcCtx.Config.File.UploadDir, name))
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "open the file failed")
+	}
+	defer r.Close()
+	res, err := preview.Detect(r, opts)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "preview the file failed")
+	}
+	if overridden {
+		return res, nil
+	}
+
+	columns, err := json.Marshal(fileColumns(res.Columns))
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	if result.RowsAffected > 0 {
+		result = db.CtxDB.Model(file).Updates(map[string]interface{}{
+			"with_header": res.WithHeader,
+			"delimiter":   res.Delimiter,
+			"quote":       res.Quote,
+			"encoding":    res.Encoding,
+			"columns":     string(columns),
+		})
+	} else {
+		auth := f.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+		result = db.CtxDB.Create(&db.File{
+			BID:        f.svcCtx.IDGenerator.Generate(),
+			Name:       name,
+			WithHeader: res.WithHeader,
+			Delimiter:  res.Delimiter,
+			Quote:      res.Quote,
+			Encoding:   res.Encoding,
+			Columns:    string(columns),
+			Host:       auth.Address + ":" + strconv.Itoa(auth.Port),
+			Username:   auth.Username,
+		})
+	}
+	if result.Error != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalDatabase, result.Error)
+	}
+	return res, nil
+}
+
+// cleanFileName is the base name of the file in the upload dir, the dirs in the name are dropped
+func cleanFileName(name string) string {
+	return filepath.Base(filepath.Clean("/" + name))
+}
+
+// isDelimitedFile tells whether the file may be delimited text, e.g. csv, tsv or txt
+func isDelimitedFile(name string) bool {
+	switch filestore.FileType(name) {
+	case "csv", "txt", "file":
+		return true
+	}
+	return false
+}
+
+func fileColumns(columns []preview.Column) []types.FileColumn {
+	list := make([]types.FileColumn, 0, len(columns))
+	for _, c := range columns {
+		list = append(list, types.FileColumn{Name: c.Name, Type: c.Type})
+	}
+	return list
+}
+
+func filePreviewData(res *preview.Result) *types.FilePreviewData {
+	return &types.FilePreviewData{
+		Encoding:   res.Encoding,
+		Delimiter:  res.Delimiter,
+		Quote:      res.Quote,
+		WithHeader: res.WithHeader,
+		Columns:    fileColumns(res.Columns),
+		Rows:       res.Rows,
+		Truncated:  res.Truncated,
+	}
+}
+
 func (f *fileService) FileUpload() error {
 	dir := f.svcCtx.Config.File.UploadDir
 	auth := f.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
@@ -213,13 +365,16 @@ func (f *fileService) FileUpload() error {
 			logx.Infof("upload file error, check charset fail:%v", err)
 			return ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
 		}
-		if charSet == "UTF-8" {
-			continue
+		if charSet != "UTF-8" {
+			path := filepath.Join(dir, file.Filename)
+			if err = changeFileCharset2UTF8(path, charSet); err != nil {
+				logx.Infof("upload file error:%v", err)
+				return ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+			}
 		}
-		path := filepath.Join(dir, file.Filename)
-		if err = changeFileCharset2UTF8(path, charSet); err != nil {
-			logx.Infof("upload file error:%v", err)
-			return ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+		// the settings which are not uploaded with the file are detected
+		if _, err := f.previewUploadedFile(file.Filename, preview.Options{}, false); err != nil {
+			logx.Infof("detect the settings of the file %s error:%v", file.Filename, err)
 		}
 	}
 	logx.Infof("upload %d files", len(files))
@@ -324,8 +479,22 @@ func checkCharset(file *multipart.FileHeader) (string, error) {
 		return "", err
 	}
 	defer f.Close()
+	return detectCharset(f)
+}
+
+// fileCharset detects the charset of the file saved
+func fileCharset(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return detectCharset(f)
+}
+
+func detectCharset(r io.Reader) (string, error) {
 	bytes := make([]byte, 1024)
-	if _, err = f.Read(bytes); err != nil {
+	if _, err := r.Read(bytes); err != nil {
 		return "", err
 	}
 	detector := chardet.NewTextDetector()
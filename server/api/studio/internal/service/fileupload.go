@@ -0,0 +1,346 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vesoft-inc/go-pkg/middleware"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/preview"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// uploadPartDir is the hidden dir in the upload dir for the part files, which is not listed as the files uploaded
+	uploadPartDir       = ".uploads"
+	defaultChunkSize    = 8 << 20
+	defaultUploadExpire = 24 * time.Hour
+)
+
+// uploadLocks serializes the requests of the same upload, e.g. the chunk retried while the last one is still written
+var uploadLocks sync.Map
+
+func lockUpload(id string) func() {
+	mu, _ := uploadLocks.LoadOrStore(id, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// FileUploadInit starts the chunked upload, the upload of the same file by the user which is not completed is returned to be resumed
+func (f *fileService) FileUploadInit(request types.FileUploadInitRequest) (*types.FileUploadData, error) {
+	auth := f.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	name := cleanFileName(request.Name)
+	if name == "." || name == "/" || strings.HasPrefix(name, ".") {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("invalid file name %s", request.Name))
+	}
+	checksum := strings.ToLower(request.Checksum)
+	if checksum != "" {
+		if b, err := hex.DecodeString(checksum); err != nil || len(b) != sha256.Size {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the checksum should be the hex sha256 of the file"))
+		}
+	}
+	f.removeExpiredUploads()
+
+	upload := &db.FileUpload{}
+	result := db.CtxDB.Where("name = ? AND size = ? AND checksum = ? AND host = ? AND username = ?", name, request.Size, checksum, host, auth.Username).
+		Order("id desc").Limit(1).Find(upload)
+	if result.Error != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalDatabase, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		unlock := lockUpload(upload.BID)
+		defer unlock()
+		if err := f.syncUploadOffset(upload); err == nil {
+			return f.fileUploadData(upload), nil
+		}
+		// the part file is lost, the upload is started again
+		f.removeUpload(upload)
+	}
+
+	upload = &db.FileUpload{
+		BID:       f.svcCtx.IDGenerator.Generate(),
+		Name:      name,
+		Size:      request.Size,
+		ChunkSize: f.chunkSize(),
+		Checksum:  checksum,
+		Delimiter: request.Delimiter,
+		HasConfig: request.WithHeader != nil || request.Delimiter != "",
+		Host:      host,
+		Username:  auth.Username,
+	}
+	if request.WithHeader != nil {
+		upload.WithHeader = *request.WithHeader
+	}
+	if upload.HasConfig && upload.Delimiter == "" {
+		upload.Delimiter = ","
+	}
+	if err := os.MkdirAll(filepath.Dir(f.uploadPartPath(upload.BID)), os.ModePerm); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+	}
+	part, err := os.Create(f.uploadPartPath(upload.BID))
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+	}
+	part.Close()
+	if result := db.CtxDB.Create(upload); result.Error != nil {
+		os.Remove(f.uploadPartPath(upload.BID))
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalDatabase, result.Error)
+	}
+	return f.fileUploadData(upload), nil
+}
+
+// FileUploadStatus returns the offset where the client continues the upload, e.g. after the connection is broken
+func (f *fileService) FileUploadStatus(request types.FileUploadStatusRequest) (*types.FileUploadData, error) {
+	unlock := lockUpload(request.Id)
+	defer unlock()
+	upload, err := f.findUpload(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.syncUploadOffset(upload); err != nil {
+		return nil, err
+	}
+	return f.fileUploadData(upload), nil
+}
+
+/*
+FileUploadChunk writes the body from the offset, which must be the offset received so far,
+the chunk is verified by its checksum before it is written, and the offset is saved after the chunk is synced to disk
+*/
+func (f *fileService) FileUploadChunk(request types.FileUploadChunkRequest) (*types.FileUploadData, error) {
+	httpReq, ok := middleware.GetRequest(f.ctx)
+	if !ok {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, fmt.Errorf("unset KeepRequest"), "upload failed")
+	}
+	unlock := lockUpload(request.Id)
+	defer unlock()
+	upload, err := f.findUpload(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.syncUploadOffset(upload); err != nil {
+		return nil, err
+	}
+	if request.Offset != upload.Offset {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the chunk should start from the offset %d", upload.Offset))
+	}
+
+	chunk, err := io.ReadAll(io.LimitReader(httpReq.Body, upload.ChunkSize+1))
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "read the chunk failed")
+	}
+	if int64(len(chunk)) > upload.ChunkSize {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the chunk is larger than %d bytes", upload.ChunkSize))
+	}
+	if upload.Offset+int64(len(chunk)) > upload.Size {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the chunk exceeds the size %d of the file", upload.Size))
+	}
+	if request.Checksum != "" {
+		sum := sha256.Sum256(chunk)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), request.Checksum) {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the checksum of the chunk does not match"))
+		}
+	}
+
+	part, err := os.OpenFile(f.uploadPartPath(upload.BID), os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+	}
+	defer part.Close()
+	if _, err = part.WriteAt(chunk, upload.Offset); err == nil {
+		err = part.Sync()
+	}
+	if err != nil {
+		// the bytes written partly are dropped, the chunk is sent again
+		part.Truncate(upload.Offset)
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+	}
+	upload.Offset += int64(len(chunk))
+	if result := db.CtxDB.Model(upload).Update("byte_offset", upload.Offset); result.Error != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalDatabase, result.Error)
+	}
+	return f.fileUploadData(upload), nil
+}
+
+/*
+FileUploadComplete verifies the size and the checksum of the file, and moves it into the upload dir,
+the charset of the text file is changed into utf-8, and the settings are saved or detected as the files uploaded at once
+*/
+func (f *fileService) FileUploadComplete(request types.FileUploadCompleteRequest) (*types.FileUploadData, error) {
+	unlock := lockUpload(request.Id)
+	defer unlock()
+	upload, err := f.findUpload(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.syncUploadOffset(upload); err != nil {
+		return nil, err
+	}
+	if upload.Offset != upload.Size {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("%d of %d bytes are received", upload.Offset, upload.Size))
+	}
+	partPath := f.uploadPartPath(upload.BID)
+	checksum, err := importer.FileChecksum(partPath)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+	}
+	if upload.Checksum != "" && checksum != upload.Checksum {
+		// the file is broken, the client should upload it again
+		f.removeUpload(upload)
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the checksum %s of the file does not match %s", checksum, upload.Checksum))
+	}
+
+	target := filepath.Join(f.svcCtx.Config.File.UploadDir, upload.Name)
+	if err := os.Rename(partPath, target); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+	}
+	if result := db.CtxDB.Delete(upload); result.Error != nil {
+		logx.Errorf("delete the upload %s error: %v", upload.BID, result.Error)
+	}
+	uploadLocks.Delete(upload.BID)
+
+	auth := f.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	if isDelimitedFile(upload.Name) && upload.Size > 0 {
+		charSet, err := fileCharset(target)
+		if err != nil {
+			logx.Infof("upload file error, check charset fail:%v", err)
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+		}
+		if charSet != "UTF-8" {
+			if err = changeFileCharset2UTF8(target, charSet); err != nil {
+				logx.Infof("upload file error:%v", err)
+				return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "upload failed")
+			}
+		}
+	}
+	if upload.HasConfig {
+		if err := f.SaveFileConfig(auth, host, fileConfig{Name: upload.Name, WithHeader: upload.WithHeader, Delimiter: upload.Delimiter}); err != nil {
+			return nil, err
+		}
+	} else if isDelimitedFile(upload.Name) {
+		if _, err := f.previewUploadedFile(upload.Name, preview.Options{}, false); err != nil {
+			logx.Infof("detect the settings of the file %s error:%v", upload.Name, err)
+		}
+	}
+	logx.Infof("upload the file %s by chunks, size: %d, sha256: %s", upload.Name, upload.Size, checksum)
+	data := f.fileUploadData(upload)
+	data.Checksum = checksum
+	return data, nil
+}
+
+// FileUploadAbort removes the chunked upload and the bytes received
+func (f *fileService) FileUploadAbort(request types.FileUploadAbortRequest) error {
+	unlock := lockUpload(request.Id)
+	defer unlock()
+	upload, err := f.findUpload(request.Id)
+	if err != nil {
+		return err
+	}
+	f.removeUpload(upload)
+	uploadLocks.Delete(upload.BID)
+	return nil
+}
+
+// findUpload finds the upload started by the user
+func (f *fileService) findUpload(id string) (*db.FileUpload, error) {
+	auth := f.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	upload := &db.FileUpload{}
+	result := db.CtxDB.Where("b_id = ? AND host = ? AND username = ?", id, host, auth.Username).Limit(1).Find(upload)
+	if result.Error != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalDatabase, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the upload is not found, it may be completed or expired"))
+	}
+	return upload, nil
+}
+
+/*
+syncUploadOffset checks the part file against the offset saved, the bytes written after the offset is saved are dropped,
+e.g. the service is stopped while the chunk is written
+*/
+func (f *fileService) syncUploadOffset(upload *db.FileUpload) error {
+	partPath := f.uploadPartPath(upload.BID)
+	info, err := os.Stat(partPath)
+	if err != nil {
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, err, "the bytes received are lost, please upload the file again")
+	}
+	switch {
+	case info.Size() > upload.Offset:
+		if err := os.Truncate(partPath, upload.Offset); err != nil {
+			return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+	case info.Size() < upload.Offset:
+		upload.Offset = info.Size()
+		if result := db.CtxDB.Model(upload).Update("byte_offset", upload.Offset); result.Error != nil {
+			return ecode.WithErrorMessage(ecode.ErrInternalDatabase, result.Error)
+		}
+	}
+	return nil
+}
+
+func (f *fileService) removeUpload(upload *db.FileUpload) {
+	if err := os.Remove(f.uploadPartPath(upload.BID)); err != nil && !os.IsNotExist(err) {
+		logx.Errorf("remove the part file of the upload %s error: %v", upload.BID, err)
+	}
+	if result := db.CtxDB.Delete(upload); result.Error != nil {
+		logx.Errorf("delete the upload %s error: %v", upload.BID, result.Error)
+	}
+}
+
+// removeExpiredUploads removes the uploads which receive no chunk for the expire time
+func (f *fileService) removeExpiredUploads() {
+	expire := time.Duration(f.svcCtx.Config.File.UploadExpire) * time.Second
+	if expire <= 0 {
+		expire = defaultUploadExpire
+	}
+	var uploads []*db.FileUpload
+	if result := db.CtxDB.Where("update_time < ?", time.Now().Add(-expire)).Find(&uploads); result.Error != nil {
+		logx.Errorf("find the expired uploads error: %v", result.Error)
+		return
+	}
+	for _, upload := range uploads {
+		unlock := lockUpload(upload.BID)
+		f.removeUpload(upload)
+		unlock()
+		uploadLocks.Delete(upload.BID)
+	}
+}
+
+func (f *fileService) uploadPartPath(id string) string {
+	return filepath.Join(f.svcCtx.Config.File.UploadDir, uploadPartDir, id+".part")
+}
+
+func (f *fileService) chunkSize() int64 {
+	if size := f.svcCtx.Config.File.ChunkSize; size > 0 {
+		return size
+	}
+	return defaultChunkSize
+}
+
+func (f *fileService) fileUploadData(upload *db.FileUpload) *types.FileUploadData {
+	return &types.FileUploadData{
+		Id:        upload.BID,
+		Name:      upload.Name,
+		Size:      upload.Size,
+		Offset:    upload.Offset,
+		ChunkSize: upload.ChunkSize,
+		Checksum:  upload.Checksum,
+	}
+}
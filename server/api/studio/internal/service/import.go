@@ -13,6 +13,7 @@ import (
 	"sync"
 
 	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
 
 	"github.com/vesoft-inc/go-pkg/middleware"
@@ -24,6 +25,7 @@ import (
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -51,6 +53,19 @@ type (
 		GetImportTaskLogNames(request *types.GetImportTaskLogNamesRequest) (*types.GetImportTaskLogNamesData, error)
 		GetManyImportTaskLog(request *types.GetManyImportTaskLogRequest) (*types.GetManyImportTaskLogData, error)
 		GetWorkingDir() (*types.GetWorkingDirResult, error)
+		CreateNGQLImportTask(*types.CreateNGQLImportTaskRequest) (*types.CreateImportTaskData, error)
+		ResumeImportTask(*types.ResumeImportTaskRequest) (*types.CreateImportTaskData, error)
+		RetryImportTask(*types.RetryImportTaskRequest) (*types.CreateImportTaskData, error)
+		ValidateImportTask(*types.ValidateImportTaskRequest) (*types.ValidateImportTaskData, error)
+		SuggestImportMapping(*types.SuggestImportMappingRequest) (*types.SuggestImportMappingData, error)
+		CloneImportTask(*types.CloneImportTaskRequest) (*types.CreateImportTaskData, error)
+		GetImportTaskFailedFiles(*types.GetImportTaskFailedFilesRequest) (*types.GetImportTaskFailedFilesData, error)
+		PreviewImportTaskFailedFile(*types.PreviewImportTaskFailedFileRequest) (*types.PreviewImportTaskFailedFileData, error)
+		DownloadImportTaskFailedFile(*types.DownloadImportTaskFailedFileRequest) error
+		DownloadImportTaskFailedFiles(*types.DownloadImportTaskFailedFilesRequest) error
+		ReimportImportTaskFailedFiles(*types.ReimportImportTaskFailedFilesRequest) (*types.CreateImportTaskData, error)
+		GetImportTaskMetrics(*types.GetImportTaskMetricsRequest) (*types.GetImportTaskMetricsData, error)
+		GetImportTaskReport(*types.GetImportTaskReportRequest) (*types.GetImportTaskReportData, error)
 	}
 
 	importService struct {
@@ -63,9 +78,10 @@ type (
 
 func NewImportService(ctx context.Context, svcCtx *svc.ServiceContext) ImportService {
 	return &importService{
-		Logger: logx.WithContext(ctx),
-		ctx:    ctx,
-		svcCtx: svcCtx,
+		Logger:           logx.WithContext(ctx),
+		ctx:              ctx,
+		svcCtx:           svcCtx,
+		gormErrorWrapper: utils.GormErrorWithLogger(ctx),
 	}
 }
 
@@ -75,79 +91,268 @@ func (i *importService) updateDatasourceConfig(conf *types.CreateImportTaskReque
 		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
 	}
 	for _, source := range config.Sources {
-		if source.DatasourceId != nil {
-			var dbs db.Datasource
-			result := db.CtxDB.Where("b_id = ?", source.DatasourceId).First(&dbs)
-			if result.Error != nil {
-				return nil, i.gormErrorWrapper(result.Error)
+		if err := i.resolveSource(source); err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// resolveSource resolves the local file of the source to the absolute path, and fills the source with its datasource
+func (i *importService) resolveSource(source *types.Source) error {
+	if source.Path != "" {
+		path, err := i.resolveSourcePath(source.Path)
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+		source.Path = path
+	}
+	return i.resolveDatasource(source)
+}
+
+/*
+resolveSourcePath returns the absolute path of the local file of the source,
+  - the path is relative to the upload dir, neither the absolute path nor the path with .. is accepted
+  - only the files of the failed records of the user's own tasks are read from the tasks dir, which are the sources of the retries
+*/
+func (i *importService) resolveSourcePath(path string) (string, error) {
+	tasksDir, err := filepath.Abs(i.svcCtx.Config.File.TasksDir)
+	if err != nil {
+		return "", err
+	}
+	if taskID, ok := failedRecordsTask(tasksDir, path); ok {
+		auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+		host := auth.Address + ":" + strconv.Itoa(auth.Port)
+		if _, err := importer.FindImportTask(taskID, host, auth.Username); err != nil {
+			return "", fmt.Errorf("the file %s is not allowed to be read", path)
+		}
+		return path, nil
+	}
+	return uploadFilePath(i.svcCtx.Config.File.UploadDir, path)
+}
+
+// failedRecordsTask returns the task whose failed records are kept in the file, the path must be the exact one of FailedRecordsPath
+func failedRecordsTask(tasksDir, path string) (string, bool) {
+	if !filepath.IsAbs(path) {
+		return "", false
+	}
+	rel, err := filepath.Rel(tasksDir, path)
+	if err != nil {
+		return "", false
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) != 3 || parts[0] == ".." || parts[0] == "." {
+		return "", false
+	}
+	idx, ok := importer.FailedRecordsSource(parts[2])
+	if !ok || importer.FailedRecordsPath(filepath.Join(tasksDir, parts[0]), idx) != path {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// uploadFilePath joins the name with the upload dir, the name given by the request can not be out of the upload dir
+func uploadFilePath(uploadDir, name string) (string, error) {
+	if filepath.IsAbs(name) || utils.Contains(strings.Split(filepath.ToSlash(name), "/"), "..") {
+		return "", fmt.Errorf("the file %s is not allowed to be read, it should be relative to the upload dir", name)
+	}
+	return filepath.Join(uploadDir, name), nil
+}
+
+// resolveDatasource fills the source with the config and the secret of its datasource
+func (i *importService) resolveDatasource(source *types.Source) error {
+	if source.DatasourceId == nil {
+		return nil
+	}
+	var dbs db.Datasource
+	result := db.CtxDB.Where("b_id = ?", source.DatasourceId).First(&dbs)
+	if result.Error != nil {
+		return i.gormErrorWrapper(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "datasource don't exist")
+	}
+
+	if err := decryptDatasource(&dbs); err != nil {
+		return err
+	}
+	secret := dbs.Secret
+	switch dbs.Type {
+	case "s3":
+		cfg := &types.DatasourceS3Config{}
+		jsonConfig := dbs.Config
+		if err := json.Unmarshal([]byte(jsonConfig), cfg); err != nil {
+			return ecode.WithInternalServer(err, "get datasource config failed")
+		}
+		switch dbs.Platform {
+		case "aws":
+			// endpoint is not required in importer aws config
+			// some format of endpoint will cause error, for example: https://s3.amazonaws.com
+			source.S3 = &types.S3Config{
+				AccessKeyID:     cfg.AccessKeyID,
+				AccessKeySecret: secret,
+				Bucket:          cfg.Bucket,
+				Region:          cfg.Region,
+				Key:             *source.DatasourceFilePath,
 			}
-			if result.RowsAffected == 0 {
-				return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, nil, "datasource don't exist")
+		case "oss":
+			source.OSS = &types.OSSConfig{
+				AccessKeyID:     cfg.AccessKeyID,
+				AccessKeySecret: secret,
+				Bucket:          cfg.Bucket,
+				Endpoint:        cfg.Endpoint,
+				Key:             *source.DatasourceFilePath,
 			}
-
-			secret, err := utils.Decrypt(dbs.Secret, []byte(cipher))
-			if err != nil {
-				return nil, err
+		case "cos", "customize":
+			source.S3 = &types.S3Config{
+				AccessKeyID:     cfg.AccessKeyID,
+				AccessKeySecret: secret,
+				Bucket:          cfg.Bucket,
+				Region:          cfg.Region,
+				Endpoint:        cfg.Endpoint,
+				Key:             *source.DatasourceFilePath,
 			}
-			switch dbs.Type {
-			case "s3":
-				cfg := &types.DatasourceS3Config{}
-				jsonConfig := dbs.Config
-				if err := json.Unmarshal([]byte(jsonConfig), cfg); err != nil {
-					return nil, ecode.WithInternalServer(err, "get datasource config failed")
-				}
-				switch dbs.Platform {
-				case "aws":
-					// endpoint is not required in importer aws config
-					// some format of endpoint will cause error, for example: https://s3.amazonaws.com
-					source.S3 = &types.S3Config{
-						AccessKeyID:     cfg.AccessKeyID,
-						AccessKeySecret: string(secret),
-						Bucket:          cfg.Bucket,
-						Region:          cfg.Region,
-						Key:             *source.DatasourceFilePath,
-					}
-				case "oss":
-					source.OSS = &types.OSSConfig{
-						AccessKeyID:     cfg.AccessKeyID,
-						AccessKeySecret: string(secret),
-						Bucket:          cfg.Bucket,
-						Endpoint:        cfg.Endpoint,
-						Key:             *source.DatasourceFilePath,
-					}
-				case "cos", "customize":
-					source.S3 = &types.S3Config{
-						AccessKeyID:     cfg.AccessKeyID,
-						AccessKeySecret: string(secret),
-						Bucket:          cfg.Bucket,
-						Region:          cfg.Region,
-						Endpoint:        cfg.Endpoint,
-						Key:             *source.DatasourceFilePath,
-					}
-					if cfg.Region == "" {
-						source.S3.Region = "us-east-1"
-					}
-				}
-			case "sftp":
-				sftpConfig := &types.DatasourceSFTPConfig{}
-				jsonConfig := dbs.Config
-				if err := json.Unmarshal([]byte(jsonConfig), sftpConfig); err != nil {
-					return nil, ecode.WithInternalServer(err, "get datasource config failed")
-				}
-				source.SFTP = &types.SFTPConfig{
-					Host:     sftpConfig.Host,
-					Port:     sftpConfig.Port,
-					User:     sftpConfig.Username,
-					Password: string(secret),
-					Path:     *source.DatasourceFilePath,
-				}
+			if cfg.Region == "" {
+				source.S3.Region = "us-east-1"
 			}
 		}
+	case "sftp":
+		sftpConfig := &types.DatasourceSFTPConfig{}
+		jsonConfig := dbs.Config
+		if err := json.Unmarshal([]byte(jsonConfig), sftpConfig); err != nil {
+			return ecode.WithInternalServer(err, "get datasource config failed")
+		}
+		source.SFTP = &types.SFTPConfig{
+			Host:               sftpConfig.Host,
+			Port:               sftpConfig.Port,
+			User:               sftpConfig.Username,
+			Password:           secret,
+			KeyData:            dbs.PrivateKey,
+			Passphrase:         dbs.Passphrase,
+			Path:               *source.DatasourceFilePath,
+			HostKeyFingerprint: sftpConfig.HostKeyFingerprint,
+			KnownHosts:         sftpConfig.KnownHosts,
+		}
+	case "sql":
+		sqlConfig := &types.DatasourceSQLConfig{}
+		if err := json.Unmarshal([]byte(dbs.Config), sqlConfig); err != nil {
+			return ecode.WithInternalServer(err, "get datasource config failed")
+		}
+		sql := &types.SQLConfig{}
+		if source.SQL != nil {
+			// the custom query is given by the source
+			sql.Table, sql.Query = source.SQL.Table, source.SQL.Query
+		}
+		if sql.Query == "" && source.DatasourceFilePath != nil {
+			sql.Table = *source.DatasourceFilePath
+		}
+		sql.Driver, sql.Host, sql.Port, sql.Database = sqlConfig.Driver, sqlConfig.Host, sqlConfig.Port, sqlConfig.Database
+		sql.User, sql.Password, sql.Params = sqlConfig.Username, secret, sqlConfig.Params
+		source.SQL = sql
+	case "local":
+		localConfig := &filestore.LocalConfig{}
+		if err := json.Unmarshal([]byte(dbs.Config), localConfig); err != nil {
+			return ecode.WithInternalServer(err, "get datasource config failed")
+		}
+		store, err := filestore.NewLocalStore(localConfig.Root, filestore.LocalRoots())
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+		// the file is read as a local file
+		path, err := store.Resolve(*source.DatasourceFilePath)
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+		source.Path = path
+	case "http":
+		httpConfig := &filestore.HTTPConfig{}
+		if err := json.Unmarshal([]byte(dbs.Config), httpConfig); err != nil {
+			return ecode.WithInternalServer(err, "get datasource config failed")
+		}
+		store, err := filestore.NewHTTPStore(httpConfig.URL, httpConfig.URLs, httpConfig.AuthType, httpConfig.Username, secret)
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+		url, err := store.Resolve(*source.DatasourceFilePath)
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+		source.HTTP = &types.HTTPConfig{
+			URL:      url,
+			AuthType: httpConfig.AuthType,
+			Username: httpConfig.Username,
+			Password: secret,
+		}
 	}
-	return &config, nil
+	return nil
 }
 
-func updateConfig(conf config.Configurator, taskDir, uploadDir string) {
+/*
+normalizeSources checks the json, the parquet and the sql sources, which are read as csv,
+their csv config is changed to the csv converted from the records
+*/
+func normalizeSources(sources []*types.Source) (map[int]*importer.JSONSource, map[int]*importer.ParquetSource, map[int]*importer.SQLSource, error) {
+	jsonSources := make(map[int]*importer.JSONSource)
+	parquetSources := make(map[int]*importer.ParquetSource)
+	sqlSources := make(map[int]*importer.SQLSource)
+	for idx, source := range sources {
+		ss, err := importer.NewSQLSource(source)
+		if err != nil {
+			return nil, nil, nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, fmt.Sprintf("source %d is invalid", idx))
+		}
+		js, err := importer.NewJSONSource(source)
+		if err != nil {
+			return nil, nil, nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, fmt.Sprintf("source %d is invalid", idx))
+		}
+		ps, err := importer.NewParquetSource(source)
+		if err != nil {
+			return nil, nil, nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, fmt.Sprintf("source %d is invalid", idx))
+		}
+		if js == nil && ps == nil && ss == nil {
+			continue
+		}
+		delimiter := ","
+		source.CSV = types.ImportTaskCSV{Delimiter: &delimiter}
+		switch {
+		case ss != nil:
+			sqlSources[idx] = ss
+		case js != nil:
+			jsonSources[idx] = js
+		default:
+			parquetSources[idx] = ps
+		}
+	}
+	return jsonSources, parquetSources, sqlSources, nil
+}
+
+/*
+remoteSources checks the sources read by the studio instead of nebula-importer,
+the files downloaded over http and the sftp files whose hosts are verified
+*/
+func remoteSources(sources []*types.Source) (map[int]*importer.HTTPSource, map[int]*importer.SFTPSource, error) {
+	httpSources := make(map[int]*importer.HTTPSource)
+	sftpSources := make(map[int]*importer.SFTPSource)
+	for idx, source := range sources {
+		hs, err := importer.NewHTTPSource(source)
+		if err != nil {
+			return nil, nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, fmt.Sprintf("source %d is invalid", idx))
+		}
+		if hs != nil {
+			httpSources[idx] = hs
+		}
+		ss, err := importer.NewSFTPSource(source)
+		if err != nil {
+			return nil, nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, fmt.Sprintf("source %d is invalid", idx))
+		}
+		if ss != nil {
+			sftpSources[idx] = ss
+		}
+	}
+	return httpSources, sftpSources, nil
+}
+
+// updateConfig adds the log of the task, the local files of the sources are resolved by resolveSource already
+func updateConfig(conf config.Configurator, taskDir string) {
 	confv3 := conf.(*configv3.Config)
 	if confv3.Log == nil {
 		confv3.Log = &config.Log{}
@@ -155,19 +360,27 @@ func updateConfig(conf config.Configurator, taskDir, uploadDir string) {
 	}
 
 	confv3.Log.Files = append(confv3.Log.Files, filepath.Join(taskDir, importLogName))
-	for _, source := range confv3.Sources {
-		if source.SourceConfig.Local != nil {
-			source.SourceConfig.Local.Path = filepath.Join(uploadDir, source.SourceConfig.Local.Path)
-		}
-	}
 }
 
 func (i *importService) CreateImportTask(req *types.CreateImportTaskRequest) (*types.CreateImportTaskData, error) {
+	return i.createImportTask(req, nil)
+}
+
+// createImportTask creates the task with the config, the run is set when the task continues an existing one
+func (i *importService) createImportTask(req *types.CreateImportTaskRequest, run *importRun) (*types.CreateImportTaskData, error) {
 	_config, err := i.updateDatasourceConfig(req)
 
 	if err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
+	jsonSources, parquetSources, sqlSources, err := normalizeSources(_config.Sources)
+	if err != nil {
+		return nil, err
+	}
+	httpSources, sftpSources, err := remoteSources(_config.Sources)
+	if err != nil {
+		return nil, err
+	}
 	jsons, err := json.Marshal(_config)
 	if err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrParam, err)
@@ -191,8 +404,8 @@ func (i *importService) CreateImportTask(req *types.CreateImportTaskRequest) (*t
 	if err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
-	// modify source file path & add log config
-	updateConfig(conf, taskDir, i.svcCtx.Config.File.UploadDir)
+	// add log config
+	updateConfig(conf, taskDir)
 
 	// init task in db
 	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
@@ -200,16 +413,39 @@ func (i *importService) CreateImportTask(req *types.CreateImportTaskRequest) (*t
 	taskMgr := importer.GetTaskMgr()
 	var task *importer.Task
 	if req.Id != nil {
-		task, err = taskMgr.TurnDraftToTask(*req.Id, req.Name, req.RawConfig, conf)
+		task, err = taskMgr.TurnDraftToTask(*req.Id, host, req.Name, req.RawConfig, req.Priority, conf)
 	} else {
-		task, err = taskMgr.NewTask(*id, host, auth.Username, req.Name, req.RawConfig, conf)
+		task, err = taskMgr.NewTask(*id, host, auth.Username, req.Name, req.RawConfig, req.Priority, conf)
 	}
 	if err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
+	tracker := importer.NewImportTracker(*id, taskDir)
+	tracker.JSON = jsonSources
+	tracker.Parquet = parquetSources
+	tracker.SQL = sqlSources
+	tracker.HTTP = httpSources
+	tracker.SFTP = sftpSources
+	task.Client.Tracker = tracker
+	if run != nil {
+		tracker.RunType = run.runType
+		tracker.Resume = run.resume
+		tracker.Base = run.base
+		if err = taskMgr.SetTaskParent(*id, run.parent.BID, run.runType); err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+	}
 
-	// init task effect in db, store config.yaml
-	err = taskMgr.NewTaskEffect(&db.TaskEffect{BID: *id, Config: configFile})
+	// init task effect in db, store config.yaml and the config to resume or retry the task
+	importConfig, err := secrets.Encrypt(req.Config)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	secret, err := secrets.Encrypt(auth.Password)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	err = taskMgr.NewTaskEffect(&db.TaskEffect{BID: *id, Config: configFile, ImportConfig: importConfig, Secret: secret})
 	if err != nil {
 		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
@@ -340,25 +576,12 @@ func (i *importService) GetManyImportTask(req *types.GetManyImportTaskRequest) (
 	return importer.GetManyImportTask(host, auth.Username, req.Space, req.Page, req.PageSize)
 }
 
-// GetImportTaskLogNames :Get all log file's name of a task
+// GetImportTaskLogNames :Get all log file's name of a task, the files of the failed records are listed by GetImportTaskFailedFiles
 func (i *importService) GetImportTaskLogNames(req *types.GetImportTaskLogNamesRequest) (*types.GetImportTaskLogNamesData, error) {
-	// TODO err log will be support in next importer version
-	// id := req.Id
-
-	// errLogDir := filepath.Join(i.svcCtx.Config.File.TasksDir, strconv.Itoa(id), "err")
-	// fileInfos, err := ioutil.ReadDir(errLogDir)
-	// if err != nil {
-	// 	return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
-	// }
-
 	data := &types.GetImportTaskLogNamesData{
 		Names: []string{},
 	}
 	data.Names = append(data.Names, importLogName)
-	// for _, fileInfo := range fileInfos {
-	// 	name := fileInfo.Name()
-	// 	data.Names = append(data.Names, name)
-	// }
 	return data, nil
 }
 
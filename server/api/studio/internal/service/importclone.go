@@ -0,0 +1,185 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+)
+
+/*
+CloneImportTask creates a new task or draft from an existing task, the clone is linked to the task it is cloned from.
+  - the space, the graphd address and the paths of the sources can be overridden
+  - the credentials of the current user and the secrets of the datasources are injected again
+  - the draft keeps the raw config of the task, so only the name and the space can be changed for it
+*/
+func (i *importService) CloneImportTask(req *types.CloneImportTaskRequest) (*types.CreateImportTaskData, error) {
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	taskInfo, err := importer.FindImportTask(req.Id, host, auth.Username)
+	if err != nil {
+		return nil, err
+	}
+	if taskInfo.TaskStatus == importer.Draft.String() {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the draft can be used directly, it can not be cloned"))
+	}
+	name := req.Name
+	if name == "" {
+		name = taskInfo.Name + "_copy"
+	}
+
+	var data *types.CreateImportTaskData
+	switch {
+	case req.Draft:
+		// the draft is edited by the import page, which only knows the config of the csv import task
+		if taskInfo.TaskType == db.TaskTypeNGQL {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the nGQL script task can not be cloned as a draft"))
+		}
+		if req.Address != "" || len(req.Sources) > 0 {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("only the name and the space can be changed for the draft"))
+		}
+		space := taskInfo.Space
+		if req.Space != "" {
+			space = req.Space
+		}
+		id := i.svcCtx.IDGenerator.Generate()
+		if err := importer.GetTaskMgr().NewTaskDraft(id, host, auth.Username, name, space, taskInfo.RawConfig); err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		data = &types.CreateImportTaskData{Id: id}
+	case taskInfo.TaskType == db.TaskTypeNGQL:
+		data, err = i.cloneNGQLTask(taskInfo, name, req)
+	default:
+		data, err = i.cloneImportTask(taskInfo, name, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := importer.GetTaskMgr().SetTaskParent(data.Id, taskInfo.BID, importer.RunTypeClone); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return data, nil
+}
+
+func (i *importService) cloneImportTask(taskInfo *db.TaskInfo, name string, req *types.CloneImportTaskRequest) (*types.CreateImportTaskData, error) {
+	config, err := i.getImportConfig(taskInfo.BID)
+	if err != nil {
+		return nil, err
+	}
+	var cfg types.ImportTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	cfg.Client.User = auth.Username
+	cfg.Client.Password = auth.Password
+	if req.Address != "" {
+		cfg.Client.Address = req.Address
+	}
+	if req.Space != "" {
+		cfg.Manager.SpaceName = req.Space
+	}
+	for _, override := range req.Sources {
+		if override.Index < 0 || override.Index >= len(cfg.Sources) {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("source %d does not exist", override.Index))
+		}
+		if err := overrideSource(cfg.Sources[override.Index], &override); err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+	}
+	cloneConfig, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	// the clone does not continue the task, so it is created as a new task and linked to the task afterwards
+	return i.createImportTask(&types.CreateImportTaskRequest{
+		Name:      name,
+		Config:    string(cloneConfig),
+		RawConfig: taskInfo.RawConfig,
+		Priority:  req.Priority,
+	}, nil)
+}
+
+func (i *importService) cloneNGQLTask(taskInfo *db.TaskInfo, name string, req *types.CloneImportTaskRequest) (*types.CreateImportTaskData, error) {
+	if req.Address != "" {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the nGQL script task runs against the graphd of the current session"))
+	}
+	cfg := &importer.NGQLConfig{}
+	if err := json.Unmarshal([]byte(taskInfo.RawConfig), cfg); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	source := &types.Source{Path: cfg.FilePath}
+	if cfg.DatasourceId != "" {
+		source = &types.Source{DatasourceId: &cfg.DatasourceId, DatasourceFilePath: &cfg.FilePath}
+	}
+	// the task reimporting the failed statements of another task runs them again unless its source is changed
+	failedTaskID := cfg.FailedTaskId
+	for _, override := range req.Sources {
+		failedTaskID = ""
+		if override.Index != 0 {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("source %d does not exist", override.Index))
+		}
+		if err := overrideSource(source, &override); err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+	}
+
+	ngqlReq := &types.CreateNGQLImportTaskRequest{
+		Name:     name,
+		Space:    cfg.Space,
+		File:     source.Path,
+		Batch:    cfg.Batch,
+		Priority: req.Priority,
+	}
+	if source.DatasourceId != nil {
+		ngqlReq.DatasourceId = source.DatasourceId
+		ngqlReq.DatasourceFilePath = source.DatasourceFilePath
+	}
+	if req.Space != "" {
+		ngqlReq.Space = req.Space
+	}
+	return i.createNGQLImportTask(ngqlReq, failedTaskID)
+}
+
+// overrideSource changes where the source reads the file, the secrets of the datasource are resolved again when the task is created
+func overrideSource(source *types.Source, override *types.CloneImportSource) error {
+	switch {
+	case override.Path != nil:
+		source.Path = *override.Path
+		source.DatasourceId, source.DatasourceFilePath = nil, nil
+		source.S3, source.SFTP, source.OSS, source.SQL, source.HTTP = nil, nil, nil, nil, nil
+	case override.DatasourceId != nil:
+		if override.DatasourceFilePath == nil || *override.DatasourceFilePath == "" {
+			return errors.New("datasourceFilePath is required")
+		}
+		source.Path = ""
+		source.DatasourceId, source.DatasourceFilePath = override.DatasourceId, override.DatasourceFilePath
+		source.S3, source.SFTP, source.OSS, source.SQL, source.HTTP = nil, nil, nil, nil, nil
+	case override.DatasourceFilePath != nil:
+		path := *override.DatasourceFilePath
+		switch {
+		case source.DatasourceId != nil:
+			// the table of the sql datasource replaces its query
+			source.DatasourceFilePath, source.SQL = &path, nil
+		case source.S3 != nil:
+			source.S3.Key = path
+		case source.OSS != nil:
+			source.OSS.Key = path
+		case source.SFTP != nil:
+			source.SFTP.Path = path
+		case source.HTTP != nil:
+			source.HTTP.URL = path
+		case source.SQL != nil:
+			source.SQL.Table, source.SQL.Query = path, ""
+		default:
+			return errors.New("the source is a local file, path should be given instead")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,677 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	nebula_go "github.com/vesoft-inc/nebula-go/v3"
+	configbase "github.com/vesoft-inc/nebula-importer/v4/pkg/config/base"
+	configv3 "github.com/vesoft-inc/nebula-importer/v4/pkg/config/v3"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/source"
+	specv3 "github.com/vesoft-inc/nebula-importer/v4/pkg/spec/v3"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/client"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatNGQL = "ngql"
+
+	ExportItemTag  = "tag"
+	ExportItemEdge = "edge"
+
+	exportDirName        = "export"
+	exportConfigName     = "config.yaml"
+	exportDefaultBatch   = 1000
+	exportQueryRetry     = 3
+	exportNullValueTmpl  = "__NULL_%s__"
+	exportVidColumn      = "__vid"
+	exportSrcColumn      = "__src"
+	exportDstColumn      = "__dst"
+	exportRankColumn     = "__rank"
+	exportPropColumnTmpl = "__p%d"
+)
+
+var fileNameReplacer = regexp.MustCompile(`[^\w.-]`)
+
+type (
+	ExportItem struct {
+		Type  string   `json:"type"`
+		Name  string   `json:"name"`
+		Props []string `json:"props,omitempty"`
+	}
+
+	// ExportConfig is stored as the raw config of the export task, so that the task can be resumed with it
+	ExportConfig struct {
+		Space          string       `json:"space"`
+		Format         string       `json:"format"`
+		Batch          int          `json:"batch"`
+		Items          []ExportItem `json:"items"`
+		DatasourceId   string       `json:"datasourceId,omitempty"`
+		DatasourcePath string       `json:"datasourcePath,omitempty"`
+	}
+
+	// Exporter scans the tags and edges of a space page by page, writes them into files,
+	// and saves a checkpoint after each page so that a stopped or failed export can be resumed.
+	Exporter struct {
+		runnerBase
+		Dir string
+		Cfg *ExportConfig
+		// Store is the optional destination of the exported files besides Dir
+		Store filestore.FileStore
+
+		auth   *auth.AuthData
+		nsid   string
+		schema *SpaceSchema
+		// nullValue marks the null values in the csv files, it contains the task id so that it does not collide with the strings exported
+		nullValue string
+	}
+)
+
+func ParseExportConfig(rawConfig string) (*ExportConfig, error) {
+	cfg := &ExportConfig{}
+	if err := json.Unmarshal([]byte(rawConfig), cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ExportDir returns the dir where the exported files of the task are written
+func ExportDir(tasksDir, taskID string) string {
+	return filepath.Join(tasksDir, taskID, exportDirName)
+}
+
+func NewExporter(taskID, dir string, cfg *ExportConfig, authData *auth.AuthData, store filestore.FileStore) *Exporter {
+	if cfg.Batch <= 0 {
+		cfg.Batch = exportDefaultBatch
+	}
+	if cfg.Format == "" {
+		cfg.Format = ExportFormatCSV
+	}
+	return &Exporter{
+		runnerBase: newRunnerBase(taskID),
+		Dir:        dir,
+		Cfg:        cfg,
+		Store:      store,
+		auth:       authData,
+		nullValue:  fmt.Sprintf(exportNullValueTmpl, taskID),
+	}
+}
+
+// Discard releases the runner which is cancelled before it runs
+func (e *Exporter) Discard() {
+	if e.Store != nil {
+		e.Store.Close()
+	}
+}
+
+// Run exports all the items and returns when they are done, failed or stopped
+func (e *Exporter) Run() (err error) {
+	defer close(e.done)
+	defer func() {
+		if e.Store != nil {
+			e.Store.Close()
+		}
+	}()
+	if err = os.MkdirAll(e.Dir, 0o755); err != nil {
+		return err
+	}
+	if err = e.openLog(filepath.Join(filepath.Dir(e.Dir), TaskLogName(db.TaskTypeExport))); err != nil {
+		return err
+	}
+	defer e.closeLog()
+	defer func() {
+		if err != nil && err != errRunnerStopped {
+			e.log("error", "export failed: %s", err)
+		}
+	}()
+
+	clientInfo, err := client.NewClient(e.auth.Address, e.auth.Port, e.auth.Username, e.auth.Password, nebula_go.GetDefaultConf())
+	if err != nil {
+		return fmt.Errorf("connect to nebula failed: %w", err)
+	}
+	e.nsid = clientInfo.ClientID
+	defer client.CloseClient(e.nsid)
+
+	if e.schema, err = DescribeSpace(e.nsid, e.Cfg.Space); err != nil {
+		return fmt.Errorf("describe space failed: %w", err)
+	}
+	items, err := resolveExportItems(e.schema, e.Cfg)
+	if err != nil {
+		return err
+	}
+	if err = checkExportIndexes(e.nsid, e.Cfg.Space, items); err != nil {
+		return err
+	}
+
+	checkpoints := make(map[string]*db.TaskCheckpoint)
+	saved, err := GetTaskMgr().db.FindTaskCheckpoints(e.TaskID)
+	if err != nil {
+		return err
+	}
+	for _, cp := range saved {
+		checkpoints[cp.Name] = cp
+		e.updateStats(func(stats *db.Stats) {
+			stats.TotalRecords += cp.Records
+			stats.TotalProcessed += cp.Records
+			stats.ProcessedBytes += cp.Offset
+		})
+	}
+
+	e.log("info", "start exporting space %s, format: %s, batch: %d", e.Cfg.Space, e.Cfg.Format, e.Cfg.Batch)
+	for _, item := range items {
+		cp, ok := checkpoints[item.key()]
+		if !ok {
+			cp = &db.TaskCheckpoint{TaskID: e.TaskID, Name: item.key()}
+		}
+		if cp.IsFinished {
+			e.log("info", "%s %s has been exported, skip it", item.Type, item.Name)
+			continue
+		}
+		if err = e.exportItem(item, cp); err != nil {
+			if err == errRunnerStopped {
+				e.log("info", "export is stopped at %s %s, records: %d", item.Type, item.Name, cp.Records)
+			}
+			return err
+		}
+		e.log("info", "%s %s is exported, records: %d", item.Type, item.Name, cp.Records)
+	}
+
+	if e.Cfg.Format == ExportFormatCSV {
+		if err = e.writeImporterConfig(items); err != nil {
+			return err
+		}
+	}
+	if e.Store != nil {
+		if err = e.upload(); err != nil {
+			return fmt.Errorf("upload exported files failed: %w", err)
+		}
+	}
+	e.log("info", "export finished, total records: %d", e.Stats().TotalRecords)
+	return nil
+}
+
+type exportItem struct {
+	ExportItem
+	schema *SchemaItem
+	props  []SchemaProp
+}
+
+func (item *exportItem) key() string {
+	return item.Type + ":" + item.Name
+}
+
+func (item *exportItem) fileName(format string) string {
+	return fmt.Sprintf("%s_%s.%s", item.Type, fileNameReplacer.ReplaceAllString(item.Name, "_"), format)
+}
+
+// CheckExportConfig checks the items of the config against the schema and the indexes of the space before the task is created
+func CheckExportConfig(nsid string, cfg *ExportConfig) error {
+	schema, err := DescribeSpace(nsid, cfg.Space)
+	if err != nil {
+		return fmt.Errorf("describe space failed: %w", err)
+	}
+	items, err := resolveExportItems(schema, cfg)
+	if err != nil {
+		return err
+	}
+	return checkExportIndexes(nsid, cfg.Space, items)
+}
+
+// resolveExportItems checks the items against the schema, exports all the tags and edges if no item is given
+func resolveExportItems(schema *SpaceSchema, cfg *ExportConfig) ([]*exportItem, error) {
+	configItems := cfg.Items
+	if len(configItems) == 0 {
+		for _, tag := range schema.Tags {
+			configItems = append(configItems, ExportItem{Type: ExportItemTag, Name: tag.Name})
+		}
+		for _, edge := range schema.Edges {
+			configItems = append(configItems, ExportItem{Type: ExportItemEdge, Name: edge.Name})
+		}
+	}
+	items := make([]*exportItem, 0, len(configItems))
+	for _, it := range configItems {
+		var (
+			schemaItem *SchemaItem
+			ok         bool
+		)
+		switch it.Type {
+		case ExportItemTag:
+			schemaItem, ok = schema.FindTag(it.Name)
+		case ExportItemEdge:
+			schemaItem, ok = schema.FindEdge(it.Name)
+		default:
+			return nil, fmt.Errorf("unknown export item type %s", it.Type)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s %s not found in space %s", it.Type, it.Name, cfg.Space)
+		}
+		item := &exportItem{ExportItem: it, schema: schemaItem}
+		if len(it.Props) == 0 {
+			item.props = schemaItem.Props
+		}
+		for _, name := range it.Props {
+			prop, ok := schemaItem.FindProp(name)
+			if !ok {
+				return nil, fmt.Errorf("prop %s not found in %s %s", name, it.Type, it.Name)
+			}
+			item.props = append(item.props, *prop)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// checkExportIndexes checks that each item has a tag or an edge index, without which MATCH can not scan the tag or the edge
+func checkExportIndexes(nsid, space string, items []*exportItem) error {
+	indexed := make(map[string]bool)
+	for _, typ := range []string{ExportItemTag, ExportItemEdge} {
+		rows, err := executeOne(nsid, space, fmt.Sprintf("SHOW %s INDEXES", strings.ToUpper(typ)))
+		if err != nil {
+			return fmt.Errorf("show the %s indexes failed: %w", typ, err)
+		}
+		column := "By Tag"
+		if typ == ExportItemEdge {
+			column = "By Edge"
+		}
+		for _, row := range rows {
+			indexed[typ+":"+fmt.Sprintf("%v", row[column])] = true
+		}
+	}
+	for _, item := range items {
+		if !indexed[item.key()] {
+			return fmt.Errorf("%[1]s %[2]s has no index to be scanned, create one with CREATE %[3]s INDEX IF NOT EXISTS %[4]s ON %[5]s() and rebuild it before exporting",
+				item.Type, item.Name, strings.ToUpper(item.Type), QuoteName(item.Name+"_export_index"), QuoteName(item.Name))
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) exportItem(item *exportItem, cp *db.TaskCheckpoint) error {
+	filePath := filepath.Join(e.Dir, item.fileName(e.Cfg.Format))
+	var (
+		f   *os.File
+		err error
+	)
+	if cp.Offset > 0 {
+		// drop anything written after the last checkpoint
+		if err = os.Truncate(filePath, cp.Offset); err != nil {
+			return err
+		}
+		f, err = os.OpenFile(filePath, os.O_WRONLY|os.O_APPEND, 0o644)
+	} else {
+		f, err = os.Create(filePath)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if cp.Offset == 0 && e.Cfg.Format == ExportFormatCSV {
+		if err = e.writeCSVRows(w, [][]string{item.header()}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if err := e.checkStopped(); err != nil {
+			return err
+		}
+
+		rows, err := e.query(item.buildQuery(e.Cfg.Batch, cp.Cursor))
+		if err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if e.Cfg.Format == ExportFormatNGQL {
+				_, err = w.WriteString(item.insertStatement(rows))
+			} else {
+				var records [][]string
+				if records, err = item.csvRows(rows, e.nullValue); err == nil {
+					err = e.writeCSVRows(w, records)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if cp.Cursor, err = item.cursor(rows[len(rows)-1]); err != nil {
+				return err
+			}
+		}
+		if err = w.Flush(); err != nil {
+			return err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		e.updateStats(func(stats *db.Stats) {
+			stats.TotalRecords += int64(len(rows))
+			stats.TotalProcessed += int64(len(rows))
+			stats.ProcessedBytes += info.Size() - cp.Offset
+		})
+
+		cp.Records += int64(len(rows))
+		cp.Offset = info.Size()
+		cp.IsFinished = len(rows) < e.Cfg.Batch
+		if err = GetTaskMgr().db.SaveTaskCheckpoint(cp); err != nil {
+			return err
+		}
+		if cp.IsFinished {
+			return nil
+		}
+	}
+}
+
+func (e *Exporter) writeCSVRows(w *bufio.Writer, rows [][]string) error {
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// query executes the gql with retries and records the request stats
+func (e *Exporter) query(gql string) ([]map[string]client.Any, error) {
+	var lastErr error
+	for i := 0; i < exportQueryRetry; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * time.Second)
+		}
+		start := time.Now()
+		results, err := client.Execute(e.nsid, e.Cfg.Space, []string{gql})
+		e.updateStats(func(stats *db.Stats) {
+			stats.TotalRequest++
+			stats.TotalRespTime += time.Since(start)
+			if err == nil && len(results) > 0 {
+				stats.TotalLatency += time.Duration(results[0].Result.TimeCost) * time.Microsecond
+			}
+		})
+		if err == nil && len(results) > 0 && results[0].Error == nil {
+			return results[0].Result.Tables, nil
+		}
+		if err == nil && len(results) > 0 {
+			err = results[0].Error
+		} else if err == nil {
+			err = fmt.Errorf("no result for %s", gql)
+		}
+		e.updateStats(func(stats *db.Stats) {
+			stats.FailedRequest++
+		})
+		lastErr = err
+		e.log("warn", "query failed(%d/%d): %s, gql: %s", i+1, exportQueryRetry, err, gql)
+	}
+	return nil, lastErr
+}
+
+func (item *exportItem) header() []string {
+	var header []string
+	if item.Type == ExportItemTag {
+		header = []string{"vid"}
+	} else {
+		header = []string{"src", "dst", "rank"}
+	}
+	for _, p := range item.props {
+		header = append(header, p.Name)
+	}
+	return header
+}
+
+/*
+buildQuery pages the tag or the edge by its key after the cursor, MATCH scans the rows by the index of the tag or the edge,
+ORDER BY with LIMIT is planned as TopN by graphd, so only a batch of rows is kept in memory for each page,
+while each page still scans all the rows after the cursor, which is about N*N/(2*batch) rows scanned for N rows in total,
+set a larger batch to export the tags and edges with lots of rows
+*/
+func (item *exportItem) buildQuery(batch int, cursor string) string {
+	var (
+		returns []string
+		where   string
+		orderBy string
+		pattern string
+	)
+	name := QuoteName(item.Name)
+	if item.Type == ExportItemTag {
+		pattern = fmt.Sprintf("(v:%s)", name)
+		returns = append(returns, fmt.Sprintf("id(v) AS %s", exportVidColumn))
+		if cursor != "" {
+			var vid string
+			_ = json.Unmarshal([]byte(cursor), &vid)
+			where = fmt.Sprintf(" WHERE id(v) > %s", vid)
+		}
+		orderBy = exportVidColumn
+	} else {
+		pattern = fmt.Sprintf("()-[e:%s]->()", name)
+		returns = append(returns,
+			fmt.Sprintf("src(e) AS %s", exportSrcColumn),
+			fmt.Sprintf("dst(e) AS %s", exportDstColumn),
+			fmt.Sprintf("rank(e) AS %s", exportRankColumn),
+		)
+		if cursor != "" {
+			var last []string
+			_ = json.Unmarshal([]byte(cursor), &last)
+			if len(last) == 3 {
+				where = fmt.Sprintf(" WHERE src(e) > %[1]s OR (src(e) == %[1]s AND dst(e) > %[2]s) OR (src(e) == %[1]s AND dst(e) == %[2]s AND rank(e) > %[3]s)",
+					last[0], last[1], last[2])
+			}
+		}
+		orderBy = strings.Join([]string{exportSrcColumn, exportDstColumn, exportRankColumn}, ", ")
+	}
+	for i, p := range item.props {
+		if item.Type == ExportItemTag {
+			returns = append(returns, fmt.Sprintf("v.%s.%s AS %s", name, QuoteName(p.Name), fmt.Sprintf(exportPropColumnTmpl, i)))
+		} else {
+			returns = append(returns, fmt.Sprintf("e.%s AS %s", QuoteName(p.Name), fmt.Sprintf(exportPropColumnTmpl, i)))
+		}
+	}
+	return fmt.Sprintf("MATCH %s%s RETURN %s ORDER BY %s LIMIT %d", pattern, where, strings.Join(returns, ", "), orderBy, batch)
+}
+
+// cursor is the json encoded nGQL literals of the key of the last row
+func (item *exportItem) cursor(row map[string]client.Any) (string, error) {
+	var v interface{}
+	if item.Type == ExportItemTag {
+		v = idLiteral(row[exportVidColumn])
+	} else {
+		v = []string{idLiteral(row[exportSrcColumn]), idLiteral(row[exportDstColumn]), fmt.Sprintf("%v", row[exportRankColumn])}
+	}
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// csvRows formats the rows as csv records, the null values of the nullable props are written as the nullValue
+func (item *exportItem) csvRows(rows []map[string]client.Any, nullValue string) ([][]string, error) {
+	records := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		var record []string
+		if item.Type == ExportItemTag {
+			record = []string{csvValue(row[exportVidColumn])}
+		} else {
+			record = []string{csvValue(row[exportSrcColumn]), csvValue(row[exportDstColumn]), csvValue(row[exportRankColumn])}
+		}
+		for i, p := range item.props {
+			v := row[fmt.Sprintf(exportPropColumnTmpl, i)]
+			value := csvValue(v)
+			switch {
+			case v == nil && p.Nullable:
+				value = nullValue
+			case value == nullValue:
+				return nil, fmt.Errorf("the value of %s.%s collides with the null value %s", item.Name, p.Name, nullValue)
+			}
+			record = append(record, value)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (item *exportItem) insertStatement(rows []map[string]client.Any) string {
+	names := make([]string, 0, len(item.props))
+	for _, p := range item.props {
+		names = append(names, QuoteName(p.Name))
+	}
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		props := make([]string, 0, len(item.props))
+		for i := range item.props {
+			props = append(props, item.props[i].literal(row[fmt.Sprintf(exportPropColumnTmpl, i)]))
+		}
+		if item.Type == ExportItemTag {
+			values = append(values, fmt.Sprintf("%s:(%s)", idLiteral(row[exportVidColumn]), strings.Join(props, ", ")))
+		} else {
+			values = append(values, fmt.Sprintf("%s->%s@%v:(%s)", idLiteral(row[exportSrcColumn]), idLiteral(row[exportDstColumn]),
+				row[exportRankColumn], strings.Join(props, ", ")))
+		}
+	}
+	kind := "VERTEX"
+	if item.Type == ExportItemEdge {
+		kind = "EDGE"
+	}
+	return fmt.Sprintf("INSERT %s %s(%s) VALUES %s;\n", kind, QuoteName(item.Name), strings.Join(names, ", "), strings.Join(values, ", "))
+}
+
+func idLiteral(v client.Any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func csvValue(v client.Any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// literal formats the value returned by the client as the nGQL literal of the prop type
+func (p *SchemaProp) literal(v client.Any) string {
+	if v == nil {
+		return "NULL"
+	}
+	s := csvValue(v)
+	typ := p.ImporterValueType()
+	switch {
+	case typ == "STRING":
+		return strconv.Quote(s)
+	case typ == "DATE", typ == "TIME", typ == "DATETIME":
+		return fmt.Sprintf("%s(%s)", strings.ToLower(typ), strconv.Quote(s))
+	case strings.HasPrefix(typ, "GEOGRAPHY"):
+		return fmt.Sprintf("ST_GeogFromText(%s)", strconv.Quote(s))
+	}
+	return s
+}
+
+// writeImporterConfig generates the nebula-importer config to import the exported csv files
+func (e *Exporter) writeImporterConfig(items []*exportItem) error {
+	vidType := specv3.ValueTypeString
+	if e.schema.IsIntVid() {
+		vidType = specv3.ValueTypeInt
+	}
+	conf := &configv3.Config{
+		Client: configv3.Client{
+			Version:  configbase.ClientVersion3,
+			Address:  "${YOUR_NEBULA_ADDRESS}",
+			User:     "${YOUR_NEBULA_NAME}",
+			Password: "${YOUR_NEBULA_PASSWORD}",
+		},
+		Manager: configv3.Manager{
+			GraphName: e.Cfg.Space,
+			Manager: configbase.Manager{
+				Batch: 128,
+			},
+		},
+	}
+	for _, item := range items {
+		props := make(specv3.Props, 0, len(item.props))
+		offset := 1
+		if item.Type == ExportItemEdge {
+			offset = 3
+		}
+		for i, p := range item.props {
+			props = append(props, &specv3.Prop{
+				Name:      p.Name,
+				Type:      specv3.ValueType(p.ImporterValueType()),
+				Index:     offset + i,
+				Nullable:  p.Nullable,
+				NullValue: e.nullValue,
+			})
+		}
+		src := configv3.Source{
+			Source: configbase.Source{
+				SourceConfig: source.Config{
+					Local: &source.LocalConfig{Path: item.fileName(ExportFormatCSV)},
+					CSV:   &source.CSVConfig{WithHeader: true},
+				},
+			},
+		}
+		if item.Type == ExportItemTag {
+			src.Nodes = specv3.Nodes{{
+				Name:  item.Name,
+				ID:    &specv3.NodeID{Type: vidType, Index: 0},
+				Props: props,
+			}}
+		} else {
+			src.Edges = specv3.Edges{{
+				Name:  item.Name,
+				Src:   &specv3.EdgeNodeRef{ID: &specv3.NodeID{Type: vidType, Index: 0}},
+				Dst:   &specv3.EdgeNodeRef{ID: &specv3.NodeID{Type: vidType, Index: 1}},
+				Rank:  &specv3.Rank{Index: 2},
+				Props: props,
+			}}
+		}
+		conf.Sources = append(conf.Sources, src)
+	}
+	outYaml, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(e.Dir, exportConfigName), outYaml, 0o644); err != nil {
+		return err
+	}
+	return GetTaskMgr().db.UpdateTaskEffect(&db.TaskEffect{BID: e.TaskID, Config: string(outYaml)})
+}
+
+func (e *Exporter) upload() error {
+	entries, err := os.ReadDir(e.Dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := e.uploadFile(entry.Name()); err != nil {
+			return err
+		}
+		e.log("info", "%s is uploaded to %s", entry.Name(), e.Cfg.DatasourcePath)
+	}
+	return nil
+}
+
+func (e *Exporter) uploadFile(name string) error {
+	f, err := os.Open(filepath.Join(e.Dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return e.Store.WriteFile(path.Join(e.Cfg.DatasourcePath, name), f)
+}
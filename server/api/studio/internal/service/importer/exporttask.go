@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"errors"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+)
+
+// FindExportTask is used to check whether the export task belongs to the user
+func FindExportTask(taskID, address, username string) (*db.TaskInfo, error) {
+	taskInfo, err := taskmgr.db.FindTaskInfoByIdAndAddresssAndUser(taskID, address, username)
+	if err != nil || taskInfo.TaskType != db.TaskTypeExport {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("task not existed"))
+	}
+	return taskInfo, nil
+}
+
+func GetExportTask(taskID, address, username string) (*types.GetExportTaskData, error) {
+	taskInfo, err := FindExportTask(taskID, address, username)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := GetTaskMgr().getTaskFromMap(taskID); ok {
+		taskInfo = t.TaskInfo
+	}
+	data := toExportTaskData(taskInfo)
+	return &data, nil
+}
+
+func GetManyExportTask(address, username, space string, pageIndex, pageSize int) (*types.GetManyExportTaskData, error) {
+	result := &types.GetManyExportTaskData{
+		Total: 0,
+		List:  []types.GetExportTaskData{},
+	}
+
+	tasks, count, err := taskmgr.db.FindTaskInfoByAddressAndUser(address, username, space, []string{db.TaskTypeExport}, pageIndex, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if running, ok := GetTaskMgr().getTaskFromMap(t.BID); ok {
+			t = running.TaskInfo
+		}
+		result.List = append(result.List, toExportTaskData(t))
+	}
+	result.Total = count
+	return result, nil
+}
+
+func StopExportTask(taskID, address, username string) error {
+	if _, err := FindExportTask(taskID, address, username); err != nil {
+		return err
+	}
+	if err := GetTaskMgr().StopTask(taskID); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return nil
+}
+
+func DeleteExportTask(tasksDir, taskID, address, username string) error {
+	if _, err := FindExportTask(taskID, address, username); err != nil {
+		return err
+	}
+	if _, ok := GetTaskMgr().getTaskFromMap(taskID); ok {
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("task is running, please stop it first"))
+	}
+	if err := GetTaskMgr().DelTask(tasksDir, taskID); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return nil
+}
+
+func toExportTaskData(t *db.TaskInfo) types.GetExportTaskData {
+	stats := t.Stats
+	return types.GetExportTaskData{
+		Id:         t.BID,
+		Name:       t.Name,
+		User:       t.User,
+		Address:    t.Address,
+		Space:      t.Space,
+		Status:     t.TaskStatus,
+		Message:    t.TaskMessage,
+		CreateTime: t.CreateTime.UnixMilli(),
+		UpdateTime: t.UpdateTime.UnixMilli(),
+		RawConfig:  t.RawConfig,
+		Stats: types.ImportTaskStats{
+			TotalBytes:      stats.TotalBytes,
+			ProcessedBytes:  stats.ProcessedBytes,
+			FailedRecords:   stats.FailedRecords,
+			TotalRecords:    stats.TotalRecords,
+			TotalRequest:    stats.TotalRequest,
+			FailedRequest:   stats.FailedRequest,
+			TotalLatency:    int64(stats.TotalLatency),
+			TotalRespTime:   int64(stats.TotalRespTime),
+			FailedProcessed: stats.FailedProcessed,
+			TotalProcessed:  stats.TotalProcessed,
+		},
+	}
+}
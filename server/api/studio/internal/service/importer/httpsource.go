@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/source"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+)
+
+type (
+	// HTTPSource is how the file is downloaded over http(s), nebula-importer has no http source, the file is streamed as it is read
+	HTTPSource struct {
+		Config types.HTTPConfig
+	}
+
+	// httpSource is the source of nebula-importer whose content is the body of the response
+	httpSource struct {
+		source.Source
+		http  *HTTPSource
+		store *filestore.HTTPStore
+		body  io.ReadCloser
+		size  int64
+	}
+)
+
+// NewHTTPSource checks the url of the http source, it returns nil for the source which is not from a url
+func NewHTTPSource(s *types.Source) (*HTTPSource, error) {
+	if s.HTTP == nil {
+		return nil, nil
+	}
+	if s.HTTP.URL == "" {
+		return nil, errors.New("the url of the http source is required")
+	}
+	if format := sourceFormat(s); format == SourceFormatParquet {
+		return nil, fmt.Errorf("the %s file can not be read over http", format)
+	}
+	hs := &HTTPSource{Config: *s.HTTP}
+	if _, err := hs.OpenStore(); err != nil {
+		return nil, err
+	}
+	return hs, nil
+}
+
+// Name identifies the file downloaded without the credentials in the url
+func (hs *HTTPSource) Name() string {
+	u, err := url.Parse(hs.Config.URL)
+	if err != nil {
+		return hs.Config.URL
+	}
+	u.User = nil
+	return u.String()
+}
+
+// OpenStore returns the store with the credentials of the source, the url is absolute in the store
+func (hs *HTTPSource) OpenStore() (*filestore.HTTPStore, error) {
+	c := hs.Config
+	return filestore.NewHTTPStore("", nil, c.AuthType, c.Username, c.Password)
+}
+
+// wrapHTTPSource downloads the file of the http source, the size is the content length told by the server
+func wrapHTTPSource(s source.Source, hs *HTTPSource) source.Source {
+	return &httpSource{Source: s, http: hs}
+}
+
+func (s *httpSource) Name() string {
+	return s.http.Name()
+}
+
+func (s *httpSource) Open() error {
+	store, err := s.http.OpenStore()
+	if err != nil {
+		return err
+	}
+	// the size is unknown if the server does not support the head request
+	if info, err := store.Stat(s.http.Config.URL); err == nil {
+		s.size = info.Size
+	}
+	body, err := store.Open(s.http.Config.URL)
+	if err != nil {
+		store.Close()
+		return err
+	}
+	s.store, s.body = store, body
+	return nil
+}
+
+func (s *httpSource) Size() (int64, error) {
+	return s.size, nil
+}
+
+func (s *httpSource) Read(p []byte) (int, error) {
+	return s.body.Read(p)
+}
+
+func (s *httpSource) Close() error {
+	if s.body == nil {
+		return nil
+	}
+	err := s.body.Close()
+	s.store.Close()
+	return err
+}
@@ -3,9 +3,14 @@ package importer
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"time"
 
+	configv3 "github.com/vesoft-inc/nebula-importer/v4/pkg/config/v3"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/logger"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/manager"
+	studioConfig "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
 	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
@@ -13,6 +18,34 @@ import (
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+// ImportTaskTypes are the task types listed as import tasks
+var ImportTaskTypes = []string{db.TaskTypeImport, db.TaskTypeNGQL}
+
+func FindTaskCheckpoints(taskID string) ([]*db.TaskCheckpoint, error) {
+	return taskmgr.db.FindTaskCheckpoints(taskID)
+}
+
+func FindInterruptedTasks() ([]*db.TaskInfo, error) {
+	return taskmgr.db.FindInterruptedTasks()
+}
+
+// FindTaskSecret returns the encrypted password of the user who runs the task
+func FindTaskSecret(taskID string) (string, error) {
+	var taskEffect db.TaskEffect
+	if err := taskmgr.db.Select("secret").Where("task_id = ?", taskID).First(&taskEffect).Error; err != nil {
+		return "", err
+	}
+	return taskEffect.Secret, nil
+}
+
+func FindImportTask(taskID, address, username string) (*db.TaskInfo, error) {
+	taskInfo, err := taskmgr.db.FindTaskInfoByIdAndAddresssAndUser(taskID, address, username)
+	if err != nil || (taskInfo.TaskType != db.TaskTypeImport && taskInfo.TaskType != db.TaskTypeNGQL) {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("task not existed"))
+	}
+	return taskInfo, nil
+}
+
 type ImportResult struct {
 	TaskId      string `json:"taskId"`
 	TimeCost    string `json:"timeCost"` // Milliseconds
@@ -23,8 +56,21 @@ type ImportResult struct {
 	}
 }
 
-func StartImport(taskID string) (err error) {
-	task, _ := GetTaskMgr().GetTask(taskID)
+// StartImport queues the task, which is run when the limits of the queue allow
+func StartImport(taskID string) error {
+	task, ok := GetTaskMgr().getTaskFromMap(taskID)
+	if !ok {
+		return errors.New("task is not running")
+	}
+	GetTaskQueue().Push(task, func() {
+		runImport(taskID, task)
+	})
+	return nil
+}
+
+func runImport(taskID string, task *Task) {
+	var err error
+	GetTaskMgr().setRunning(task)
 	signal := make(chan struct{}, 1)
 
 	abort := func() {
@@ -55,15 +101,20 @@ func StartImport(taskID string) (err error) {
 				GetTaskMgr().AbortTask(taskID)
 			}
 		}()
-		cfg := task.Client.Cfg
-		if err = cfg.Build(); err != nil {
+		if task.Client.Tracker == nil {
+			task.Client.Tracker = NewImportTracker(taskID, filepath.Join(studioConfig.GetConfig().File.TasksDir, taskID))
+		}
+		var (
+			mgr manager.Manager
+			l   logger.Logger
+		)
+		mgr, l, err = task.Client.Tracker.Build(task.Client.Cfg.(*configv3.Config))
+		if err != nil {
 			abort()
 			return
 		}
-		mgr := cfg.GetManager()
-		logger := cfg.GetLogger()
 		task.Client.Manager = mgr
-		task.Client.Logger = logger
+		task.Client.Logger = l
 		if err = mgr.Start(); err != nil {
 			abort()
 			return
@@ -82,7 +133,6 @@ func StartImport(taskID string) (err error) {
 		}
 		signal <- struct{}{}
 	}()
-	return nil
 }
 
 func DeleteImportTask(tasksDir, taskID, address, username string) error {
@@ -124,6 +174,11 @@ func GetImportTask(taskID, address, username string) (*types.GetImportTaskData,
 		result.Name = task.TaskInfo.Name
 		result.Space = task.TaskInfo.Space
 		result.RawConfig = task.TaskInfo.RawConfig
+		result.ScheduleId = task.TaskInfo.ScheduleID
+		result.ParentTaskId = task.TaskInfo.ParentTaskID
+		result.RunType = task.TaskInfo.RunType
+		result.Priority = task.TaskInfo.Priority
+		result.QueuePosition = GetTaskQueue().Position(task.TaskInfo.BID)
 		result.Stats = types.ImportTaskStats{
 			TotalBytes:      stats.TotalBytes,
 			ProcessedBytes:  stats.ProcessedBytes,
@@ -147,11 +202,61 @@ func GetManyImportTask(address, username, space string, pageIndex, pageSize int)
 		List:  []types.GetImportTaskData{},
 	}
 
-	tasks, count, err := taskmgr.db.FindTaskInfoByAddressAndUser(address, username, space, pageIndex, pageSize)
+	tasks, count, err := taskmgr.db.FindTaskInfoByAddressAndUser(address, username, space, ImportTaskTypes, pageIndex, pageSize)
 	if err != nil {
 		return nil, err
 	}
 
+	list, err := toImportTaskList(tasks)
+	if err != nil {
+		return nil, err
+	}
+	result.List = list
+	result.Total = count
+
+	return result, nil
+}
+
+func StopImportTask(taskID, address, username string) error {
+	_, err := taskmgr.db.FindTaskInfoByIdAndAddresssAndUser(taskID, address, username)
+	if err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+
+	err = GetTaskMgr().StopTask(taskID)
+	if err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	} else {
+		return nil
+	}
+}
+
+func parseImportAddress(address string) ([]string, error) {
+	re := regexp.MustCompile(`,\s*`)
+	split := re.Split(address, -1)
+	importAddress := append([]string{}, split...)
+
+	return importAddress, nil
+}
+
+// GetScheduleRuns lists the tasks created by the schedule
+func GetScheduleRuns(scheduleID string, pageIndex, pageSize int) (*types.GetManyImportTaskData, error) {
+	tasks, count, err := taskmgr.db.FindTaskInfoBySchedule(scheduleID, pageIndex, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	list, err := toImportTaskList(tasks)
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetManyImportTaskData{
+		Total: count,
+		List:  list,
+	}, nil
+}
+
+func toImportTaskList(tasks []*db.TaskInfo) ([]types.GetImportTaskData, error) {
+	list := []types.GetImportTaskData{}
 	for _, t := range tasks {
 		importAddress, err := parseImportAddress(t.ImportAddress)
 		if err != nil {
@@ -193,33 +298,14 @@ func GetManyImportTask(address, username, space string, pageIndex, pageSize int)
 				FailedProcessed: stats.FailedProcessed,
 				TotalProcessed:  stats.TotalProcessed,
 			},
-			LLMJob: llmJob,
+			LLMJob:        llmJob,
+			ScheduleId:    t.ScheduleID,
+			ParentTaskId:  t.ParentTaskID,
+			RunType:       t.RunType,
+			Priority:      t.Priority,
+			QueuePosition: GetTaskQueue().Position(t.BID),
 		}
-		result.List = append(result.List, data)
-	}
-	result.Total = count
-
-	return result, nil
-}
-
-func StopImportTask(taskID, address, username string) error {
-	_, err := taskmgr.db.FindTaskInfoByIdAndAddresssAndUser(taskID, address, username)
-	if err != nil {
-		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
-	}
-
-	err = GetTaskMgr().StopTask(taskID)
-	if err != nil {
-		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
-	} else {
-		return nil
+		list = append(list, data)
 	}
-}
-
-func parseImportAddress(address string) ([]string, error) {
-	re := regexp.MustCompile(`,\s*`)
-	split := re.Split(address, -1)
-	importAddress := append([]string{}, split...)
-
-	return importAddress, nil
+	return list, nil
 }
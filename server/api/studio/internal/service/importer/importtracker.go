@@ -0,0 +1,438 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/client"
+	configv3 "github.com/vesoft-inc/nebula-importer/v4/pkg/config/v3"
+	importerpkg "github.com/vesoft-inc/nebula-importer/v4/pkg/importer"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/logger"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/manager"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/reader"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/source"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/spec"
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/utils"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	RunTypeResume = "resume"
+	RunTypeRetry  = "retry"
+	// RunTypeClone is the task cloned from another one, it runs on its own without the stats of the task
+	RunTypeClone = "clone"
+
+	importErrDir           = "err"
+	importSourceNameFormat = "source-%d"
+	checkpointSaveInterval = 2 * time.Second
+)
+
+/*
+ImportTracker follows the csv import task by wrapping the sources and the importers of nebula-importer:
+  - the byte offset of each source, below which all the batches are done, is saved as the checkpoint
+  - the records of the failed batches are kept in the err dir of the task, one csv file for each source
+*/
+type ImportTracker struct {
+	TaskID string
+	Dir    string
+	// Resume holds the checkpoints of the task resumed, by the name of the source
+	Resume map[string]*db.TaskCheckpoint
+	// Base is the stats of the task resumed or retried, which is merged into the stats of the run
+	Base    db.Stats
+	RunType string
+	// JSON holds the json sources by their indices, which are read as csv
+	JSON map[int]*JSONSource
+	// Parquet holds the parquet sources by their indices, which are read as csv
+	Parquet map[int]*ParquetSource
+	// SQL holds the sources read from the databases by their indices, which are read as csv
+	SQL map[int]*SQLSource
+	// HTTP holds the sources downloaded over http by their indices
+	HTTP map[int]*HTTPSource
+	// SFTP holds the sftp sources whose hosts are verified by their indices
+	SFTP map[int]*SFTPSource
+
+	sources []*sourceTracker
+}
+
+func NewImportTracker(taskID, dir string) *ImportTracker {
+	return &ImportTracker{
+		TaskID: taskID,
+		Dir:    dir,
+	}
+}
+
+// ImportSourceName is the checkpoint name of the i-th source in the config
+func ImportSourceName(i int) string {
+	return fmt.Sprintf(importSourceNameFormat, i)
+}
+
+// FailedRecordsPath is the file which keeps the failed records of the i-th source
+func FailedRecordsPath(taskDir string, i int) string {
+	return filepath.Join(taskDir, importErrDir, ImportSourceName(i)+".csv")
+}
+
+// FailedRecordsSource returns the index of the source whose failed records are kept in the file
+func FailedRecordsSource(name string) (int, bool) {
+	var i int
+	if _, err := fmt.Sscanf(name, importSourceNameFormat+".csv", &i); err != nil || name != ImportSourceName(i)+".csv" {
+		return 0, false
+	}
+	return i, true
+}
+
+// Build is like the Build of the config, except that the sources and the importers are tracked
+func (t *ImportTracker) Build(conf *configv3.Config) (manager.Manager, logger.Logger, error) {
+	l, err := conf.BuildLogger()
+	if err != nil {
+		return nil, nil, err
+	}
+	pool, err := conf.BuildClientPool(
+		client.WithLogger(l),
+		client.WithClientInitFunc(func(cli client.Client) error {
+			resp, err := cli.Execute(fmt.Sprintf("USE %s", utils.ConvertIdentifier(conf.Manager.GraphName)))
+			if err != nil {
+				return err
+			}
+			if !resp.IsSucceed() {
+				return resp.GetError()
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		_ = l.Close()
+		return nil, nil, err
+	}
+	m := conf.Manager
+	mgr := manager.NewWithOpts(
+		manager.WithClientPool(pool),
+		manager.WithBatch(m.Batch),
+		manager.WithReaderConcurrency(m.ReaderConcurrency),
+		manager.WithImporterConcurrency(m.ImporterConcurrency),
+		manager.WithStatsInterval(m.StatsInterval),
+		manager.WithBeforeHooks(m.Hooks.Before...),
+		manager.WithAfterHooks(m.Hooks.After...),
+		manager.WithLogger(l),
+		manager.WithGetClientOptions(client.WithClientInitFunc(nil)), // clean the USE SPACE in 3.x
+	)
+
+	for i := range conf.Sources {
+		s := conf.Sources[i]
+		ss := t.SQL[i]
+		if ss != nil {
+			// the rows are not read from a file, the local source is only the placeholder wrapped
+			s.SourceConfig.Local = &source.LocalConfig{Path: ss.Name()}
+		}
+		hs := t.HTTP[i]
+		if hs != nil {
+			s.SourceConfig.Local = &source.LocalConfig{Path: hs.Name()}
+		}
+		st := &sourceTracker{
+			tracker: t,
+			index:   i,
+			cp:      &db.TaskCheckpoint{TaskID: t.TaskID, Name: ImportSourceName(i), Cursor: sourceName(&s.SourceConfig)},
+			done:    map[int64]*trackedBatch{},
+			pending: map[*spec.Record]*trackedBatch{},
+			comma:   ',',
+		}
+		if c := s.SourceConfig.CSV; c != nil {
+			if chars := []rune(c.Delimiter); len(chars) > 0 {
+				st.comma = chars[0]
+			}
+		}
+		if resumed, ok := t.Resume[st.cp.Name]; ok {
+			st.cp.Offset, st.cp.Records, st.cp.Failed, st.cp.IsFinished = resumed.Offset, resumed.Records, resumed.Failed, resumed.IsFinished
+		}
+		t.sources = append(t.sources, st)
+		if st.cp.IsFinished {
+			st.save()
+			continue
+		}
+
+		sourceConfig := s.SourceConfig.Clone()
+		if st.cp.Offset > 0 && sourceConfig.CSV != nil {
+			// the header is before the offset
+			sourceConfig.CSV.WithHeader = false
+		}
+		src, err := source.New(sourceConfig)
+		if err != nil {
+			_ = pool.Close()
+			_ = l.Close()
+			return nil, nil, err
+		}
+		if hs != nil {
+			// the content downloaded is converted by the other wrappers
+			src = wrapHTTPSource(src, hs)
+		}
+		if ss := t.SFTP[i]; ss != nil {
+			src = wrapSFTPSource(src, ss)
+		}
+		if js := t.JSON[i]; js != nil {
+			// the offset and the failed records are of the csv converted
+			src = wrapJSONSource(src, js)
+		}
+		if ps := t.Parquet[i]; ps != nil {
+			src = wrapParquetSource(src, ps)
+		}
+		if ss != nil {
+			src = wrapSQLSource(src, ss)
+		}
+		opts := []reader.Option{reader.WithBatch(m.Batch), reader.WithLogger(l)}
+		if s.Batch > 0 {
+			opts = append(opts, reader.WithBatch(s.Batch))
+		}
+		tracked := &trackedSource{Source: src, offset: st.cp.Offset}
+		brr := &trackedReader{
+			BatchRecordReader: reader.NewBatchRecordReader(reader.NewRecordReader(tracked), opts...),
+			st:                st,
+		}
+
+		importers, err := s.BuildImporters(m.GraphName, pool)
+		if err != nil {
+			_ = pool.Close()
+			_ = l.Close()
+			return nil, nil, err
+		}
+		st.importers = len(importers)
+		trackedImporters := make([]importerpkg.Importer, 0, len(importers))
+		for _, i := range importers {
+			trackedImporters = append(trackedImporters, &trackedImporter{Importer: i, st: st})
+		}
+		if err = mgr.Import(tracked, brr, trackedImporters...); err != nil {
+			_ = pool.Close()
+			_ = l.Close()
+			return nil, nil, err
+		}
+	}
+	return mgr, l, nil
+}
+
+// Flush saves the checkpoints and closes the failed records files, it is called when the task is finished, stopped or aborted
+func (t *ImportTracker) Flush() {
+	for _, st := range t.sources {
+		st.mu.Lock()
+		st.save()
+		if st.failedF != nil {
+			st.failedF.Close()
+			st.failedF, st.failedW = nil, nil
+		}
+		st.mu.Unlock()
+	}
+}
+
+// MergeStats merges the stats of the run into the stats of the task it continues
+func (t *ImportTracker) MergeStats(run db.Stats) db.Stats {
+	base := t.Base
+	switch t.RunType {
+	case RunTypeResume:
+		run.ProcessedBytes += base.ProcessedBytes
+		run.TotalBytes += base.TotalBytes
+		run.TotalRecords += base.TotalRecords
+		run.FailedRecords += base.FailedRecords
+		run.TotalProcessed += base.TotalProcessed
+		run.FailedProcessed += base.FailedProcessed
+	case RunTypeRetry:
+		// the records retried have been counted by the task, only the ones still failed are left
+		run.TotalRecords = base.TotalRecords
+		run.TotalProcessed = base.TotalProcessed
+	default:
+		return run
+	}
+	run.TotalRequest += base.TotalRequest
+	run.FailedRequest += base.FailedRequest
+	run.TotalLatency += base.TotalLatency
+	run.TotalRespTime += base.TotalRespTime
+	return run
+}
+
+func sourceName(c *source.Config) string {
+	if src, err := source.New(c); err == nil {
+		return src.Name()
+	}
+	return ""
+}
+
+type (
+	sourceTracker struct {
+		tracker   *ImportTracker
+		index     int
+		importers int
+		comma     rune
+
+		mu       sync.Mutex
+		cp       *db.TaskCheckpoint
+		seq      int64
+		next     int64
+		eof      bool
+		pending  map[*spec.Record]*trackedBatch
+		done     map[int64]*trackedBatch
+		lastSave time.Time
+		failedW  *csv.Writer
+		failedF  *os.File
+	}
+
+	trackedBatch struct {
+		seq       int64
+		nBytes    int
+		records   spec.Records
+		remaining int
+		failed    bool
+	}
+
+	// trackedSource skips the bytes before the offset, which have been imported
+	trackedSource struct {
+		source.Source
+		offset int64
+	}
+
+	trackedReader struct {
+		reader.BatchRecordReader
+		st *sourceTracker
+	}
+
+	trackedImporter struct {
+		importerpkg.Importer
+		st *sourceTracker
+	}
+)
+
+func (s *trackedSource) Open() error {
+	if err := s.Source.Open(); err != nil {
+		return err
+	}
+	if s.offset > 0 {
+		if _, err := io.CopyN(io.Discard, s.Source, s.offset); err != nil {
+			return fmt.Errorf("skip to offset %d failed: %w", s.offset, err)
+		}
+	}
+	return nil
+}
+
+func (s *trackedSource) Size() (int64, error) {
+	size, err := s.Source.Size()
+	return size - s.offset, err
+}
+
+func (r *trackedReader) ReadBatch() (int, spec.Records, error) {
+	n, records, err := r.BatchRecordReader.ReadBatch()
+	r.st.read(n, records, err)
+	return n, records, err
+}
+
+func (i *trackedImporter) Import(records ...spec.Record) (*importerpkg.ImportResp, error) {
+	resp, err := i.Importer.Import(records...)
+	if len(records) > 0 {
+		i.st.imported(&records[0], err != nil)
+	}
+	return resp, err
+}
+
+func (st *sourceTracker) read(n int, records spec.Records, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if err != nil {
+		// the source is finished only when it is read to the end
+		if err == io.EOF {
+			st.eof = true
+			st.advance()
+		}
+		return
+	}
+	b := &trackedBatch{seq: st.seq, nBytes: n, records: records, remaining: st.importers}
+	st.seq++
+	if len(records) == 0 || st.importers == 0 {
+		// nothing is imported for the batch
+		st.complete(b)
+		return
+	}
+	st.pending[&records[0]] = b
+}
+
+func (st *sourceTracker) imported(key *spec.Record, failed bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	b, ok := st.pending[key]
+	if !ok {
+		return
+	}
+	b.failed = b.failed || failed
+	b.remaining--
+	if b.remaining == 0 {
+		delete(st.pending, key)
+		st.complete(b)
+	}
+}
+
+func (st *sourceTracker) complete(b *trackedBatch) {
+	if b.failed {
+		if err := st.writeFailed(b.records); err != nil {
+			logx.Errorf("[task %s] write the failed records error: %s", st.tracker.TaskID, err)
+		}
+	}
+	st.done[b.seq] = b
+	st.advance()
+}
+
+// advance moves the offset over the batches which are done in order
+func (st *sourceTracker) advance() {
+	for {
+		b, ok := st.done[st.next]
+		if !ok {
+			break
+		}
+		delete(st.done, st.next)
+		st.next++
+		st.cp.Offset += int64(b.nBytes)
+		st.cp.Records += int64(len(b.records))
+		if b.failed {
+			st.cp.Failed += int64(len(b.records))
+		}
+	}
+	if st.eof && st.next == st.seq {
+		st.cp.IsFinished = true
+		st.save()
+		return
+	}
+	if time.Since(st.lastSave) >= checkpointSaveInterval {
+		st.save()
+	}
+}
+
+func (st *sourceTracker) save() {
+	st.lastSave = time.Now()
+	if st.failedW != nil {
+		st.failedW.Flush()
+	}
+	if err := GetTaskMgr().db.SaveTaskCheckpoint(st.cp); err != nil {
+		logx.Errorf("[task %s] save the checkpoint of %s error: %s", st.tracker.TaskID, st.cp.Name, err)
+	}
+}
+
+func (st *sourceTracker) writeFailed(records spec.Records) error {
+	if st.failedW == nil {
+		path := FailedRecordsPath(st.tracker.Dir, st.index)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		st.failedF = f
+		st.failedW = csv.NewWriter(f)
+		// keep the delimiter of the source, so that the file can be imported with the same config
+		st.failedW.Comma = st.comma
+	}
+	for _, record := range records {
+		if err := st.failedW.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
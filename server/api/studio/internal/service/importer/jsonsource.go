@@ -0,0 +1,335 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/source"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+)
+
+const (
+	SourceFormatCSV   = "csv"
+	SourceFormatJSON  = "json"
+	SourceFormatJSONL = "jsonl"
+)
+
+type (
+	/*
+		JSONSource is how the records are read from the json source, nebula-importer only reads csv,
+		so the records are converted into csv rows, whose i-th column is selected by the i-th field
+		  - the jsonl file has one record in each line
+		  - the records of the json file are the elements of the array at Root,
+		    without Root they are the elements of the document, or the document itself if it is not an array
+	*/
+	JSONSource struct {
+		Lines  bool
+		Root   []selectorStep
+		Fields [][]selectorStep
+	}
+
+	// selectorStep is one step of the JSONPath-style selector, e.g. `$.user.tags[0]` has 3 steps
+	selectorStep struct {
+		key   string
+		index int
+	}
+
+	// jsonCSVReader converts the json records into csv rows as they are read
+	jsonCSVReader struct {
+		src     *JSONSource
+		br      *bufio.Reader
+		dec     *json.Decoder
+		started bool
+		// inArray is set when the records are the elements of an array
+		inArray bool
+		buf     bytes.Buffer
+		w       *csv.Writer
+		err     error
+	}
+
+	// jsonSource is the source of nebula-importer whose json content is read as csv
+	jsonSource struct {
+		source.Source
+		json *JSONSource
+		r    io.Reader
+	}
+)
+
+// sourceFormat is the format of the source in lower case, the source is csv by default
+func sourceFormat(s *types.Source) string {
+	if s.Format != nil && *s.Format != "" {
+		return strings.ToLower(*s.Format)
+	}
+	return SourceFormatCSV
+}
+
+// NewJSONSource checks the format and the selectors of the source, it returns nil for the source in the other formats
+func NewJSONSource(s *types.Source) (*JSONSource, error) {
+	format := sourceFormat(s)
+	switch format {
+	case SourceFormatCSV, SourceFormatParquet:
+		return nil, nil
+	case SourceFormatJSON, SourceFormatJSONL:
+	default:
+		return nil, fmt.Errorf("unknown source format %s", format)
+	}
+	if s.JSON == nil || len(s.JSON.Fields) == 0 {
+		return nil, errors.New("the fields of the json source are required")
+	}
+	js := &JSONSource{Lines: format == SourceFormatJSONL}
+	var err error
+	if s.JSON.Root != "" {
+		if js.Lines {
+			return nil, errors.New("the root can not be set for the jsonl source")
+		}
+		if js.Root, err = parseSelector(s.JSON.Root); err != nil {
+			return nil, err
+		}
+		for _, step := range js.Root {
+			if step.key == "" {
+				return nil, fmt.Errorf("the root %s can only select the keys of the objects", s.JSON.Root)
+			}
+		}
+	}
+	for _, field := range s.JSON.Fields {
+		steps, err := parseSelector(field)
+		if err != nil {
+			return nil, err
+		}
+		js.Fields = append(js.Fields, steps)
+	}
+	return js, nil
+}
+
+// parseSelector parses the selector like `$.a.b[0]`, `a.b` or `$['a.b']`, `$` selects the record itself
+func parseSelector(selector string) ([]selectorStep, error) {
+	s := strings.TrimSpace(selector)
+	s = strings.TrimPrefix(s, "$")
+	var steps []selectorStep
+	for len(s) > 0 {
+		switch {
+		case s[0] == '.':
+			s = s[1:]
+		case strings.HasPrefix(s, "['"):
+			end := strings.Index(s, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid selector %s", selector)
+			}
+			steps = append(steps, selectorStep{key: s[2:end]})
+			s = s[end+2:]
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid selector %s", selector)
+			}
+			index, err := strconv.Atoi(s[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index in selector %s", selector)
+			}
+			steps = append(steps, selectorStep{index: index})
+			s = s[end+1:]
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			steps = append(steps, selectorStep{key: s[:end]})
+			s = s[end:]
+		}
+	}
+	return steps, nil
+}
+
+// NewCSVReader returns the csv rows converted from the json records in r, the delimiter of the rows is comma
+func (js *JSONSource) NewCSVReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+	// keep the big integers, e.g. the int64 vids
+	dec.UseNumber()
+	jr := &jsonCSVReader{src: js, br: br, dec: dec}
+	jr.w = csv.NewWriter(&jr.buf)
+	return jr
+}
+
+func (r *jsonCSVReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		record, err := r.next()
+		if err != nil {
+			r.err = err
+			continue
+		}
+		if err := r.w.Write(r.src.row(record)); err != nil {
+			return 0, err
+		}
+		r.w.Flush()
+	}
+	return r.buf.Read(p)
+}
+
+// next decodes the next record
+func (r *jsonCSVReader) next() (any, error) {
+	if !r.started {
+		r.started = true
+		if err := r.seekRecords(); err != nil {
+			return nil, err
+		}
+	}
+	if r.inArray && !r.dec.More() {
+		// the rest of the document after the array is not read
+		return nil, io.EOF
+	}
+	var record any
+	if err := r.dec.Decode(&record); err != nil {
+		return nil, err
+	}
+	if !r.src.Lines && !r.inArray {
+		// the document which is not an array is the only record
+		r.err = io.EOF
+	}
+	return record, nil
+}
+
+// seekRecords moves the decoder into the array of the records, so that the records can be decoded one by one
+func (r *jsonCSVReader) seekRecords() error {
+	if r.src.Lines {
+		return nil
+	}
+	if len(r.src.Root) == 0 {
+		// the first byte is peeked before the decoder reads the buffer
+		for {
+			b, err := r.br.Peek(1)
+			if err != nil {
+				return err
+			}
+			if !unicode.IsSpace(rune(b[0])) {
+				break
+			}
+			_, _ = r.br.Discard(1)
+		}
+		b, _ := r.br.Peek(1)
+		if b[0] == '[' {
+			r.inArray = true
+			return r.expectDelim('[')
+		}
+		return nil
+	}
+	for _, step := range r.src.Root {
+		if err := r.expectDelim('{'); err != nil {
+			return err
+		}
+		found := false
+		for r.dec.More() {
+			token, err := r.dec.Token()
+			if err != nil {
+				return err
+			}
+			if key, _ := token.(string); key == step.key {
+				found = true
+				break
+			}
+			var skipped json.RawMessage
+			if err := r.dec.Decode(&skipped); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return fmt.Errorf("the key %s of the root is not found", step.key)
+		}
+	}
+	r.inArray = true
+	return r.expectDelim('[')
+}
+
+func (r *jsonCSVReader) expectDelim(delim json.Delim) error {
+	token, err := r.dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("%v is expected for the root, but %v is met", delim, token)
+	}
+	return nil
+}
+
+// row selects the fields from the record, the missing or null fields are empty
+func (js *JSONSource) row(record any) []string {
+	row := make([]string, len(js.Fields))
+	for i, steps := range js.Fields {
+		row[i] = jsonValueString(selectValue(record, steps))
+	}
+	return row
+}
+
+func selectValue(value any, steps []selectorStep) any {
+	for _, step := range steps {
+		switch v := value.(type) {
+		case map[string]any:
+			if step.key == "" {
+				return nil
+			}
+			value = v[step.key]
+		case []any:
+			if step.key != "" || step.index >= len(v) {
+				return nil
+			}
+			value = v[step.index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func jsonValueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		// the objects and the arrays are kept as json, e.g. for the string props
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+/*
+wrapJSONSource reads the json source as csv, the config of the source is changed to the csv converted,
+the size is still the size of the json file, so the progress of the source is estimated
+*/
+func wrapJSONSource(s source.Source, js *JSONSource) source.Source {
+	return &jsonSource{Source: s, json: js}
+}
+
+func (s *jsonSource) Config() *source.Config {
+	c := s.Source.Config().Clone()
+	c.CSV = &source.CSVConfig{Delimiter: ","}
+	return c
+}
+
+func (s *jsonSource) Open() error {
+	if err := s.Source.Open(); err != nil {
+		return err
+	}
+	s.r = s.json.NewCSVReader(s.Source)
+	return nil
+}
+
+func (s *jsonSource) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
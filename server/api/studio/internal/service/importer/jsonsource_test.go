@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+)
+
+func TestParseSelector(t *testing.T) {
+	testCases := []struct {
+		selector string
+		steps    []selectorStep
+		hasErr   bool
+	}{
+		{selector: "$", steps: nil},
+		{selector: "a", steps: []selectorStep{{key: "a"}}},
+		{selector: "$.a.b", steps: []selectorStep{{key: "a"}, {key: "b"}}},
+		{selector: "$['a.b']", steps: []selectorStep{{key: "a.b"}}},
+		{selector: "$['a.b'].c", steps: []selectorStep{{key: "a.b"}, {key: "c"}}},
+		{selector: "$.tags[0]", steps: []selectorStep{{key: "tags"}, {index: 0}}},
+		{selector: "$[1][2]", steps: []selectorStep{{index: 1}, {index: 2}}},
+		{selector: " $.a ", steps: []selectorStep{{key: "a"}}},
+		{selector: "$['a", hasErr: true},
+		{selector: "$.a[0", hasErr: true},
+		{selector: "$.a[x]", hasErr: true},
+		{selector: "$.a[-1]", hasErr: true},
+	}
+	for _, tc := range testCases {
+		steps, err := parseSelector(tc.selector)
+		if tc.hasErr {
+			assert.NotNil(t, err, tc.selector)
+			continue
+		}
+		assert.Nil(t, err, tc.selector)
+		assert.Equal(t, tc.steps, steps, tc.selector)
+	}
+}
+
+func TestJSONSource_NewCSVReader(t *testing.T) {
+	testCases := []struct {
+		name    string
+		format  string
+		root    string
+		fields  []string
+		content string
+		rows    [][]string
+		hasErr  bool
+	}{
+		{
+			name:    "array",
+			format:  SourceFormatJSON,
+			fields:  []string{"$.id", "$['a.b']", "$.tags[1]", "$.missing"},
+			content: ` [{"id": 1, "a.b": "x", "tags": ["t0", "t1"]}, {"id": 2, "a": {"b": "y"}, "tags": ["t0"]}] `,
+			rows:    [][]string{{"1", "x", "t1", ""}, {"2", "", "", ""}},
+		},
+		{
+			name:    "object",
+			format:  SourceFormatJSON,
+			fields:  []string{"$.id", "$.user.name", "$.user"},
+			content: "\n{\"id\": \"v1\", \"user\": {\"name\": \"Tom, Jr.\"}}",
+			rows:    [][]string{{"v1", "Tom, Jr.", `{"name":"Tom, Jr."}`}},
+		},
+		{
+			name:    "nested root",
+			format:  SourceFormatJSON,
+			root:    "$.data.items",
+			fields:  []string{"$.id"},
+			content: `{"meta": {"items": [0]}, "data": {"total": 2, "items": [{"id": 1}, {"id": 2}]}, "after": 1}`,
+			rows:    [][]string{{"1"}, {"2"}},
+		},
+		{
+			name:    "root not found",
+			format:  SourceFormatJSON,
+			root:    "$.data",
+			fields:  []string{"$.id"},
+			content: `{"items": []}`,
+			hasErr:  true,
+		},
+		{
+			name:    "root not an array",
+			format:  SourceFormatJSON,
+			root:    "$.data",
+			fields:  []string{"$.id"},
+			content: `{"data": {"id": 1}}`,
+			hasErr:  true,
+		},
+		{
+			name:    "jsonl with blank lines",
+			format:  SourceFormatJSONL,
+			fields:  []string{"$.id", "$.ok"},
+			content: "{\"id\": 1, \"ok\": true}\n\n  \n{\"id\": 2, \"ok\": null}\n",
+			rows:    [][]string{{"1", "true"}, {"2", ""}},
+		},
+		{
+			name:    "empty json",
+			format:  SourceFormatJSON,
+			fields:  []string{"$.id"},
+			content: "",
+		},
+		{
+			name:    "blank json",
+			format:  SourceFormatJSON,
+			fields:  []string{"$.id"},
+			content: " \n ",
+		},
+		{
+			name:    "empty jsonl",
+			format:  SourceFormatJSONL,
+			fields:  []string{"$.id"},
+			content: "",
+		},
+		{
+			name:    "empty array",
+			format:  SourceFormatJSON,
+			fields:  []string{"$.id"},
+			content: "[]",
+		},
+		{
+			name:    "int64 precision",
+			format:  SourceFormatJSONL,
+			fields:  []string{"$.id", "$.score"},
+			content: `{"id": 9223372036854775807, "score": 1.5e3}` + "\n" + `{"id": -9007199254740993, "score": 0.1}`,
+			rows:    [][]string{{"9223372036854775807", "1.5e3"}, {"-9007199254740993", "0.1"}},
+		},
+		{
+			name:    "invalid json",
+			format:  SourceFormatJSON,
+			fields:  []string{"$.id"},
+			content: `[{"id": 1}, {"id": `,
+			hasErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			format := tc.format
+			js, err := NewJSONSource(&types.Source{Format: &format, JSON: &types.ImportTaskJSON{Root: tc.root, Fields: tc.fields}})
+			assert.Nil(t, err)
+			content, err := io.ReadAll(js.NewCSVReader(strings.NewReader(tc.content)))
+			if tc.hasErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			rows, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+			assert.Nil(t, err)
+			if len(tc.rows) == 0 {
+				assert.Empty(t, rows)
+				return
+			}
+			assert.Equal(t, tc.rows, rows)
+		})
+	}
+}
+
+func TestNewJSONSource(t *testing.T) {
+	jsonl, csvFormat := SourceFormatJSONL, SourceFormatCSV
+	js, err := NewJSONSource(&types.Source{Format: &csvFormat})
+	assert.Nil(t, err)
+	assert.Nil(t, js)
+	_, err = NewJSONSource(&types.Source{Format: &jsonl})
+	assert.NotNil(t, err)
+	_, err = NewJSONSource(&types.Source{Format: &jsonl, JSON: &types.ImportTaskJSON{Root: "$.a", Fields: []string{"$.id"}}})
+	assert.NotNil(t, err)
+	jsonFormat := SourceFormatJSON
+	_, err = NewJSONSource(&types.Source{Format: &jsonFormat, JSON: &types.ImportTaskJSON{Root: "$.a[0]", Fields: []string{"$.id"}}})
+	assert.NotNil(t, err)
+}
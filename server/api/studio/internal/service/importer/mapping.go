@@ -0,0 +1,250 @@
+package importer
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+)
+
+// mappingNameThreshold is the lowest name similarity for a column to be mapped to a prop or a vid
+const mappingNameThreshold = 0.75
+
+var (
+	srcColumnNames = []string{"src", "srcid", "srcvid", "source", "sourceid", "from", "fromid", "start", "startid"}
+	dstColumnNames = []string{"dst", "dstid", "dstvid", "dest", "destid", "target", "targetid", "to", "toid", "end", "endid"}
+)
+
+// InferColumnType infers the value type of the column from the sampled values, the empty values are ignored
+func InferColumnType(values []string) string {
+	candidates := []string{"INT", "DOUBLE", "BOOL", "DATE", "TIME", "DATETIME"}
+	seen := false
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		seen = true
+		kept := candidates[:0]
+		for _, typ := range candidates {
+			if checkValue(typ)(value) == nil {
+				kept = append(kept, typ)
+			}
+		}
+		candidates = kept
+		if len(candidates) == 0 {
+			break
+		}
+	}
+	if !seen || len(candidates) == 0 {
+		return "STRING"
+	}
+	// the candidates are in the order of preference, e.g. 1 is taken as int rather than double or bool
+	return candidates[0]
+}
+
+/*
+SuggestMappings maps the columns of the file to the tags or the edges of the space:
+  - if the columns of the source and the destination are found, the file is taken as the file of one edge type
+  - otherwise the file is mapped to the tags whose vid column is found
+  - the props are mapped to the columns with the similar names and the compatible types
+*/
+func SuggestMappings(schema *SpaceSchema, columns []types.ImportColumnSuggestion, fileName string) ([]types.Tag, []types.Edge) {
+	base := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	vidType := "STRING"
+	if schema.IsIntVid() {
+		vidType = "INT"
+	}
+
+	src := matchColumn(columns, srcColumnNames, vidType)
+	dst := matchColumn(columns, dstColumnNames, vidType)
+	if src != nil && dst != nil {
+		type scoredEdge struct {
+			edge  types.Edge
+			score float64
+		}
+		var best *scoredEdge
+		for i := range schema.Edges {
+			item := &schema.Edges[i]
+			props := matchProps(columns, item)
+			score := float64(len(props))
+			if s := nameSimilarity(item.Name, base); s >= mappingNameThreshold {
+				score += 2 * s
+			}
+			if best != nil && score <= best.score {
+				continue
+			}
+			edge := types.Edge{
+				Name:  item.Name,
+				Src:   types.EdgeNodeRef{ID: types.NodeId{Name: src.Name, Type: vidType, Index: src.Index}},
+				Dst:   types.EdgeNodeRef{ID: types.NodeId{Name: dst.Name, Type: vidType, Index: dst.Index}},
+				Props: props,
+			}
+			if rank := matchColumn(columns, []string{"rank"}, "INT"); rank != nil {
+				index := rank.Index
+				edge.Rank = &types.EdgeRank{Index: &index}
+			}
+			best = &scoredEdge{edge: edge, score: score}
+		}
+		// a file without the similar name or any prop is only mapped to the only edge type
+		if best != nil && (best.score > 0 || len(schema.Edges) == 1) {
+			return nil, []types.Edge{best.edge}
+		}
+		return nil, nil
+	}
+
+	var tags []types.Tag
+	for i := range schema.Tags {
+		item := &schema.Tags[i]
+		names := []string{"vid", "id", item.Name + "id", item.Name + "vid", item.Name}
+		vid := matchColumn(columns, names, vidType)
+		if vid == nil {
+			continue
+		}
+		props := matchProps(columns, item)
+		if len(props) == 0 && nameSimilarity(item.Name, base) < mappingNameThreshold {
+			continue
+		}
+		tags = append(tags, types.Tag{
+			Name:  item.Name,
+			ID:    types.NodeId{Name: vid.Name, Type: vidType, Index: vid.Index},
+			Props: props,
+		})
+	}
+	return tags, nil
+}
+
+// matchProps maps the props of the tag or the edge to the columns, the props not found are left out
+func matchProps(columns []types.ImportColumnSuggestion, item *SchemaItem) []types.Prop {
+	props := []types.Prop{}
+	for _, p := range item.Props {
+		typ := p.ImporterValueType()
+		column := matchColumn(columns, []string{p.Name}, typ)
+		if column == nil {
+			continue
+		}
+		prop := types.Prop{
+			Name:  p.Name,
+			Type:  typ,
+			Index: column.Index,
+		}
+		if p.Nullable {
+			// the empty values are taken as null
+			prop.Nullable = true
+		}
+		props = append(props, prop)
+	}
+	return props
+}
+
+// matchColumn finds the column whose name is the most similar to one of the names, and whose type is compatible
+func matchColumn(columns []types.ImportColumnSuggestion, names []string, typ string) *types.ImportColumnSuggestion {
+	type candidate struct {
+		column *types.ImportColumnSuggestion
+		score  float64
+	}
+	var candidates []candidate
+	for i := range columns {
+		column := &columns[i]
+		if !compatibleColumnType(column.Type, typ) {
+			continue
+		}
+		score := 0.0
+		for _, name := range names {
+			if s := nameSimilarity(column.Name, name); s > score {
+				score = s
+			}
+		}
+		if score >= mappingNameThreshold {
+			candidates = append(candidates, candidate{column: column, score: score})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	return candidates[0].column
+}
+
+// compatibleColumnType checks whether the values of the column type can be imported as the prop type
+func compatibleColumnType(columnType, propType string) bool {
+	switch propType {
+	case columnType:
+		return true
+	case "STRING":
+		return true
+	case "FLOAT", "DOUBLE":
+		return columnType == "INT" || columnType == "DOUBLE"
+	case "TIMESTAMP":
+		return columnType == "INT" || columnType == "DATETIME"
+	case "INT", "BOOL", "DATE", "TIME", "DATETIME":
+		return false
+	}
+	// e.g. geography and duration are written as strings
+	return columnType == "STRING"
+}
+
+// nameSimilarity is 1 for the same names ignoring the case and the separators, and decreases with the edit distance
+func nameSimilarity(a, b string) float64 {
+	a, b = normalizeName(a), normalizeName(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	shorter, longer := a, b
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if len(shorter) >= 3 && strings.Contains(longer, shorter) {
+		return 0.8
+	}
+	return 1 - float64(editDistance(a, b))/float64(len(longer))
+}
+
+func normalizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// ColumnName is the name of the column in the file without a header
+func ColumnName(index int) string {
+	return "column" + strconv.Itoa(index)
+}
@@ -0,0 +1,320 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxTaskSamples is the count of the samples kept for a finished task, the samples in between are dropped evenly
+const maxTaskSamples = 720
+
+func newTaskSample(taskID string, stats db.Stats, now time.Time) *db.TaskSample {
+	return &db.TaskSample{
+		TaskID:         taskID,
+		Time:           now.UnixMilli(),
+		ProcessedBytes: stats.ProcessedBytes,
+		TotalRecords:   stats.TotalRecords,
+		FailedRecords:  stats.FailedRecords,
+		TotalRequest:   stats.TotalRequest,
+		FailedRequest:  stats.FailedRequest,
+		TotalLatency:   stats.TotalLatency.Microseconds(),
+	}
+}
+
+/*
+sampleTask appends the current stats of the task to its time series,
+the first sample of a run which continues another task starts from the stats of that task
+*/
+func (mgr *TaskMgr) sampleTask(task *Task, first bool) {
+	stats := task.TaskInfo.Stats
+	if first && task.Client != nil && task.Client.Manager == nil && task.Client.Tracker != nil {
+		stats = task.Client.Tracker.MergeStats(db.Stats{})
+	}
+	if err := mgr.db.InsertTaskSample(newTaskSample(task.TaskInfo.BID, stats, time.Now())); err != nil {
+		logx.Errorf("[task %s] save the stats sample error: %s", task.TaskInfo.BID, err)
+	}
+}
+
+// reportTask compacts the time series of the finished task and saves its performance report
+func (mgr *TaskMgr) reportTask(info *db.TaskInfo) {
+	mgr.sampleTask(&Task{TaskInfo: info}, false)
+	if err := mgr.db.CompactTaskSamples(info.BID, maxTaskSamples); err != nil {
+		logx.Errorf("[task %s] compact the stats samples error: %s", info.BID, err)
+	}
+	samples, err := mgr.db.FindTaskSamples(info.BID)
+	if err != nil {
+		logx.Errorf("[task %s] find the stats samples error: %s", info.BID, err)
+		return
+	}
+	_, perf := TaskMetrics(samples)
+	sum := sha256.Sum256([]byte(info.RawConfig))
+	report := &db.TaskReport{
+		TaskID:            info.BID,
+		Address:           info.Address,
+		User:              info.User,
+		ConfigHash:        hex.EncodeToString(sum[:]),
+		TaskStatus:        info.TaskStatus,
+		StartTime:         perf.StartTime,
+		Duration:          perf.Duration,
+		Records:           perf.Records,
+		Bytes:             perf.Bytes,
+		FailedRecords:     perf.FailedRecords,
+		RowsPerSecond:     perf.RowsPerSecond,
+		BytesPerSecond:    perf.BytesPerSecond,
+		PeakRowsPerSecond: perf.PeakRowsPerSecond,
+		LatencyP50:        perf.LatencyP50,
+		LatencyP90:        perf.LatencyP90,
+		LatencyP99:        perf.LatencyP99,
+		FailureRate:       perf.FailureRate,
+	}
+	if err := mgr.db.SaveTaskReport(report); err != nil {
+		logx.Errorf("[task %s] save the performance report error: %s", info.BID, err)
+	}
+}
+
+// GetTaskMetrics returns the throughput time series of the task, which is still growing while the task is running
+func GetTaskMetrics(taskInfo *db.TaskInfo) (*types.GetImportTaskMetricsData, error) {
+	samples, err := taskmgr.db.FindTaskSamples(taskInfo.BID)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	points, perf := TaskMetrics(samples)
+	perf.TaskId = taskInfo.BID
+	perf.Status = taskInfo.TaskStatus
+	return &types.GetImportTaskMetricsData{Points: points, Summary: perf}, nil
+}
+
+// GetTaskReport returns the performance report of the finished task, with the reports of at most limit previous runs of the same config
+func GetTaskReport(taskInfo *db.TaskInfo, limit int) (*types.GetImportTaskReportData, error) {
+	report, err := taskmgr.db.FindTaskReport(taskInfo.BID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the report is generated after the task finishes"))
+		}
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	previous, err := taskmgr.db.FindPreviousTaskReports(report, limit)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	data := &types.GetImportTaskReportData{
+		Report:   toTaskPerformance(report),
+		Previous: []types.ImportTaskPerformance{},
+	}
+	for _, r := range previous {
+		data.Previous = append(data.Previous, toTaskPerformance(r))
+	}
+	if len(previous) > 0 {
+		data.Change = compareTaskPerformance(data.Report, data.Previous)
+	}
+	return data, nil
+}
+
+/*
+TaskMetrics computes the rates between the adjacent samples, and the summary of the run from the first sample to the last one.
+
+The stats only keep the sum of the latency, so the percentiles are of the average latency in each interval,
+weighted by the requests sent in the interval.
+*/
+func TaskMetrics(samples []*db.TaskSample) ([]types.ImportTaskMetricPoint, types.ImportTaskPerformance) {
+	points := []types.ImportTaskMetricPoint{}
+	perf := types.ImportTaskPerformance{}
+	if len(samples) == 0 {
+		return points, perf
+	}
+	type latency struct {
+		value    int64
+		requests int64
+	}
+	var latencies []latency
+	first, last := samples[0], samples[len(samples)-1]
+	for i := 1; i < len(samples); i++ {
+		prev, cur := samples[i-1], samples[i]
+		seconds := float64(cur.Time-prev.Time) / 1000
+		if seconds <= 0 {
+			continue
+		}
+		records := cur.TotalRecords - prev.TotalRecords
+		requests := cur.TotalRequest - prev.TotalRequest
+		point := types.ImportTaskMetricPoint{
+			Time:           cur.Time,
+			RowsPerSecond:  float64(records) / seconds,
+			BytesPerSecond: float64(cur.ProcessedBytes-prev.ProcessedBytes) / seconds,
+		}
+		if records > 0 {
+			point.FailureRate = float64(cur.FailedRecords-prev.FailedRecords) / float64(records)
+		}
+		if requests > 0 {
+			point.Latency = (cur.TotalLatency - prev.TotalLatency) / requests
+			latencies = append(latencies, latency{value: point.Latency, requests: requests})
+		}
+		if point.RowsPerSecond > perf.PeakRowsPerSecond {
+			perf.PeakRowsPerSecond = point.RowsPerSecond
+		}
+		points = append(points, point)
+	}
+
+	perf.StartTime = first.Time
+	perf.Duration = last.Time - first.Time
+	perf.Records = last.TotalRecords - first.TotalRecords
+	perf.Bytes = last.ProcessedBytes - first.ProcessedBytes
+	perf.FailedRecords = last.FailedRecords - first.FailedRecords
+	if perf.Duration > 0 {
+		perf.RowsPerSecond = float64(perf.Records) * 1000 / float64(perf.Duration)
+		perf.BytesPerSecond = float64(perf.Bytes) * 1000 / float64(perf.Duration)
+	}
+	if perf.Records > 0 {
+		perf.FailureRate = float64(perf.FailedRecords) / float64(perf.Records)
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i].value < latencies[j].value })
+	var total int64
+	for _, l := range latencies {
+		total += l.requests
+	}
+	percentile := func(p float64) int64 {
+		var acc int64
+		for _, l := range latencies {
+			acc += l.requests
+			if float64(acc) >= p*float64(total) {
+				return l.value
+			}
+		}
+		return 0
+	}
+	perf.LatencyP50 = percentile(0.5)
+	perf.LatencyP90 = percentile(0.9)
+	perf.LatencyP99 = percentile(0.99)
+	return points, perf
+}
+
+func toTaskPerformance(r *db.TaskReport) types.ImportTaskPerformance {
+	return types.ImportTaskPerformance{
+		TaskId:            r.TaskID,
+		Status:            r.TaskStatus,
+		StartTime:         r.StartTime,
+		Duration:          r.Duration,
+		Records:           r.Records,
+		Bytes:             r.Bytes,
+		FailedRecords:     r.FailedRecords,
+		RowsPerSecond:     r.RowsPerSecond,
+		BytesPerSecond:    r.BytesPerSecond,
+		PeakRowsPerSecond: r.PeakRowsPerSecond,
+		LatencyP50:        r.LatencyP50,
+		LatencyP90:        r.LatencyP90,
+		LatencyP99:        r.LatencyP99,
+		FailureRate:       r.FailureRate,
+	}
+}
+
+// compareTaskPerformance compares the run with the average of the previous runs
+func compareTaskPerformance(cur types.ImportTaskPerformance, previous []types.ImportTaskPerformance) *types.ImportTaskPerformanceChange {
+	var avg types.ImportTaskPerformanceChange
+	for _, p := range previous {
+		avg.Duration += float64(p.Duration)
+		avg.RowsPerSecond += p.RowsPerSecond
+		avg.BytesPerSecond += p.BytesPerSecond
+		avg.LatencyP50 += float64(p.LatencyP50)
+		avg.LatencyP99 += float64(p.LatencyP99)
+		avg.FailureRate += p.FailureRate
+	}
+	n := float64(len(previous))
+	change := func(cur, sum float64) float64 {
+		if sum == 0 {
+			return 0
+		}
+		return (cur - sum/n) / (sum / n) * 100
+	}
+	return &types.ImportTaskPerformanceChange{
+		Duration:       change(float64(cur.Duration), avg.Duration),
+		RowsPerSecond:  change(cur.RowsPerSecond, avg.RowsPerSecond),
+		BytesPerSecond: change(cur.BytesPerSecond, avg.BytesPerSecond),
+		LatencyP50:     change(float64(cur.LatencyP50), avg.LatencyP50),
+		LatencyP99:     change(float64(cur.LatencyP99), avg.LatencyP99),
+		FailureRate:    cur.FailureRate - avg.FailureRate/n,
+	}
+}
+
+func (t *TaskDb) InsertTaskSample(sample *db.TaskSample) error {
+	return t.Create(sample).Error
+}
+
+func (t *TaskDb) FindTaskSamples(taskID string) ([]*db.TaskSample, error) {
+	samples := make([]*db.TaskSample, 0)
+	if err := t.Where("task_id = ?", taskID).Order("sample_time, id").Find(&samples).Error; err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// CompactTaskSamples keeps at most max samples of the task, the first and the last ones are always kept, the rates of the kept samples are still right as the stats are cumulative
+func (t *TaskDb) CompactTaskSamples(taskID string, max int) error {
+	var ids []int
+	if err := t.Model(&db.TaskSample{}).Where("task_id = ?", taskID).Order("sample_time, id").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) <= max {
+		return nil
+	}
+	step := (len(ids) + max - 2) / (max - 1)
+	var dropped []int
+	for i, id := range ids[:len(ids)-1] {
+		if i%step != 0 {
+			dropped = append(dropped, id)
+		}
+	}
+	for len(dropped) > 0 {
+		n := len(dropped)
+		if n > 500 {
+			n = 500
+		}
+		if err := t.Delete(&db.TaskSample{}, "id IN ?", dropped[:n]).Error; err != nil {
+			return err
+		}
+		dropped = dropped[n:]
+	}
+	return nil
+}
+
+// SaveTaskReport inserts the report or overwrites the one of the same task
+func (t *TaskDb) SaveTaskReport(report *db.TaskReport) error {
+	return t.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}},
+		UpdateAll: true,
+	}).Create(report).Error
+}
+
+func (t *TaskDb) FindTaskReport(taskID string) (*db.TaskReport, error) {
+	report := new(db.TaskReport)
+	if err := t.Where("task_id = ?", taskID).First(report).Error; err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// FindPreviousTaskReports lists the reports of the runs of the same config by the same user before the report, the latest first
+func (t *TaskDb) FindPreviousTaskReports(report *db.TaskReport, limit int) ([]*db.TaskReport, error) {
+	reports := make([]*db.TaskReport, 0)
+	if err := t.Where("address = ? AND user = ? AND config_hash = ? AND id < ?", report.Address, report.User, report.ConfigHash, report.ID).
+		Order("id desc").Limit(limit).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (t *TaskDb) DelTaskMetrics(taskID string) error {
+	if err := t.Delete(&db.TaskSample{}, "task_id = ?", taskID).Error; err != nil {
+		return err
+	}
+	return t.Delete(&db.TaskReport{}, "task_id = ?", taskID).Error
+}
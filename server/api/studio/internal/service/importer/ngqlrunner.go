@@ -0,0 +1,310 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	nebula_go "github.com/vesoft-inc/nebula-go/v3"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/client"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ngql"
+)
+
+const (
+	ngqlDefaultBatch    = 100
+	ngqlScriptName      = "script.ngql"
+	ngqlCheckpointName  = "script"
+	ngqlErrDir          = "err"
+	NGQLFailedStmtsName = "failed.ngql"
+	ngqlErrorLogName    = "error.log"
+)
+
+var useSpaceRegex = regexp.MustCompile("(?is)^USE\\s+(`(?:[^`\\\\]|\\\\.)*`|\\S+)$")
+
+// executeGQLs runs the statements of the script, which is replaced in the tests
+var executeGQLs = client.Execute
+
+type (
+	// NGQLConfig is stored as the raw config of the ngql import task
+	NGQLConfig struct {
+		Space string `json:"space"`
+		// FilePath is the path in the upload dir, or the path in the datasource if DatasourceId is set
+		FilePath     string `json:"filePath"`
+		DatasourceId string `json:"datasourceId,omitempty"`
+		Batch        int    `json:"batch"`
+		// FailedTaskId is only set by the server when the failed statements of the task are reimported, the script is in its err dir
+		FailedTaskId string `json:"failedTaskId,omitempty"`
+	}
+
+	/*
+		NGQLRunner executes the statements of an nGQL script in batches,
+		  - the statements failed are kept in the err dir of the task with their errors
+		  - the offset of the script is saved after each batch, so that the task can be resumed
+	*/
+	NGQLRunner struct {
+		runnerBase
+		Dir string
+		Cfg *NGQLConfig
+		// ScriptPath is the local script to run, the script in the Store is downloaded into it first
+		ScriptPath string
+		Store      filestore.FileStore
+
+		auth  *auth.AuthData
+		nsid  string
+		space string
+	}
+)
+
+func NewNGQLRunner(taskID, dir, scriptPath string, cfg *NGQLConfig, authData *auth.AuthData, store filestore.FileStore) *NGQLRunner {
+	if cfg.Batch <= 0 {
+		cfg.Batch = ngqlDefaultBatch
+	}
+	if store != nil {
+		scriptPath = filepath.Join(dir, ngqlScriptName)
+	}
+	return &NGQLRunner{
+		runnerBase: newRunnerBase(taskID),
+		Dir:        dir,
+		Cfg:        cfg,
+		ScriptPath: scriptPath,
+		Store:      store,
+		auth:       authData,
+		space:      cfg.Space,
+	}
+}
+
+// Discard releases the runner which is cancelled before it runs
+func (n *NGQLRunner) Discard() {
+	if n.Store != nil {
+		n.Store.Close()
+	}
+}
+
+func (n *NGQLRunner) Run() (err error) {
+	defer close(n.done)
+	if err = n.openLog(filepath.Join(n.Dir, TaskLogName(db.TaskTypeNGQL))); err != nil {
+		return err
+	}
+	defer n.closeLog()
+	defer func() {
+		if err != nil && err != errRunnerStopped {
+			n.log("error", "run script failed: %s", err)
+		}
+	}()
+
+	if n.Store != nil {
+		err = n.download()
+		n.Store.Close()
+		if err != nil {
+			return fmt.Errorf("download script failed: %w", err)
+		}
+	}
+
+	cp := &db.TaskCheckpoint{TaskID: n.TaskID, Name: ngqlCheckpointName}
+	saved, err := GetTaskMgr().db.FindTaskCheckpoints(n.TaskID)
+	if err != nil {
+		return err
+	}
+	for _, c := range saved {
+		if c.Name == ngqlCheckpointName {
+			cp = c
+		}
+	}
+
+	clientInfo, err := client.NewClient(n.auth.Address, n.auth.Port, n.auth.Username, n.auth.Password, nebula_go.GetDefaultConf())
+	if err != nil {
+		return fmt.Errorf("connect to nebula failed: %w", err)
+	}
+	n.nsid = clientInfo.ClientID
+	defer client.CloseClient(n.nsid)
+	return n.runScript(cp)
+}
+
+// runScript runs the statements of the script after the checkpoint, the space switched by USE is restored from its cursor
+func (n *NGQLRunner) runScript(cp *db.TaskCheckpoint) (err error) {
+	if cp.Cursor != "" {
+		n.space = cp.Cursor
+	}
+	f, err := os.Open(n.ScriptPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err = f.Seek(cp.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	n.updateStats(func(stats *db.Stats) {
+		stats.TotalBytes = info.Size()
+		stats.ProcessedBytes = cp.Offset
+		stats.TotalRecords = cp.Records
+		stats.TotalProcessed = cp.Records
+	})
+
+	if cp.Offset > 0 {
+		n.log("info", "resume the script from offset %d, %d statements have been executed", cp.Offset, cp.Records)
+	} else {
+		n.log("info", "start running the script, size: %d, batch: %d", info.Size(), n.Cfg.Batch)
+	}
+
+	scanner := ngql.NewScanner(f)
+	base := cp.Offset
+	batch := make([]string, 0, n.Cfg.Batch)
+	var end int64
+	// flush runs the batch in the current space, and saves the checkpoint with the space of the statements after it
+	flush := func(next string) error {
+		if len(batch) > 0 {
+			if err := n.execute(batch, cp.Offset); err != nil {
+				return err
+			}
+		}
+		n.space = next
+		cp.Records += int64(len(batch))
+		cp.Offset = base + end
+		cp.Cursor = n.space
+		batch = batch[:0]
+		n.updateStats(func(stats *db.Stats) {
+			stats.ProcessedBytes = cp.Offset
+		})
+		return GetTaskMgr().db.SaveTaskCheckpoint(cp)
+	}
+	for scanner.Scan() {
+		stmt := scanner.Text()
+		end = scanner.Offset()
+		if matches := useSpaceRegex.FindStringSubmatch(stmt); matches != nil {
+			// the space is switched for the statements after it, so run the ones before it first
+			if err = flush(strings.Trim(matches[1], "`")); err != nil {
+				return err
+			}
+			continue
+		}
+		batch = append(batch, stmt)
+		if len(batch) >= n.Cfg.Batch {
+			if err = flush(n.space); err != nil {
+				return err
+			}
+			if err = n.checkStopped(); err != nil {
+				n.log("info", "the task is stopped at offset %d", cp.Offset)
+				return err
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	end = info.Size() - base
+	if err = flush(n.space); err != nil {
+		return err
+	}
+	cp.IsFinished = true
+	if err = GetTaskMgr().db.SaveTaskCheckpoint(cp); err != nil {
+		return err
+	}
+	stats := n.Stats()
+	n.log("info", "the script is finished, statements: %d, failed: %d", stats.TotalRecords, stats.FailedRecords)
+	return nil
+}
+
+// execute runs the statements in one request, the failed ones are written into the err dir,
+// offset is where the statements start in the script
+func (n *NGQLRunner) execute(stmts []string, offset int64) error {
+	start := time.Now()
+	results, err := executeGQLs(n.nsid, n.space, stmts)
+	n.updateStats(func(stats *db.Stats) {
+		stats.TotalRequest++
+		stats.TotalRespTime += time.Since(start)
+	})
+	if err != nil {
+		// the connection is broken, stop here so that the batch is run again when resumed
+		n.updateStats(func(stats *db.Stats) {
+			stats.FailedRequest++
+		})
+		return err
+	}
+
+	var failed []string
+	var errLines []string
+	var latency time.Duration
+	for i, result := range results {
+		latency += time.Duration(result.Result.TimeCost) * time.Microsecond
+		if result.Error != nil {
+			failed = append(failed, stmts[i])
+			errLines = append(errLines, fmt.Sprintf("%s [batch at offset %d] %s: %s", time.Now().Format("2006-01-02 15:04:05"), offset, result.Error, abbreviate(stmts[i], 200)))
+		}
+	}
+	n.updateStats(func(stats *db.Stats) {
+		stats.TotalLatency += latency
+		stats.TotalRecords += int64(len(stmts))
+		stats.TotalProcessed += int64(len(stmts))
+		stats.FailedRecords += int64(len(failed))
+		stats.FailedProcessed += int64(len(failed))
+		if len(failed) > 0 {
+			stats.FailedRequest++
+		}
+	})
+	if len(failed) == 0 {
+		return nil
+	}
+	n.log("warn", "%d of %d statements failed in the batch at offset %d", len(failed), len(stmts), offset)
+	if err := n.appendErrFile(NGQLFailedStmtsName, strings.Join(failed, ";\n")+";\n"); err != nil {
+		return err
+	}
+	return n.appendErrFile(ngqlErrorLogName, strings.Join(errLines, "\n")+"\n")
+}
+
+func (n *NGQLRunner) appendErrFile(name, content string) error {
+	errDir := filepath.Join(n.Dir, ngqlErrDir)
+	if err := os.MkdirAll(errDir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(errDir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(content)
+	return err
+}
+
+// download copies the script from the datasource into the task dir if it has not been done
+func (n *NGQLRunner) download() error {
+	if _, err := os.Stat(n.ScriptPath); err == nil {
+		return nil
+	}
+	r, err := n.Store.Open(n.Cfg.FilePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	tmpPath := n.ScriptPath + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	n.log("info", "the script %s is downloaded", n.Cfg.FilePath)
+	return os.Rename(tmpPath, n.ScriptPath)
+}
+
+func abbreviate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/client"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func useTestTaskDb(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tasks.db")), &gorm.Config{})
+	assert.Nil(t, err)
+	assert.Nil(t, gdb.AutoMigrate(&db.TaskCheckpoint{}))
+	origin := GetTaskMgr().db
+	GetTaskMgr().db = &TaskDb{DB: gdb}
+	t.Cleanup(func() { GetTaskMgr().db = origin })
+}
+
+func TestNGQLRunner_ResumeAfterUse(t *testing.T) {
+	useTestTaskDb(t)
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.ngql")
+	assert.Nil(t, os.WriteFile(script, []byte("INSERT a;\nUSE `s2`;\nINSERT b;\nINSERT c;\n"), 0o644))
+
+	type executed struct {
+		space string
+		stmts []string
+	}
+	var calls []executed
+	broken := true
+	origin := executeGQLs
+	executeGQLs = func(nsid, space string, gqls []string) ([]client.ExecuteResult, error) {
+		if broken && space == "s2" {
+			return nil, errors.New("connection broken")
+		}
+		calls = append(calls, executed{space: space, stmts: append([]string(nil), gqls...)})
+		return make([]client.ExecuteResult, len(gqls)), nil
+	}
+	t.Cleanup(func() { executeGQLs = origin })
+
+	newRunner := func() *NGQLRunner {
+		return NewNGQLRunner("1", dir, script, &NGQLConfig{Space: "s1", Batch: 10}, nil, nil)
+	}
+	cp := &db.TaskCheckpoint{TaskID: "1", Name: ngqlCheckpointName}
+	assert.NotNil(t, newRunner().runScript(cp))
+	assert.Equal(t, []executed{{space: "s1", stmts: []string{"INSERT a"}}}, calls)
+
+	// the task is resumed from the checkpoint saved by USE, which runs the statements after it in the new space
+	saved, err := GetTaskMgr().db.FindTaskCheckpoints("1")
+	assert.Nil(t, err)
+	assert.Len(t, saved, 1)
+	assert.Equal(t, "s2", saved[0].Cursor)
+	broken = false
+	calls = nil
+	assert.Nil(t, newRunner().runScript(saved[0]))
+	assert.Equal(t, []executed{{space: "s2", stmts: []string{"INSERT b", "INSERT c"}}}, calls)
+	assert.True(t, saved[0].IsFinished)
+	assert.Equal(t, int64(3), saved[0].Records)
+}
@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/source"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+	pqsource "github.com/xitongsys/parquet-go/source"
+)
+
+const SourceFormatParquet = "parquet"
+
+// parquetBatchRows is the count of the rows read from the parquet file at a time
+const parquetBatchRows = 256
+
+type (
+	/*
+		ParquetSource is how the records are read from the parquet source, the rows are converted into csv rows,
+		whose i-th column is the column named by the i-th of Columns, so the mappings do not depend on the order of the columns in the file
+	*/
+	ParquetSource struct {
+		Columns []string
+	}
+
+	// parquetCSVReader converts the parquet rows into csv rows as they are read
+	parquetCSVReader struct {
+		r       *filestore.ParquetReader
+		indices []int
+		store   io.Closer
+		buf     bytes.Buffer
+		w       *csv.Writer
+		err     error
+	}
+
+	// parquetSource is the source of nebula-importer whose parquet file is read randomly as csv
+	parquetSource struct {
+		source.Source
+		parquet *ParquetSource
+		r       *parquetCSVReader
+	}
+)
+
+// NewParquetSource checks the columns of the parquet source, it returns nil for the source in the other formats
+func NewParquetSource(s *types.Source) (*ParquetSource, error) {
+	if sourceFormat(s) != SourceFormatParquet {
+		return nil, nil
+	}
+	if s.Parquet == nil || len(s.Parquet.Columns) == 0 {
+		return nil, errors.New("the columns of the parquet source are required")
+	}
+	return &ParquetSource{Columns: s.Parquet.Columns}, nil
+}
+
+/*
+NewCSVReader returns the csv rows converted from the rows of the parquet file, the delimiter of the rows is comma,
+the store is closed with the reader if it is not nil
+*/
+func (ps *ParquetSource) NewCSVReader(pf pqsource.ParquetFile, store io.Closer) (io.ReadCloser, error) {
+	r, err := filestore.NewParquetReader(pf)
+	if err != nil {
+		if store != nil {
+			store.Close()
+		}
+		return nil, err
+	}
+	pr := &parquetCSVReader{r: r, store: store}
+	pr.w = csv.NewWriter(&pr.buf)
+	for _, name := range ps.Columns {
+		index := -1
+		for i, column := range r.Columns {
+			if column.Name == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			pr.Close()
+			return nil, fmt.Errorf("the column %s is not found in the parquet file", name)
+		}
+		pr.indices = append(pr.indices, index)
+	}
+	return pr, nil
+}
+
+func (r *parquetCSVReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		rows, err := r.r.Read(parquetBatchRows)
+		if err == nil && len(rows) == 0 {
+			// no row is read while the rows remain, the file is truncated
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			r.err = err
+			continue
+		}
+		row := make([]string, len(r.indices))
+		for _, values := range rows {
+			for i, index := range r.indices {
+				row[i] = values[index]
+			}
+			if err := r.w.Write(row); err != nil {
+				return 0, err
+			}
+		}
+		r.w.Flush()
+	}
+	return r.buf.Read(p)
+}
+
+func (r *parquetCSVReader) Close() error {
+	err := r.r.Close()
+	if r.store != nil {
+		r.store.Close()
+	}
+	return err
+}
+
+// openParquetFile opens the parquet file of the source, the store of the remote file should be closed after the file is read
+func openParquetFile(c *source.Config) (pqsource.ParquetFile, io.Closer, error) {
+	var (
+		store filestore.FileStore
+		path  string
+		err   error
+	)
+	switch {
+	case c.S3 != nil:
+		platform := "aws"
+		if c.S3.Endpoint != "" {
+			platform = "customize"
+		}
+		store, err = filestore.NewS3Store(platform, c.S3.Endpoint, c.S3.Region, c.S3.Bucket, c.S3.AccessKeyID, c.S3.AccessKeySecret)
+		path = c.S3.Key
+	case c.OSS != nil:
+		store, err = filestore.NewS3Store("oss", c.OSS.Endpoint, "", c.OSS.Bucket, c.OSS.AccessKeyID, c.OSS.AccessKeySecret)
+		path = c.OSS.Key
+	case c.SFTP != nil:
+		store, err = OpenSFTPStore(&types.SFTPConfig{
+			Host:       c.SFTP.Host,
+			Port:       c.SFTP.Port,
+			User:       c.SFTP.User,
+			Password:   c.SFTP.Password,
+			KeyFile:    c.SFTP.KeyFile,
+			KeyData:    c.SFTP.KeyData,
+			Passphrase: c.SFTP.Passphrase,
+		})
+		path = c.SFTP.Path
+	case c.Local != nil:
+		pf, err := filestore.OpenLocalParquetFile(c.Local.Path)
+		return pf, nil, err
+	default:
+		return nil, nil, errors.New("the parquet file can only be read from the local file, s3, oss or sftp")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	opener, ok := store.(filestore.ParquetOpener)
+	if !ok {
+		store.Close()
+		return nil, nil, errors.New("the parquet file can not be read from the store")
+	}
+	pf, err := opener.OpenParquetFile(path)
+	if err != nil {
+		store.Close()
+		return nil, nil, err
+	}
+	return pf, store, nil
+}
+
+/*
+wrapParquetSource reads the parquet source as csv, the file is opened by the source itself for the random access,
+the size is estimated by the uncompressed size of the rows after the source is opened
+*/
+func wrapParquetSource(s source.Source, ps *ParquetSource) source.Source {
+	return &parquetSource{Source: s, parquet: ps}
+}
+
+func (s *parquetSource) Config() *source.Config {
+	c := s.Source.Config().Clone()
+	c.CSV = &source.CSVConfig{Delimiter: ","}
+	return c
+}
+
+func (s *parquetSource) Open() error {
+	var (
+		pf    pqsource.ParquetFile
+		store io.Closer
+		err   error
+	)
+	if ss, ok := s.Source.(*sftpSource); ok {
+		// the host of the sftp source is verified
+		pf, store, err = ss.openParquetFile()
+	} else {
+		pf, store, err = openParquetFile(s.Source.Config())
+	}
+	if err != nil {
+		return err
+	}
+	r, err := s.parquet.NewCSVReader(pf, store)
+	if err != nil {
+		return err
+	}
+	s.r = r.(*parquetCSVReader)
+	return nil
+}
+
+func (s *parquetSource) Size() (int64, error) {
+	if s.r == nil {
+		return s.Source.Size()
+	}
+	return s.r.r.EstimatedSize(), nil
+}
+
+func (s *parquetSource) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+func (s *parquetSource) Close() error {
+	if s.r == nil {
+		return nil
+	}
+	return s.r.Close()
+}
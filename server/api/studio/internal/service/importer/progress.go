@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"sync"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TaskEvent is pushed to the subscribers of the task when its stats are refreshed, Ended is set when the task stops running
+type TaskEvent struct {
+	Task  *types.GetImportTaskData
+	Ended bool
+}
+
+type taskSubscribers struct {
+	mu   sync.Mutex
+	seq  int
+	subs map[string]map[int]chan *TaskEvent
+}
+
+var subscribers = &taskSubscribers{subs: make(map[string]map[int]chan *TaskEvent)}
+
+/*
+SubscribeTask follows the progress of the task, the returned func cancels the subscription,
+only the latest event is kept for the subscriber who has not read the previous one
+*/
+func SubscribeTask(taskID string) (<-chan *TaskEvent, func()) {
+	subscribers.mu.Lock()
+	defer subscribers.mu.Unlock()
+	subscribers.seq++
+	seq := subscribers.seq
+	ch := make(chan *TaskEvent, 1)
+	if subscribers.subs[taskID] == nil {
+		subscribers.subs[taskID] = make(map[int]chan *TaskEvent)
+	}
+	subscribers.subs[taskID][seq] = ch
+	return ch, func() {
+		subscribers.mu.Lock()
+		defer subscribers.mu.Unlock()
+		delete(subscribers.subs[taskID], seq)
+		if len(subscribers.subs[taskID]) == 0 {
+			delete(subscribers.subs, taskID)
+		}
+	}
+}
+
+// CurrentTaskEvent returns the latest state of the task, the task which is not in the task map has ended
+func CurrentTaskEvent(taskInfo *db.TaskInfo) (*TaskEvent, error) {
+	ended := true
+	if task, ok := GetTaskMgr().getTaskFromMap(taskInfo.BID); ok {
+		taskInfo, ended = task.TaskInfo, false
+	}
+	snapshot := *taskInfo
+	list, err := toImportTaskList([]*db.TaskInfo{&snapshot})
+	if err != nil {
+		return nil, err
+	}
+	return &TaskEvent{Task: &list[0], Ended: ended}, nil
+}
+
+// publishTask pushes the state of the task to its subscribers without blocking the task
+func publishTask(taskInfo *db.TaskInfo, ended bool) {
+	subscribers.mu.Lock()
+	defer subscribers.mu.Unlock()
+	subs := subscribers.subs[taskInfo.BID]
+	if len(subs) == 0 {
+		return
+	}
+	snapshot := *taskInfo
+	list, err := toImportTaskList([]*db.TaskInfo{&snapshot})
+	if err != nil {
+		logx.Errorf("[task %s] publish the task error: %s", taskInfo.BID, err)
+		return
+	}
+	event := &TaskEvent{Task: &list[0], Ended: ended}
+	for _, ch := range subs {
+		select {
+		case ch <- event:
+		default:
+			// drop the event not read yet, the new one is more recent
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- event
+		}
+	}
+}
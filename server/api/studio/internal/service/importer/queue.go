@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"sort"
+	"sync"
+
+	studioConfig "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+)
+
+var taskQueue = newTaskQueue(configQueueLimits)
+
+/*
+TaskQueue holds the tasks waiting to run in front of the TaskMgr,
+the tasks are started by the priority and the order they are queued, as long as
+  - the running tasks are fewer than Import.MaxRunningTasks
+  - the running tasks against the same graphd are fewer than Import.MaxRunningTasksPerAddress
+*/
+type TaskQueue struct {
+	mu      sync.Mutex
+	seq     int64
+	waiting []*queuedTask
+	// running maps the task id to the graphd address which the task runs against
+	running map[string]string
+	closed  bool
+	// limits returns MaxRunningTasks and MaxRunningTasksPerAddress, no limit if it is 0
+	limits func() (maxRunning, maxPerAddress int)
+}
+
+type queuedTask struct {
+	taskID   string
+	address  string
+	priority int
+	seq      int64
+	start    func()
+}
+
+// discarder is implemented by the runners which hold resources before they run, e.g. the datasource store
+type discarder interface {
+	Discard()
+}
+
+func GetTaskQueue() *TaskQueue {
+	return taskQueue
+}
+
+func newTaskQueue(limits func() (int, int)) *TaskQueue {
+	return &TaskQueue{
+		running: map[string]string{},
+		limits:  limits,
+	}
+}
+
+// configQueueLimits reads the limits from the config each time, since the config is loaded after the queue is created
+func configQueueLimits() (int, int) {
+	if c := studioConfig.GetConfig(); c != nil {
+		return c.Import.MaxRunningTasks, c.Import.MaxRunningTasksPerAddress
+	}
+	return 0, 0
+}
+
+// Push queues the task, it is started right away if the limits allow
+func (q *TaskQueue) Push(task *Task, start func()) {
+	q.mu.Lock()
+	q.seq++
+	q.waiting = append(q.waiting, &queuedTask{
+		taskID:   task.TaskInfo.BID,
+		address:  task.TaskInfo.Address,
+		priority: task.TaskInfo.Priority,
+		seq:      q.seq,
+		start:    start,
+	})
+	sort.SliceStable(q.waiting, func(i, j int) bool {
+		a, b := q.waiting[i], q.waiting[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		return a.seq < b.seq
+	})
+	ready := q.pop()
+	q.mu.Unlock()
+	startTasks(ready)
+}
+
+// Remove takes the task out of the queue, it returns false if the task is not waiting
+func (q *TaskQueue) Remove(taskID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, t := range q.waiting {
+		if t.taskID == taskID {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Done frees the slot of the task when it ends, and starts the tasks waiting for the slot
+func (q *TaskQueue) Done(taskID string) {
+	q.mu.Lock()
+	if _, ok := q.running[taskID]; !ok {
+		q.mu.Unlock()
+		return
+	}
+	delete(q.running, taskID)
+	ready := q.pop()
+	q.mu.Unlock()
+	startTasks(ready)
+}
+
+// Position is the position of the task in the queue starting from 1, it is 0 if the task is not waiting
+func (q *TaskQueue) Position(taskID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, t := range q.waiting {
+		if t.taskID == taskID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Close stops starting the tasks waiting, it is called when the service is shutting down
+func (q *TaskQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+}
+
+// pop takes out the tasks which can be started under the limits, a task blocked by its graphd does not block the others
+func (q *TaskQueue) pop() []*queuedTask {
+	if q.closed {
+		return nil
+	}
+	maxRunning, maxPerAddress := q.limits()
+	perAddress := map[string]int{}
+	for _, address := range q.running {
+		perAddress[address]++
+	}
+	var ready, waiting []*queuedTask
+	for _, t := range q.waiting {
+		if (maxRunning > 0 && len(q.running) >= maxRunning) || (maxPerAddress > 0 && perAddress[t.address] >= maxPerAddress) {
+			waiting = append(waiting, t)
+			continue
+		}
+		q.running[t.taskID] = t.address
+		perAddress[t.address]++
+		ready = append(ready, t)
+	}
+	q.waiting = waiting
+	return ready
+}
+
+func startTasks(tasks []*queuedTask) {
+	for _, t := range tasks {
+		t.start()
+	}
+}
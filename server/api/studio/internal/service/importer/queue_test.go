@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+)
+
+type queueTestTask struct {
+	id       string
+	address  string
+	priority int
+}
+
+func pushTestTasks(q *TaskQueue, tasks []queueTestTask, started *[]string) {
+	for _, t := range tasks {
+		id := t.id
+		q.Push(&Task{TaskInfo: &db.TaskInfo{BID: id, Address: t.address, Priority: t.priority}}, func() {
+			*started = append(*started, id)
+		})
+	}
+}
+
+func TestTaskQueue_Limits(t *testing.T) {
+	tasks := []queueTestTask{
+		{id: "1", address: "a"},
+		{id: "2", address: "a"},
+		{id: "3", address: "b"},
+		{id: "4", address: "c"},
+	}
+	testCases := []struct {
+		name          string
+		maxRunning    int
+		maxPerAddress int
+		started       []string
+		waiting       []string
+	}{
+		{name: "no limit", started: []string{"1", "2", "3", "4"}},
+		{name: "global", maxRunning: 2, started: []string{"1", "2"}, waiting: []string{"3", "4"}},
+		{name: "per address", maxPerAddress: 1, started: []string{"1", "3", "4"}, waiting: []string{"2"}},
+		{name: "both", maxRunning: 2, maxPerAddress: 1, started: []string{"1", "3"}, waiting: []string{"2", "4"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newTaskQueue(func() (int, int) { return tc.maxRunning, tc.maxPerAddress })
+			var started []string
+			pushTestTasks(q, tasks, &started)
+			assert.Equal(t, tc.started, started)
+			for i, id := range tc.waiting {
+				assert.Equal(t, i+1, q.Position(id))
+			}
+			for _, id := range tc.started {
+				assert.Equal(t, 0, q.Position(id))
+			}
+		})
+	}
+}
+
+func TestTaskQueue_Order(t *testing.T) {
+	q := newTaskQueue(func() (int, int) { return 1, 0 })
+	var started []string
+	pushTestTasks(q, []queueTestTask{
+		{id: "running"},
+		{id: "low1", priority: 0},
+		{id: "high1", priority: 2},
+		{id: "mid", priority: 1},
+		{id: "high2", priority: 2},
+		{id: "low2", priority: 0},
+	}, &started)
+	assert.Equal(t, []string{"running"}, started)
+	assert.Equal(t, 1, q.Position("high1"))
+	assert.Equal(t, 2, q.Position("high2"))
+	assert.Equal(t, 5, q.Position("low2"))
+
+	// the tasks are started one by one by the priority, and the order they are queued in the same priority
+	for _, id := range []string{"running", "high1", "high2", "mid", "low1"} {
+		q.Done(id)
+	}
+	assert.Equal(t, []string{"running", "high1", "high2", "mid", "low1", "low2"}, started)
+}
+
+func TestTaskQueue_RemoveAndDone(t *testing.T) {
+	q := newTaskQueue(func() (int, int) { return 0, 1 })
+	var started []string
+	pushTestTasks(q, []queueTestTask{
+		{id: "1", address: "a"},
+		{id: "2", address: "a"},
+		{id: "3", address: "a"},
+	}, &started)
+	assert.Equal(t, []string{"1"}, started)
+
+	// the task cancelled while it is queued is never started
+	assert.True(t, q.Remove("2"))
+	assert.False(t, q.Remove("2"))
+	assert.False(t, q.Remove("1"))
+	assert.Equal(t, 0, q.Position("2"))
+	assert.Equal(t, 1, q.Position("3"))
+
+	// Done frees the slot only for the running task
+	q.Done("2")
+	assert.Equal(t, []string{"1"}, started)
+	q.Done("1")
+	assert.Equal(t, []string{"1", "3"}, started)
+	q.Done("1")
+	assert.Equal(t, []string{"1", "3"}, started)
+
+	// no task is started after the queue is closed
+	q.Close()
+	pushTestTasks(q, []queueTestTask{{id: "4", address: "b"}}, &started)
+	assert.Equal(t, []string{"1", "3"}, started)
+	assert.Equal(t, 1, q.Position("4"))
+}
@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var errRunnerStopped = errors.New("task is stopped")
+
+// runnerBase holds what the task runners have in common: the stats, the stop signal and the task log
+type runnerBase struct {
+	TaskID string
+
+	mu      sync.Mutex
+	stats   db.Stats
+	stopped bool
+	stopCh  chan struct{}
+	done    chan struct{}
+	logFile *os.File
+}
+
+func newRunnerBase(taskID string) runnerBase {
+	return runnerBase{
+		TaskID: taskID,
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (r *runnerBase) Stats() db.Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+func (r *runnerBase) Stop() error {
+	r.mu.Lock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.stopCh)
+	}
+	r.mu.Unlock()
+	<-r.done
+	return nil
+}
+
+func (r *runnerBase) IsStopped() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopped
+}
+
+func (r *runnerBase) Done() <-chan struct{} {
+	return r.done
+}
+
+// checkStopped returns errRunnerStopped once Stop is called
+func (r *runnerBase) checkStopped() error {
+	select {
+	case <-r.stopCh:
+		return errRunnerStopped
+	default:
+		return nil
+	}
+}
+
+func (r *runnerBase) updateStats(update func(stats *db.Stats)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update(&r.stats)
+}
+
+func (r *runnerBase) openLog(path string) (err error) {
+	r.logFile, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	return err
+}
+
+func (r *runnerBase) closeLog() {
+	if r.logFile != nil {
+		r.logFile.Close()
+	}
+}
+
+func (r *runnerBase) log(level, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if r.logFile != nil {
+		r.logFile.WriteString(fmt.Sprintf("%s [%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), level, msg))
+	}
+	if level == "error" {
+		logx.Errorf("[task %s] %s", r.TaskID, msg)
+	}
+}
+
+/*
+StartRunnerTask queues the task to run the runner in background,
+the status of the task is updated when the runner returns
+*/
+func StartRunnerTask(taskID string) error {
+	task, ok := GetTaskMgr().getTaskFromMap(taskID)
+	if !ok || task.Runner == nil {
+		return errors.New("task is not running")
+	}
+	GetTaskQueue().Push(task, func() {
+		runRunnerTask(taskID, task)
+	})
+	return nil
+}
+
+func runRunnerTask(taskID string, task *Task) {
+	runner := task.Runner
+	GetTaskMgr().setRunning(task)
+
+	go func() {
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				GetTaskMgr().UpdateTaskInfo(taskID)
+			case <-runner.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logx.Errorf("[task runner error]: %+v", r)
+				task.TaskInfo.TaskStatus = Aborted.String()
+				task.TaskInfo.TaskMessage = fmt.Sprintf("%v", r)
+				GetTaskMgr().AbortTask(taskID)
+			}
+		}()
+		err := runner.Run()
+		if runner.IsStopped() {
+			// the status is updated by StopTask
+			return
+		}
+		task.TaskInfo.Stats = runner.Stats()
+		if err != nil {
+			task.TaskInfo.TaskStatus = Aborted.String()
+			task.TaskInfo.TaskMessage = err.Error()
+			GetTaskMgr().AbortTask(taskID)
+			return
+		}
+		task.TaskInfo.TaskStatus = Finished.String()
+		GetTaskMgr().FinishTask(taskID)
+	}()
+}
@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/client"
+)
+
+type (
+	SchemaProp struct {
+		Name     string
+		Type     string // the nebula data type in lower case, e.g. int64, fixed_string(32)
+		Nullable bool
+	}
+
+	SchemaItem struct {
+		Name  string
+		Props []SchemaProp
+	}
+
+	SpaceSchema struct {
+		Space   string
+		VidType string // INT64 or FIXED_STRING(N)
+		Tags    []SchemaItem
+		Edges   []SchemaItem
+	}
+)
+
+func (s *SpaceSchema) IsIntVid() bool {
+	return strings.HasPrefix(strings.ToUpper(s.VidType), "INT")
+}
+
+func (s *SpaceSchema) FindTag(name string) (*SchemaItem, bool) {
+	return findSchemaItem(s.Tags, name)
+}
+
+func (s *SpaceSchema) FindEdge(name string) (*SchemaItem, bool) {
+	return findSchemaItem(s.Edges, name)
+}
+
+func findSchemaItem(items []SchemaItem, name string) (*SchemaItem, bool) {
+	for i := range items {
+		if items[i].Name == name {
+			return &items[i], true
+		}
+	}
+	return nil, false
+}
+
+func (item *SchemaItem) FindProp(name string) (*SchemaProp, bool) {
+	for i := range item.Props {
+		if item.Props[i].Name == name {
+			return &item.Props[i], true
+		}
+	}
+	return nil, false
+}
+
+// ImporterValueType maps the nebula data type to the value type used in the nebula-importer config
+func (p *SchemaProp) ImporterValueType() string {
+	typ := strings.ToLower(p.Type)
+	switch {
+	case strings.HasPrefix(typ, "int"):
+		return "INT"
+	case strings.HasPrefix(typ, "fixed_string"), typ == "string":
+		return "STRING"
+	}
+	return strings.ToUpper(typ)
+}
+
+// QuoteName quotes the schema name with backticks to be used in nGQL
+func QuoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
+
+// DescribeSpace reads the tags, edge types and their props of the space with the client
+func DescribeSpace(nsid, space string) (*SpaceSchema, error) {
+	schema := &SpaceSchema{Space: space}
+	rows, err := executeOne(nsid, space, fmt.Sprintf("DESCRIBE SPACE %s", QuoteName(space)))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("space %s not found", space)
+	}
+	schema.VidType = fmt.Sprintf("%v", rows[0]["Vid Type"])
+
+	for _, kind := range []string{"TAG", "EDGE"} {
+		rows, err := executeOne(nsid, space, fmt.Sprintf("SHOW %sS", kind))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			item := SchemaItem{Name: fmt.Sprintf("%v", row["Name"])}
+			propRows, err := executeOne(nsid, space, fmt.Sprintf("DESCRIBE %s %s", kind, QuoteName(item.Name)))
+			if err != nil {
+				return nil, err
+			}
+			for _, propRow := range propRows {
+				item.Props = append(item.Props, SchemaProp{
+					Name:     fmt.Sprintf("%v", propRow["Field"]),
+					Type:     strings.ToLower(fmt.Sprintf("%v", propRow["Type"])),
+					Nullable: propRow["Null"] == "YES",
+				})
+			}
+			if kind == "TAG" {
+				schema.Tags = append(schema.Tags, item)
+			} else {
+				schema.Edges = append(schema.Edges, item)
+			}
+		}
+	}
+	return schema, nil
+}
+
+func executeOne(nsid, space, gql string) ([]map[string]client.Any, error) {
+	results, err := client.Execute(nsid, space, []string{gql})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no result for %s", gql)
+	}
+	if results[0].Error != nil {
+		return nil, results[0].Error
+	}
+	return results[0].Result.Tables, nil
+}
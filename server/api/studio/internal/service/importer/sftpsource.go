@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/source"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+	pqsource "github.com/xitongsys/parquet-go/source"
+)
+
+type (
+	// SFTPSource is how the sftp file is read when its host is verified, nebula-importer trusts any host
+	SFTPSource struct {
+		Config types.SFTPConfig
+	}
+
+	// sftpSource is the source of nebula-importer whose file is read by the verified sftp client
+	sftpSource struct {
+		source.Source
+		sftp  *SFTPSource
+		store *filestore.SftpStore
+		f     io.ReadCloser
+		size  int64
+	}
+)
+
+// NewSFTPSource checks the sftp source, it returns nil for the source which is not from sftp or whose host is not verified
+func NewSFTPSource(s *types.Source) (*SFTPSource, error) {
+	if s.SFTP == nil || (s.SFTP.HostKeyFingerprint == "" && s.SFTP.KnownHosts == "") {
+		return nil, nil
+	}
+	if s.SFTP.Path == "" {
+		return nil, errors.New("the path of the sftp source is required")
+	}
+	return &SFTPSource{Config: *s.SFTP}, nil
+}
+
+// OpenStore connects the sftp server of the source
+func (ss *SFTPSource) OpenStore() (*filestore.SftpStore, error) {
+	return OpenSFTPStore(&ss.Config)
+}
+
+// OpenSFTPStore connects the sftp server by the password or the private key, the key file is read if there is no key data
+func OpenSFTPStore(c *types.SFTPConfig) (*filestore.SftpStore, error) {
+	key := c.KeyData
+	if key == "" && c.KeyFile != "" {
+		data, err := os.ReadFile(c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key = string(data)
+	}
+	return filestore.NewSftpStoreWithAuth(c.Host, c.Port, c.User, filestore.SftpAuth{
+		Password:           c.Password,
+		SftpKey:            filestore.SftpKey{PrivateKey: key, Passphrase: c.Passphrase},
+		HostKeyFingerprint: c.HostKeyFingerprint,
+		KnownHosts:         c.KnownHosts,
+	})
+}
+
+// openParquetFile opens the parquet file for the random access, the store should be closed after the file is read
+func (s *sftpSource) openParquetFile() (pqsource.ParquetFile, io.Closer, error) {
+	store, err := s.sftp.OpenStore()
+	if err != nil {
+		return nil, nil, err
+	}
+	pf, err := store.OpenParquetFile(s.sftp.Config.Path)
+	if err != nil {
+		store.Close()
+		return nil, nil, err
+	}
+	return pf, store, nil
+}
+
+// wrapSFTPSource reads the sftp file by the client which verifies the host
+func wrapSFTPSource(s source.Source, ss *SFTPSource) source.Source {
+	return &sftpSource{Source: s, sftp: ss}
+}
+
+func (s *sftpSource) Open() error {
+	store, err := s.sftp.OpenStore()
+	if err != nil {
+		return err
+	}
+	info, err := store.Stat(s.sftp.Config.Path)
+	if err != nil {
+		store.Close()
+		return err
+	}
+	f, err := store.Open(s.sftp.Config.Path)
+	if err != nil {
+		store.Close()
+		return err
+	}
+	s.store, s.f, s.size = store, f, info.Size
+	return nil
+}
+
+func (s *sftpSource) Size() (int64, error) {
+	return s.size, nil
+}
+
+func (s *sftpSource) Read(p []byte) (int, error) {
+	return s.f.Read(p)
+}
+
+func (s *sftpSource) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.store.Close()
+	return err
+}
@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/vesoft-inc/nebula-importer/v4/pkg/source"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+)
+
+// sqlPageRows is the count of the rows fetched from the cursor at a time
+const sqlPageRows = 500
+
+type (
+	/*
+		SQLSource is how the records are read from the table or the query of the database, the rows are converted into csv rows,
+		whose columns are in the order of the table or the query, the rows should be in a stable order to resume the task
+	*/
+	SQLSource struct {
+		Config types.SQLConfig
+	}
+
+	// sqlCSVReader converts the rows of the database into csv rows as they are read
+	sqlCSVReader struct {
+		rows  *filestore.SQLRowReader
+		store *filestore.SQLStore
+		buf   bytes.Buffer
+		w     *csv.Writer
+		err   error
+		// size is the estimated size of the rows in csv
+		size int64
+	}
+
+	// sqlSource is the source of nebula-importer whose rows are read from the database as csv
+	sqlSource struct {
+		source.Source
+		sql *SQLSource
+		r   *sqlCSVReader
+	}
+)
+
+// NewSQLSource checks the table or the query of the sql source, it returns nil for the source which is not from a database
+func NewSQLSource(s *types.Source) (*SQLSource, error) {
+	if s.SQL == nil {
+		return nil, nil
+	}
+	if format := sourceFormat(s); format != SourceFormatCSV {
+		return nil, fmt.Errorf("the rows of the database can not be read as %s", format)
+	}
+	if s.SQL.Driver == "" {
+		return nil, errors.New("the driver of the sql source is required")
+	}
+	if (s.SQL.Table == "") == (s.SQL.Query == "") {
+		return nil, errors.New("either the table or the query of the sql source is required")
+	}
+	return &SQLSource{Config: *s.SQL}, nil
+}
+
+// Name identifies the rows read without the password
+func (ss *SQLSource) Name() string {
+	c := ss.Config
+	name := c.Driver + "://"
+	if c.Driver == filestore.SQLDriverSQLite {
+		name += c.Database
+	} else {
+		name += c.User + "@" + net.JoinHostPort(c.Host, strconv.Itoa(c.Port)) + "/" + c.Database
+	}
+	if c.Table != "" {
+		return name + "#" + c.Table
+	}
+	return name + "#query"
+}
+
+// OpenStore connects the database of the source
+func (ss *SQLSource) OpenStore() (*filestore.SQLStore, error) {
+	c := ss.Config
+	return filestore.NewSQLStore(c.Driver, c.Host, c.Port, c.Database, c.User, c.Password, c.Params, filestore.LocalRoots())
+}
+
+/*
+NewCSVReader returns the csv rows selected from the database, the delimiter of the rows is comma, there is no header,
+the rows are fetched page by page from the cursor, and the database is closed with the reader
+*/
+func (ss *SQLSource) NewCSVReader() (io.ReadCloser, error) {
+	store, err := ss.OpenStore()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.OpenRows(ss.Config.Table, ss.Config.Query)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	r := &sqlCSVReader{rows: rows, store: store}
+	r.w = csv.NewWriter(&r.buf)
+	return r, nil
+}
+
+// fill converts the next page of the rows, it returns the count of the rows converted
+func (r *sqlCSVReader) fill() int {
+	if r.err != nil {
+		return 0
+	}
+	rows, err := r.rows.Read(sqlPageRows)
+	if err != nil {
+		r.err = err
+	}
+	for _, row := range rows {
+		if err := r.w.Write(row); err != nil {
+			r.err = err
+			return 0
+		}
+	}
+	r.w.Flush()
+	return len(rows)
+}
+
+func (r *sqlCSVReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+	return r.buf.Read(p)
+}
+
+func (r *sqlCSVReader) Close() error {
+	err := r.rows.Close()
+	r.store.Close()
+	return err
+}
+
+/*
+wrapSQLSource reads the sql source as csv, the size is estimated after the source is opened,
+by the count of the rows and the average size of the first page
+*/
+func wrapSQLSource(s source.Source, ss *SQLSource) source.Source {
+	return &sqlSource{Source: s, sql: ss}
+}
+
+func (s *sqlSource) Config() *source.Config {
+	c := s.Source.Config().Clone()
+	c.CSV = &source.CSVConfig{Delimiter: ","}
+	return c
+}
+
+func (s *sqlSource) Name() string {
+	return s.sql.Name()
+}
+
+func (s *sqlSource) Open() error {
+	r, err := s.sql.NewCSVReader()
+	if err != nil {
+		return err
+	}
+	s.r = r.(*sqlCSVReader)
+	count, err := s.r.store.CountRows(s.sql.Config.Table, s.sql.Config.Query)
+	if err != nil {
+		s.r.Close()
+		return err
+	}
+	if n := s.r.fill(); n > 0 {
+		s.r.size = count * int64(s.r.buf.Len()) / int64(n)
+	}
+	return nil
+}
+
+func (s *sqlSource) Size() (int64, error) {
+	if s.r == nil {
+		return 0, nil
+	}
+	return s.r.size, nil
+}
+
+func (s *sqlSource) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+func (s *sqlSource) Close() error {
+	if s.r == nil {
+		return nil
+	}
+	return s.r.Close()
+}
@@ -0,0 +1,356 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+)
+
+const (
+	stageTmpExt   = ".staging"
+	stageMaxFiles = 10000
+)
+
+type (
+	// StageConfig is stored as the raw config of the stage task
+	StageConfig struct {
+		DatasourceId string `json:"datasourceId"`
+		// Files are the paths of the files in the datasource
+		Files []string `json:"files,omitempty"`
+		// Prefixes are the dirs in the datasource whose files are staged recursively
+		Prefixes []string `json:"prefixes,omitempty"`
+		// Pattern and Types filter the files under the prefixes
+		Pattern string   `json:"pattern,omitempty"`
+		Types   []string `json:"types,omitempty"`
+		// Overwrite copies the file even if the file with the same name and size is in the upload dir
+		Overwrite bool `json:"overwrite,omitempty"`
+	}
+
+	/*
+		Stager copies the files of the datasource into the upload dir, so that they are used as the uploaded files,
+		  - the checkpoint of each file is named by its path in the datasource, the cursor is the sha256 of the file staged
+		  - the records of the checkpoint are 1 if the file is written by the task, 0 if it is present already, only the former are cleaned up
+	*/
+	Stager struct {
+		runnerBase
+		Dir       string
+		UploadDir string
+		Cfg       *StageConfig
+		Store     filestore.FileStore
+	}
+
+	// StagedFile is a file of the stage task read from its checkpoint
+	StagedFile struct {
+		Path     string
+		Name     string
+		Size     int64
+		Checksum string
+		// Skipped is set if the file is present in the upload dir before the task
+		Skipped  bool
+		Finished bool
+	}
+
+	stageFile struct {
+		path string
+		size int64
+	}
+)
+
+func ParseStageConfig(rawConfig string) (*StageConfig, error) {
+	cfg := &StageConfig{}
+	if err := json.Unmarshal([]byte(rawConfig), cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// StagedName is the name of the file staged in the upload dir, which is the base name of the path in the datasource
+func StagedName(filePath string) string {
+	return path.Base(strings.SplitN(filePath, "?", 2)[0])
+}
+
+/*
+StagedPath is the path of the file staged in the upload dir, the file should be directly under the upload dir,
+so the dirs of s3 ending with the slash and the names like . or .. are refused
+*/
+func StagedPath(uploadDir, filePath string) (string, error) {
+	key := strings.SplitN(filePath, "?", 2)[0]
+	name := path.Base(key)
+	target := filepath.Join(uploadDir, name)
+	if strings.HasSuffix(key, "/") || name == "." || name == ".." || strings.ContainsAny(name, `/\`) ||
+		filepath.Dir(target) != filepath.Clean(uploadDir) {
+		return "", fmt.Errorf("%s can not be staged as %s", filePath, name)
+	}
+	return target, nil
+}
+
+func NewStager(taskID, dir, uploadDir string, cfg *StageConfig, store filestore.FileStore) *Stager {
+	return &Stager{
+		runnerBase: newRunnerBase(taskID),
+		Dir:        dir,
+		UploadDir:  uploadDir,
+		Cfg:        cfg,
+		Store:      store,
+	}
+}
+
+// Discard releases the runner which is cancelled before it runs
+func (s *Stager) Discard() {
+	s.Store.Close()
+}
+
+func (s *Stager) Run() (err error) {
+	defer close(s.done)
+	defer s.Store.Close()
+	if err = s.openLog(filepath.Join(s.Dir, TaskLogName(db.TaskTypeStage))); err != nil {
+		return err
+	}
+	defer s.closeLog()
+	defer func() {
+		if err != nil && err != errRunnerStopped {
+			s.log("error", "stage failed: %s", err)
+		}
+	}()
+	if err = os.MkdirAll(s.UploadDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	files, err := s.resolveFiles()
+	if err != nil {
+		return err
+	}
+	checkpoints := make(map[string]*db.TaskCheckpoint)
+	saved, err := GetTaskMgr().db.FindTaskCheckpoints(s.TaskID)
+	if err != nil {
+		return err
+	}
+	for _, cp := range saved {
+		checkpoints[cp.Name] = cp
+	}
+	s.updateStats(func(stats *db.Stats) {
+		for _, f := range files {
+			if f.size > 0 {
+				stats.TotalBytes += f.size
+			}
+		}
+		stats.TotalRecords = int64(len(files))
+	})
+
+	s.log("info", "start staging %d files from the datasource %s", len(files), s.Cfg.DatasourceId)
+	var failed int
+	for _, f := range files {
+		cp, ok := checkpoints[f.path]
+		if !ok {
+			cp = &db.TaskCheckpoint{TaskID: s.TaskID, Name: f.path}
+		}
+		if cp.IsFinished {
+			s.updateStats(func(stats *db.Stats) {
+				stats.ProcessedBytes += f.size
+				stats.TotalProcessed++
+			})
+			continue
+		}
+		if err = s.checkStopped(); err != nil {
+			s.log("info", "the task is stopped before %s", f.path)
+			return err
+		}
+		if err = s.stageFile(f, cp); err != nil {
+			if err == errRunnerStopped {
+				s.log("info", "the task is stopped at %s", f.path)
+				return err
+			}
+			// the other files are still staged, the failed ones are staged again when the task is resumed
+			failed++
+			s.log("error", "stage %s failed: %s", f.path, err)
+			s.updateStats(func(stats *db.Stats) {
+				stats.FailedRecords++
+				stats.FailedProcessed++
+			})
+			continue
+		}
+		s.updateStats(func(stats *db.Stats) {
+			stats.TotalProcessed++
+		})
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed to be staged", failed, len(files))
+	}
+	s.log("info", "stage finished, files: %d, bytes: %d", len(files), s.Stats().TotalBytes)
+	return nil
+}
+
+// resolveFiles lists the files of the config, the files with the same name are staged once
+func (s *Stager) resolveFiles() ([]stageFile, error) {
+	var files []stageFile
+	names := make(map[string]string)
+	add := func(filePath string, size int64) {
+		if _, err := StagedPath(s.UploadDir, filePath); err != nil {
+			s.log("warn", "%s is skipped: %s", filePath, err)
+			return
+		}
+		name := StagedName(filePath)
+		if first, ok := names[name]; ok {
+			if first != filePath {
+				s.log("warn", "%s is skipped, as %s is staged with the same name %s", filePath, first, name)
+			}
+			return
+		}
+		names[name] = filePath
+		files = append(files, stageFile{path: filePath, size: size})
+	}
+	for _, filePath := range s.Cfg.Files {
+		info, err := s.Store.Stat(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s failed: %w", filePath, err)
+		}
+		if info.IsDir {
+			return nil, fmt.Errorf("%s is a directory, stage it as a prefix", filePath)
+		}
+		add(filePath, info.Size)
+	}
+	opts := &filestore.ListOptions{Pattern: s.Cfg.Pattern, Types: s.Cfg.Types}
+	for _, prefix := range s.Cfg.Prefixes {
+		if err := s.walk(prefix, opts, add); err != nil {
+			if err == errRunnerStopped {
+				return nil, err
+			}
+			return nil, fmt.Errorf("list %s failed: %w", prefix, err)
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no file is found to stage")
+	}
+	if len(files) > stageMaxFiles {
+		return nil, fmt.Errorf("%d files are found, at most %d files are staged by a task", len(files), stageMaxFiles)
+	}
+	return files, nil
+}
+
+// walk lists the files under the dir recursively, the dirs are listed with the trailing slash as the prefixes of s3
+func (s *Stager) walk(dir string, opts *filestore.ListOptions, add func(filePath string, size int64)) error {
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	list, err := s.Store.ListFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range list {
+		if err := s.checkStopped(); err != nil {
+			return err
+		}
+		if f.Type == "directory" {
+			if err := s.walk(dir+f.Name, opts, add); err != nil {
+				return err
+			}
+			continue
+		}
+		if opts.Match(f) {
+			add(dir+f.Name, f.Size)
+		}
+	}
+	return nil
+}
+
+/*
+stageFile copies the file into a temporary file beside the target while hashing it, and renames it after the size is verified,
+the file with the same name and size in the upload dir is kept unless Overwrite is set
+*/
+func (s *Stager) stageFile(f stageFile, cp *db.TaskCheckpoint) error {
+	target, err := StagedPath(s.UploadDir, f.path)
+	if err != nil {
+		return err
+	}
+	if !s.Cfg.Overwrite {
+		if info, err := os.Stat(target); err == nil && !info.IsDir() && info.Size() == f.size {
+			checksum, err := FileChecksum(target)
+			if err != nil {
+				return err
+			}
+			s.log("info", "%s is present in the upload dir, skip it, sha256: %s", StagedName(f.path), checksum)
+			s.updateStats(func(stats *db.Stats) {
+				stats.ProcessedBytes += f.size
+			})
+			cp.Cursor, cp.Offset, cp.Records, cp.IsFinished = checksum, f.size, 0, true
+			return GetTaskMgr().db.SaveTaskCheckpoint(cp)
+		}
+	}
+
+	r, err := s.Store.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	tmpPath := target + "." + s.TaskID + stageTmpExt
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	h := sha256.New()
+	written, err := io.Copy(io.MultiWriter(tmp, h), &stageReader{r: r, stager: s})
+	if err != nil {
+		tmp.Close()
+		s.updateStats(func(stats *db.Stats) {
+			stats.ProcessedBytes -= written
+		})
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if f.size > 0 && written != f.size {
+		s.updateStats(func(stats *db.Stats) {
+			stats.ProcessedBytes -= written
+		})
+		return fmt.Errorf("%d bytes are read, but the size is %d", written, f.size)
+	}
+	if err = os.Rename(tmpPath, target); err != nil {
+		return err
+	}
+	checksum := hex.EncodeToString(h.Sum(nil))
+	s.log("info", "%s is staged as %s, size: %d, sha256: %s", f.path, StagedName(f.path), written, checksum)
+	cp.Cursor, cp.Offset, cp.Records, cp.IsFinished = checksum, written, 1, true
+	return GetTaskMgr().db.SaveTaskCheckpoint(cp)
+}
+
+// stageReader counts the bytes copied into the progress, and stops copying once the task is stopped
+type stageReader struct {
+	r      io.Reader
+	stager *Stager
+}
+
+func (r *stageReader) Read(p []byte) (int, error) {
+	if err := r.stager.checkStopped(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	r.stager.updateStats(func(stats *db.Stats) {
+		stats.ProcessedBytes += int64(n)
+	})
+	return n, err
+}
+
+// FileChecksum is the hex sha256 of the file
+func FileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"errors"
+	"os"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// FindStageTask is used to check whether the stage task belongs to the user
+func FindStageTask(taskID, address, username string) (*db.TaskInfo, error) {
+	taskInfo, err := taskmgr.db.FindTaskInfoByIdAndAddresssAndUser(taskID, address, username)
+	if err != nil || taskInfo.TaskType != db.TaskTypeStage {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("task not existed"))
+	}
+	return taskInfo, nil
+}
+
+func GetStageTask(taskID, address, username string) (*types.GetStageTaskData, error) {
+	taskInfo, err := FindStageTask(taskID, address, username)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := GetTaskMgr().getTaskFromMap(taskID); ok {
+		taskInfo = t.TaskInfo
+	}
+	files, err := FindStagedFiles(taskID)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalDatabase, err)
+	}
+	data := toStageTaskData(taskInfo)
+	data.Files = make([]types.StagedFile, 0, len(files))
+	for _, f := range files {
+		data.Files = append(data.Files, types.StagedFile{
+			Path:     f.Path,
+			Name:     f.Name,
+			Size:     f.Size,
+			Checksum: f.Checksum,
+			Skipped:  f.Skipped,
+			Finished: f.Finished,
+		})
+	}
+	return &data, nil
+}
+
+func GetManyStageTask(address, username string, pageIndex, pageSize int) (*types.GetManyStageTaskData, error) {
+	result := &types.GetManyStageTaskData{
+		Total: 0,
+		List:  []types.GetStageTaskData{},
+	}
+
+	tasks, count, err := taskmgr.db.FindTaskInfoByAddressAndUser(address, username, "", []string{db.TaskTypeStage}, pageIndex, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if running, ok := GetTaskMgr().getTaskFromMap(t.BID); ok {
+			t = running.TaskInfo
+		}
+		result.List = append(result.List, toStageTaskData(t))
+	}
+	result.Total = count
+	return result, nil
+}
+
+// FindStagedFiles reads the files of the stage task from its checkpoints
+func FindStagedFiles(taskID string) ([]StagedFile, error) {
+	checkpoints, err := taskmgr.db.FindTaskCheckpoints(taskID)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]StagedFile, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		files = append(files, StagedFile{
+			Path:     cp.Name,
+			Name:     StagedName(cp.Name),
+			Size:     cp.Offset,
+			Checksum: cp.Cursor,
+			Skipped:  cp.Records == 0,
+			Finished: cp.IsFinished,
+		})
+	}
+	return files, nil
+}
+
+func StopStageTask(taskID, address, username string) error {
+	if _, err := FindStageTask(taskID, address, username); err != nil {
+		return err
+	}
+	if err := GetTaskMgr().StopTask(taskID); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return nil
+}
+
+/*
+DeleteStageTask deletes the task and cleans up the files it staged unless keepFiles is set,
+the files present before the task and the files changed after it are kept, the names of the files removed are returned
+*/
+func DeleteStageTask(tasksDir, uploadDir, taskID, address, username string, keepFiles bool) ([]string, error) {
+	if _, err := FindStageTask(taskID, address, username); err != nil {
+		return nil, err
+	}
+	if _, ok := GetTaskMgr().getTaskFromMap(taskID); ok {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("task is running, please stop it first"))
+	}
+	var removed []string
+	if !keepFiles {
+		files, err := FindStagedFiles(taskID)
+		if err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalDatabase, err)
+		}
+		for _, f := range files {
+			if f.Skipped || !f.Finished {
+				continue
+			}
+			target, err := StagedPath(uploadDir, f.Path)
+			if err != nil {
+				logx.Errorf("[task %s] clean up the file staged error: %s", taskID, err)
+				continue
+			}
+			checksum, err := FileChecksum(target)
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					logx.Errorf("[task %s] check the file staged %s error: %s", taskID, f.Name, err)
+				}
+				continue
+			}
+			if checksum != f.Checksum {
+				logx.Infof("[task %s] the file staged %s is changed, keep it", taskID, f.Name)
+				continue
+			}
+			if err := os.Remove(target); err != nil {
+				return removed, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+			}
+			removed = append(removed, f.Name)
+		}
+	}
+	if err := GetTaskMgr().DelTask(tasksDir, taskID); err != nil {
+		return removed, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return removed, nil
+}
+
+func toStageTaskData(t *db.TaskInfo) types.GetStageTaskData {
+	stats := t.Stats
+	data := types.GetStageTaskData{
+		Id:         t.BID,
+		Name:       t.Name,
+		User:       t.User,
+		Address:    t.Address,
+		Status:     t.TaskStatus,
+		Message:    t.TaskMessage,
+		CreateTime: t.CreateTime.UnixMilli(),
+		UpdateTime: t.UpdateTime.UnixMilli(),
+		Stats: types.ImportTaskStats{
+			TotalBytes:      stats.TotalBytes,
+			ProcessedBytes:  stats.ProcessedBytes,
+			FailedRecords:   stats.FailedRecords,
+			TotalRecords:    stats.TotalRecords,
+			TotalRequest:    stats.TotalRequest,
+			FailedRequest:   stats.FailedRequest,
+			TotalLatency:    int64(stats.TotalLatency),
+			TotalRespTime:   int64(stats.TotalRespTime),
+			FailedProcessed: stats.FailedProcessed,
+			TotalProcessed:  stats.TotalProcessed,
+		},
+	}
+	if cfg, err := ParseStageConfig(t.RawConfig); err == nil {
+		data.DatasourceId = cfg.DatasourceId
+	}
+	return data
+}
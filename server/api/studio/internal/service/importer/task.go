@@ -13,18 +13,37 @@ type Client struct {
 	Logger     logger.Logger       `json:"logger,omitempty"`
 	Manager    manager.Manager     `json:"manager,omitempty"`
 	HasStarted bool                `json:"has_started,omitempty"`
+	Tracker    *ImportTracker      `json:"-"`
 }
+
+// TaskRunner is implemented by tasks which are not driven by nebula-importer, such as export tasks.
+type TaskRunner interface {
+	// Run does the work of the task and returns when it is done, failed or stopped.
+	Run() error
+	Stats() db.Stats
+	// Stop asks the runner to stop and blocks until it has stopped.
+	Stop() error
+	IsStopped() bool
+	// Done is closed when Run returns.
+	Done() <-chan struct{}
+}
+
 type Task struct {
 	Client   *Client      `json:"client,omitempty"`
+	Runner   TaskRunner   `json:"-"`
 	TaskInfo *db.TaskInfo `json:"task_info,omitempty"`
 }
 
 func (t *Task) UpdateQueryStats() error {
+	if t.Runner != nil {
+		t.TaskInfo.Stats = t.Runner.Stats()
+		return nil
+	}
 	if (t.Client == nil) || (t.Client.Manager == nil) {
 		return nil
 	}
 	stats := t.Client.Manager.Stats()
-	t.TaskInfo.Stats = db.Stats{
+	runStats := db.Stats{
 		ProcessedBytes:  stats.ProcessedBytes,
 		TotalBytes:      stats.TotalBytes,
 		FailedRecords:   stats.FailedRecords,
@@ -36,5 +55,9 @@ func (t *Task) UpdateQueryStats() error {
 		FailedProcessed: stats.FailedProcessed,
 		TotalProcessed:  stats.TotalProcessed,
 	}
+	if t.Client.Tracker != nil {
+		runStats = t.Client.Tracker.MergeStats(runStats)
+	}
+	t.TaskInfo.Stats = runStats
 	return nil
 }
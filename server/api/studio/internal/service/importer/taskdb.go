@@ -6,6 +6,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TaskDb struct {
@@ -16,8 +17,8 @@ func InitTaskStatus() {
 	GetTaskMgr().db = &TaskDb{
 		DB: db.CtxDB,
 	}
-	if err := GetTaskMgr().db.UpdateProcessingTasks2Aborted(); err != nil {
-		logx.Errorf("update processing tasks to aborted failed: %s", err)
+	if err := GetTaskMgr().db.UpdateProcessingTasks2Interrupted(); err != nil {
+		logx.Errorf("update processing tasks to interrupted failed: %s", err)
 		panic(err)
 	}
 }
@@ -32,10 +33,13 @@ func (t *TaskDb) FindTaskInfoByIdAndAddresssAndUser(id, address, user string) (*
 	return taskInfo, nil
 }
 
-func (t *TaskDb) FindTaskInfoByAddressAndUser(address, user, space string, pageIndex, pageSize int) ([]*db.TaskInfo, int64, error) {
+func (t *TaskDb) FindTaskInfoByAddressAndUser(address, user, space string, taskTypes []string, pageIndex, pageSize int) ([]*db.TaskInfo, int64, error) {
 	tasks := make([]*db.TaskInfo, 0)
 	var count int64
 	tx := t.Model(&db.TaskInfo{}).Where("address = ? And user = ?", address, user)
+	if len(taskTypes) > 0 {
+		tx = tx.Where("task_type IN ?", taskTypes)
+	}
 	if space != "" {
 		tx = tx.Where("space = ?", space)
 	}
@@ -49,6 +53,20 @@ func (t *TaskDb) FindTaskInfoByAddressAndUser(address, user, space string, pageI
 	return tasks, count, nil
 }
 
+// FindTaskInfoBySchedule lists the runs of the schedule, the latest first
+func (t *TaskDb) FindTaskInfoBySchedule(scheduleID string, pageIndex, pageSize int) ([]*db.TaskInfo, int64, error) {
+	tasks := make([]*db.TaskInfo, 0)
+	var count int64
+	tx := t.Model(&db.TaskInfo{}).Where("schedule_id = ?", scheduleID).Order("id desc")
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := tx.Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&tasks).Error; err != nil {
+		return nil, count, err
+	}
+	return tasks, count, nil
+}
+
 func (t *TaskDb) InsertTaskInfo(info *db.TaskInfo) error {
 	return t.Create(info).Error
 }
@@ -57,17 +75,36 @@ func (t *TaskDb) UpdateTaskInfo(info *db.TaskInfo) error {
 	return t.Model(&db.TaskInfo{}).Where("b_id = ?", info.BID).Updates(info).Error
 }
 
+// UpdateTaskStatus also clears the task message when the message is empty, which Updates(info) can not do
+func (t *TaskDb) UpdateTaskStatus(id, status, message string) error {
+	return t.Model(&db.TaskInfo{}).Where("b_id = ?", id).Updates(map[string]interface{}{
+		"task_status":  status,
+		"task_message": message,
+	}).Error
+}
+
 func (t *TaskDb) DelTaskInfo(ID string) error {
 	return t.Delete(&db.TaskInfo{}, "b_id = ?", ID).Error
 }
 
-func (t *TaskDb) UpdateProcessingTasks2Aborted() error {
-	if err := t.Model(&db.TaskInfo{}).Where("task_status = ?", Processing.String()).Updates(&db.TaskInfo{TaskStatus: Aborted.String(), TaskMessage: "Service execption"}).Error; err != nil {
+// UpdateProcessingTasks2Interrupted marks the tasks left running or queued by the last crash of the service, they can be resumed from the checkpoints
+func (t *TaskDb) UpdateProcessingTasks2Interrupted() error {
+	if err := t.Model(&db.TaskInfo{}).Where("task_status IN ?", []string{Processing.String(), Queued.String()}).Updates(&db.TaskInfo{TaskStatus: Interrupted.String(), TaskMessage: interruptedMessage}).Error; err != nil {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
 	return nil
 }
 
+// FindInterruptedTasks lists the interrupted tasks which have not been resumed by another run, the earliest first
+func (t *TaskDb) FindInterruptedTasks() ([]*db.TaskInfo, error) {
+	tasks := make([]*db.TaskInfo, 0)
+	resumed := t.Model(&db.TaskInfo{}).Select("parent_task_id").Where("run_type = ?", RunTypeResume)
+	if err := t.Where("task_status = ? AND b_id NOT IN (?)", Interrupted.String(), resumed).Order("id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
 func (t *TaskDb) InsertTaskEffect(taskEffect *db.TaskEffect) error {
 	return t.Create(taskEffect).Error
 }
@@ -79,3 +116,23 @@ func (t *TaskDb) UpdateTaskEffect(taskEffect *db.TaskEffect) error {
 func (t *TaskDb) DelTaskEffect(ID string) error {
 	return t.Delete(&db.TaskEffect{}, "task_id = ?", ID).Error
 }
+
+func (t *TaskDb) FindTaskCheckpoints(taskID string) ([]*db.TaskCheckpoint, error) {
+	checkpoints := make([]*db.TaskCheckpoint, 0)
+	if err := t.Where("task_id = ?", taskID).Order("id").Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
+
+// SaveTaskCheckpoint inserts the checkpoint or overwrites the one with the same task id and name
+func (t *TaskDb) SaveTaskCheckpoint(checkpoint *db.TaskCheckpoint) error {
+	return t.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cursor_value", "byte_offset", "records", "is_finished", "update_time"}),
+	}).Create(checkpoint).Error
+}
+
+func (t *TaskDb) DelTaskCheckpoints(taskID string) error {
+	return t.Delete(&db.TaskCheckpoint{}, "task_id = ?", taskID).Error
+}
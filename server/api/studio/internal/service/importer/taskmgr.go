@@ -13,8 +13,11 @@ import (
 	configv3 "github.com/vesoft-inc/nebula-importer/v4/pkg/config/v3"
 	studioConfig "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
 	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/webhook"
+	"github.com/zeromicro/go-zero/core/logx"
 	"gopkg.in/yaml.v3"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -64,6 +67,12 @@ func CreateConfigFile(taskdir string, cfgBytes []byte) (string, error) {
 		if SFTPConfig != nil {
 			SFTPConfig.User = "${YOUR_SFTP_USER}"
 			SFTPConfig.Password = "${YOUR_SFTP_PASSWORD}"
+			if SFTPConfig.KeyData != "" {
+				SFTPConfig.KeyData = "${YOUR_SFTP_PRIVATE_KEY}"
+			}
+			if SFTPConfig.Passphrase != "" {
+				SFTPConfig.Passphrase = "${YOUR_SFTP_PASSPHRASE}"
+			}
 		}
 		if OSSConfig != nil {
 			OSSConfig.AccessKeyID = "${YOUR_OSS_ACCESS_KEY}"
@@ -80,7 +89,7 @@ func CreateConfigFile(taskdir string, cfgBytes []byte) (string, error) {
 	return string(outYaml), nil
 }
 
-func (mgr *TaskMgr) NewTask(id, host, user, taskName, rawCfg string, cfg importconfig.Configurator) (*Task, error) {
+func (mgr *TaskMgr) NewTask(id, host, user, taskName, rawCfg string, priority int, cfg importconfig.Configurator) (*Task, error) {
 	mux.Lock()
 	defer mux.Unlock()
 	confv3 := cfg.(*configv3.Config)
@@ -91,10 +100,11 @@ func (mgr *TaskMgr) NewTask(id, host, user, taskName, rawCfg string, cfg importc
 		Name:          taskName,
 		Address:       host,
 		Space:         confv3.Manager.GraphName,
-		TaskStatus:    Processing.String(),
+		TaskStatus:    Queued.String(),
 		ImportAddress: confv3.Client.Address,
 		User:          user,
 		RawConfig:     rawCfg,
+		Priority:      priority,
 	}
 
 	if err := mgr.db.InsertTaskInfo(taskInfo); err != nil {
@@ -115,6 +125,66 @@ func (mgr *TaskMgr) NewTask(id, host, user, taskName, rawCfg string, cfg importc
 	return task, nil
 }
 
+/*
+NewRunnerTask stores a task which is driven by the runner instead of nebula-importer;
+if the task info already exists, e.g. the task is resumed, it is switched back to queued
+*/
+func (mgr *TaskMgr) NewRunnerTask(taskInfo *db.TaskInfo, runner TaskRunner) (*Task, error) {
+	mux.Lock()
+	defer mux.Unlock()
+	if _, ok := mgr.getTaskFromMap(taskInfo.BID); ok {
+		return nil, errors.New("task is running")
+	}
+
+	taskInfo.TaskStatus = Queued.String()
+	if taskInfo.ID == 0 {
+		if err := mgr.db.InsertTaskInfo(taskInfo); err != nil {
+			return nil, err
+		}
+	} else if err := mgr.db.UpdateTaskStatus(taskInfo.BID, taskInfo.TaskStatus, ""); err != nil {
+		return nil, err
+	}
+	taskInfo.TaskMessage = ""
+
+	task := &Task{
+		Client:   &Client{},
+		Runner:   runner,
+		TaskInfo: taskInfo,
+	}
+	mgr.PutTask(taskInfo.BID, task)
+	return task, nil
+}
+
+// setRunning switches the task taken out of the queue to processing
+func (mgr *TaskMgr) setRunning(task *Task) {
+	task.TaskInfo.TaskStatus = Processing.String()
+	if err := mgr.db.UpdateTaskStatus(task.TaskInfo.BID, task.TaskInfo.TaskStatus, task.TaskInfo.TaskMessage); err != nil {
+		logx.Errorf("[task %s] update the task to running error: %s", task.TaskInfo.BID, err)
+	}
+	mgr.sampleTask(task, true)
+	publishTask(task.TaskInfo, false)
+}
+
+// SetTaskSchedule links the task to the schedule which creates it
+func (mgr *TaskMgr) SetTaskSchedule(taskID, scheduleID string) error {
+	if task, ok := mgr.getTaskFromMap(taskID); ok {
+		task.TaskInfo.ScheduleID = scheduleID
+	}
+	return mgr.db.Model(&db.TaskInfo{}).Where("b_id = ?", taskID).Update("schedule_id", scheduleID).Error
+}
+
+// SetTaskParent links the run to the task it resumes or retries
+func (mgr *TaskMgr) SetTaskParent(taskID, parentID, runType string) error {
+	if task, ok := mgr.getTaskFromMap(taskID); ok {
+		task.TaskInfo.ParentTaskID = parentID
+		task.TaskInfo.RunType = runType
+	}
+	return mgr.db.Model(&db.TaskInfo{}).Where("b_id = ?", taskID).Updates(map[string]interface{}{
+		"parent_task_id": parentID,
+		"run_type":       runType,
+	}).Error
+}
+
 func (mgr *TaskMgr) NewTaskEffect(taskEffect *db.TaskEffect) error {
 	mux.Lock()
 	defer mux.Unlock()
@@ -124,7 +194,7 @@ func (mgr *TaskMgr) NewTaskEffect(taskEffect *db.TaskEffect) error {
 	return nil
 }
 
-func (mgr *TaskMgr) TurnDraftToTask(id, taskName, rawCfg string, cfg importconfig.Configurator) (*Task, error) {
+func (mgr *TaskMgr) TurnDraftToTask(id, host, taskName, rawCfg string, priority int, cfg importconfig.Configurator) (*Task, error) {
 	mux.Lock()
 	defer mux.Unlock()
 	confv3 := cfg.(*configv3.Config)
@@ -133,10 +203,12 @@ func (mgr *TaskMgr) TurnDraftToTask(id, taskName, rawCfg string, cfg importconfi
 	taskInfo := &db.TaskInfo{
 		BID:           id,
 		Name:          taskName,
+		Address:       host,
 		Space:         confv3.Manager.GraphName,
-		TaskStatus:    Processing.String(),
+		TaskStatus:    Queued.String(),
 		ImportAddress: confv3.Client.Address,
 		RawConfig:     rawCfg,
+		Priority:      priority,
 		CreateTime:    time.Now(),
 	}
 
@@ -225,6 +297,7 @@ func (mgr *TaskMgr) PutTask(taskID string, task *Task) {
 FinishTask will query task stats
   - delete task in the map
   - update taskInfo in db
+  - save the performance report of the task
   - update taskEffect in db
 */
 func (mgr *TaskMgr) FinishTask(taskID string) (err error) {
@@ -232,6 +305,9 @@ func (mgr *TaskMgr) FinishTask(taskID string) (err error) {
 	if !ok {
 		return
 	}
+	if task.Client != nil && task.Client.Tracker != nil {
+		task.Client.Tracker.Flush()
+	}
 	if err := task.UpdateQueryStats(); err != nil {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
@@ -240,8 +316,12 @@ func (mgr *TaskMgr) FinishTask(taskID string) (err error) {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
 	mgr.tasks.Delete(taskID)
+	GetTaskQueue().Done(taskID)
+	mgr.reportTask(task.TaskInfo)
+	publishTask(task.TaskInfo, true)
+	notifyTask(task.TaskInfo)
 
-	return mgr.StorePartTaskLog(taskID)
+	return mgr.StorePartTaskLog(taskID, task.TaskInfo.TaskType)
 }
 
 func (mgr *TaskMgr) AbortTask(taskID string) (err error) {
@@ -249,18 +329,28 @@ func (mgr *TaskMgr) AbortTask(taskID string) (err error) {
 	if !ok {
 		return nil
 	}
+	if task.Client != nil && task.Client.Tracker != nil {
+		task.Client.Tracker.Flush()
+	}
 	err = mgr.db.UpdateTaskInfo(task.TaskInfo)
 	if err != nil {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
 	mgr.tasks.Delete(taskID)
-	return mgr.StorePartTaskLog(taskID)
+	GetTaskQueue().Done(taskID)
+	mgr.reportTask(task.TaskInfo)
+	publishTask(task.TaskInfo, true)
+	notifyTask(task.TaskInfo)
+	return mgr.StorePartTaskLog(taskID, task.TaskInfo.TaskType)
 }
 
 func (mgr *TaskMgr) DelTask(tasksDir, taskID string) error {
-	_, ok := mgr.getTaskFromMap(taskID)
+	task, ok := mgr.getTaskFromMap(taskID)
 	if ok {
 		mgr.tasks.Delete(taskID)
+		GetTaskQueue().Remove(taskID)
+		GetTaskQueue().Done(taskID)
+		publishTask(task.TaskInfo, true)
 	}
 	if err := mgr.db.DelTaskInfo(taskID); err != nil {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
@@ -268,12 +358,29 @@ func (mgr *TaskMgr) DelTask(tasksDir, taskID string) error {
 	if err := mgr.db.DelTaskEffect(taskID); err != nil {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
+	if err := mgr.db.DelTaskCheckpoints(taskID); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	if err := mgr.db.DelTaskMetrics(taskID); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
 	taskDir := filepath.Join(tasksDir, taskID)
 	return os.RemoveAll(taskDir)
 }
 
-func (mgr *TaskMgr) StorePartTaskLog(taskID string) error {
-	filePath := filepath.Join(studioConfig.GetConfig().File.TasksDir, taskID, "import.log")
+// TaskLogName returns the name of the log file which a task of the given type writes in its task dir
+func TaskLogName(taskType string) string {
+	switch taskType {
+	case db.TaskTypeExport:
+		return "export.log"
+	case db.TaskTypeStage:
+		return "stage.log"
+	}
+	return "import.log"
+}
+
+func (mgr *TaskMgr) StorePartTaskLog(taskID, taskType string) error {
+	filePath := filepath.Join(studioConfig.GetConfig().File.TasksDir, taskID, TaskLogName(taskType))
 	content, err := utils.ReadPartFile(filePath)
 	if err != nil {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
@@ -290,8 +397,8 @@ func (mgr *TaskMgr) StorePartTaskLog(taskID string) error {
 }
 
 /*
-UpdateTaskInfo will query task stats, update task in the map
-and update the taskInfo in local sql
+UpdateTaskInfo will query task stats, update task in the map,
+update the taskInfo in local sql and sample the stats
 */
 func (mgr *TaskMgr) UpdateTaskInfo(taskID string) error {
 	task, ok := mgr.getTaskFromMap(taskID)
@@ -301,7 +408,12 @@ func (mgr *TaskMgr) UpdateTaskInfo(taskID string) error {
 	if err := task.UpdateQueryStats(); err != nil {
 		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
 	}
-	return mgr.db.UpdateTaskInfo(task.TaskInfo)
+	if err := mgr.db.UpdateTaskInfo(task.TaskInfo); err != nil {
+		return err
+	}
+	mgr.sampleTask(task, false)
+	publishTask(task.TaskInfo, false)
+	return nil
 }
 
 /*
@@ -309,30 +421,83 @@ StopTask will change the task status to `Stoped`,
 and then call FinishTask
 */
 func (mgr *TaskMgr) StopTask(taskID string) error {
-	if task, ok := mgr.getTaskFromMap(taskID); ok {
-		var err error
-		manager := task.Client.Manager
-		if manager != nil {
-			if task.Client.HasStarted {
-				err = manager.Stop()
-			} else {
-				// hack import not support stop before start()
-				err = errors.New("task has not started, please try later")
-			}
-		} else {
-			err = errors.New("manager is nil, please try later")
-		}
+	return mgr.stopTask(taskID, Stoped, "")
+}
 
-		if err != nil {
-			return fmt.Errorf("stop task failed: %w", err)
+/*
+InterruptTasks stops all the running tasks when the service is shutting down,
+the progress is saved and the tasks are marked `Interrupted`, so that they can be resumed later
+*/
+func (mgr *TaskMgr) InterruptTasks() {
+	// the tasks waiting must not take the slots freed by the tasks stopped
+	GetTaskQueue().Close()
+	mgr.tasks.Range(func(key, value any) bool {
+		taskID := key.(string)
+		task := value.(*Task)
+		if task.TaskInfo.TaskStatus != Processing.String() && task.TaskInfo.TaskStatus != Queued.String() {
+			return true
 		}
-		task.TaskInfo.TaskStatus = Stoped.String()
-		if err := mgr.FinishTask(taskID); err != nil {
-			return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		if err := mgr.stopTask(taskID, Interrupted, interruptedMessage); err != nil {
+			logx.Errorf("[task %s] interrupt task error: %s", taskID, err)
+			// keep the status so that the task is not taken as finished
+			task.TaskInfo.TaskStatus = Interrupted.String()
+			task.TaskInfo.TaskMessage = interruptedMessage
+			mgr.AbortTask(taskID)
 		}
-		return nil
+		return true
+	})
+}
+
+func (mgr *TaskMgr) stopTask(taskID string, status TaskStatus, message string) error {
+	task, ok := mgr.getTaskFromMap(taskID)
+	if !ok {
+		return errors.New("task is finished or not exist")
+	}
+	if GetTaskQueue().Remove(taskID) {
+		return mgr.cancelQueuedTask(task, status, message)
+	}
+	// the status is changed before stopping, otherwise the task may be taken as finished when the import returns
+	prevStatus := task.TaskInfo.TaskStatus
+	task.TaskInfo.TaskStatus = status.String()
+	var err error
+	manager := task.Client.Manager
+	if task.Runner != nil {
+		err = task.Runner.Stop()
+	} else if manager != nil {
+		if task.Client.HasStarted {
+			err = manager.Stop()
+		} else {
+			// hack import not support stop before start()
+			err = errors.New("task has not started, please try later")
+		}
+	} else {
+		err = errors.New("manager is nil, please try later")
+	}
+
+	if err != nil {
+		task.TaskInfo.TaskStatus = prevStatus
+		return fmt.Errorf("stop task failed: %w", err)
+	}
+	task.TaskInfo.TaskMessage = message
+	if err := mgr.FinishTask(taskID); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return nil
+}
+
+// cancelQueuedTask ends the task which is still waiting in the queue, nothing has been run for it
+func (mgr *TaskMgr) cancelQueuedTask(task *Task, status TaskStatus, message string) error {
+	if d, ok := task.Runner.(discarder); ok {
+		d.Discard()
 	}
-	return errors.New("task is finished or not exist")
+	task.TaskInfo.TaskStatus = status.String()
+	task.TaskInfo.TaskMessage = message
+	if err := mgr.db.UpdateTaskStatus(task.TaskInfo.BID, task.TaskInfo.TaskStatus, message); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	mgr.tasks.Delete(task.TaskInfo.BID)
+	publishTask(task.TaskInfo, true)
+	return nil
 }
 
 func (mgr *TaskMgr) getTaskFromMap(taskID string) (*Task, bool) {
@@ -364,24 +529,33 @@ const (
 	NotExisted
 	Aborted
 	Draft
+	Interrupted
+	Queued
 )
 
+// interruptedMessage is the message of the tasks interrupted by the shutdown or the crash of the service
+const interruptedMessage = "Interrupted by the restart of the service, the task can be resumed"
+
 var taskStatusMap = map[TaskStatus]string{
-	Finished:   "Success",
-	Stoped:     "Stopped",
-	Processing: "Running",
-	NotExisted: "NotExisted",
-	Aborted:    "Failed",
-	Draft:      "Draft",
+	Finished:    "Success",
+	Stoped:      "Stopped",
+	Processing:  "Running",
+	NotExisted:  "NotExisted",
+	Aborted:     "Failed",
+	Draft:       "Draft",
+	Interrupted: "Interrupted",
+	Queued:      "Queued",
 }
 
 var taskStatusRevMap = map[string]TaskStatus{
-	"finished":   Finished,
-	"stoped":     Stoped,
-	"processing": Processing,
-	"notExisted": NotExisted,
-	"aborted":    Aborted,
-	"draft":      Draft,
+	"finished":    Finished,
+	"stoped":      Stoped,
+	"processing":  Processing,
+	"notExisted":  NotExisted,
+	"aborted":     Aborted,
+	"draft":       Draft,
+	"interrupted": Interrupted,
+	"queued":      Queued,
 }
 
 func NewTaskStatus(status string) TaskStatus {
@@ -397,3 +571,30 @@ func (status TaskStatus) String() string {
 	}
 	return "statusUnknown"
 }
+
+// notifyTask sends the final status and stats of the task to the webhooks
+func notifyTask(info *db.TaskInfo) {
+	stats := info.Stats
+	webhook.Notify(webhook.KindTask, &webhook.Event{
+		Address:  info.Address,
+		User:     info.User,
+		TaskID:   info.BID,
+		TaskType: info.TaskType,
+		Name:     info.Name,
+		Space:    info.Space,
+		Status:   info.TaskStatus,
+		Message:  info.TaskMessage,
+		Stats: types.ImportTaskStats{
+			TotalBytes:      stats.TotalBytes,
+			ProcessedBytes:  stats.ProcessedBytes,
+			FailedRecords:   stats.FailedRecords,
+			TotalRecords:    stats.TotalRecords,
+			TotalRequest:    stats.TotalRequest,
+			FailedRequest:   stats.FailedRequest,
+			TotalLatency:    int64(stats.TotalLatency),
+			TotalRespTime:   int64(stats.TotalRespTime),
+			FailedProcessed: stats.FailedProcessed,
+			TotalProcessed:  stats.TotalProcessed,
+		},
+	})
+}
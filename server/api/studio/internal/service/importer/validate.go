@@ -0,0 +1,323 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+)
+
+// maxRowIssues limits the issues reported for the sampled rows of one source
+const maxRowIssues = 100
+
+var (
+	fixedStringRegex = regexp.MustCompile(`(?i)^fixed_string\((\d+)\)$`)
+
+	dateLayouts     = []string{"2006-01-02"}
+	timeLayouts     = []string{"15:04:05", "15:04:05.999999"}
+	datetimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04:05.999999", "2006-01-02 15:04:05", "2006-01-02 15:04:05.999999"}
+)
+
+/*
+ImportValidator checks the import config before the task is created:
+  - the tags, edges and props exist in the space with the compatible types
+  - the indices of the mappings are within the columns of the source
+  - the sampled rows can be converted to the types of the props and the vid type of the space
+*/
+type ImportValidator struct {
+	// Schema is nil if the space can not be described, then only the rows are checked against the config
+	Schema *SpaceSchema
+	Issues []types.ImportValidationIssue
+}
+
+// columnRule is how one field, e.g. the vid or a prop, is read from the row and checked
+type columnRule struct {
+	item    string
+	field   string
+	indices []int64
+	// value returns the value of the field in the row, and whether it is null
+	value func(row []string) (string, bool)
+	check func(value string) error
+}
+
+func NewImportValidator(schema *SpaceSchema) *ImportValidator {
+	return &ImportValidator{Schema: schema}
+}
+
+func (v *ImportValidator) Valid() bool {
+	return len(v.Issues) == 0
+}
+
+func (v *ImportValidator) addIssue(source, row int, item, field, format string, args ...interface{}) {
+	v.Issues = append(v.Issues, types.ImportValidationIssue{
+		Source:  source,
+		Row:     row,
+		Item:    item,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// AddSourceIssue reports the issue of the source which is not about its mappings, e.g. the file can not be read
+func (v *ImportValidator) AddSourceIssue(source int, format string, args ...interface{}) {
+	v.addIssue(source, 0, "", "", format, args...)
+}
+
+/*
+CheckSource checks the mappings of the source against the schema, and the sampled rows against the mappings,
+the first row is the header if the source has one, firstRow is its row number in the file
+*/
+func (v *ImportValidator) CheckSource(index int, source *types.Source, rows [][]string, firstRow int) *types.ImportSourceValidation {
+	result := &types.ImportSourceValidation{Index: index}
+	rules := v.sourceRules(index, source)
+
+	if len(rows) == 0 {
+		return result
+	}
+	// the column count of the source is taken from the first row, which is the header if there is one
+	columns := len(rows[0])
+	rowRules := make([]*columnRule, 0, len(rules))
+	for _, rule := range rules {
+		inRange := true
+		for _, i := range rule.indices {
+			if i < 0 || i >= int64(columns) {
+				v.addIssue(index, 0, rule.item, rule.field, "index %d is out of the %d columns", i, columns)
+				inRange = false
+			}
+		}
+		// the field out of the columns is reported once, instead of in every row
+		if inRange {
+			rowRules = append(rowRules, rule)
+		}
+	}
+	if source.CSV.WithHeader != nil && *source.CSV.WithHeader {
+		rows = rows[1:]
+		firstRow++
+	}
+
+	rowIssues := 0
+	for n, row := range rows {
+		result.SampledRows++
+		failed := false
+		for _, rule := range rowRules {
+			err := rule.checkRow(row)
+			if err == nil {
+				continue
+			}
+			failed = true
+			if rowIssues < maxRowIssues {
+				v.addIssue(index, firstRow+n, rule.item, rule.field, "%s", err)
+			}
+			rowIssues++
+		}
+		if failed {
+			result.FailedRows++
+		}
+	}
+	if rowIssues > maxRowIssues {
+		v.AddSourceIssue(index, "%d more issues of the sampled rows are omitted", rowIssues-maxRowIssues)
+	}
+	return result
+}
+
+func (r *columnRule) checkRow(row []string) error {
+	for _, i := range r.indices {
+		if i < 0 || i >= int64(len(row)) {
+			return fmt.Errorf("the row has %d columns, index %d is out of range", len(row), i)
+		}
+	}
+	value, isNull := r.value(row)
+	if isNull {
+		return nil
+	}
+	return r.check(value)
+}
+
+// sourceRules checks the tags and edges of the source against the schema and returns the rules to check the rows
+func (v *ImportValidator) sourceRules(index int, source *types.Source) []*columnRule {
+	var rules []*columnRule
+	for i := range source.Tags {
+		tag := &source.Tags[i]
+		item := "tag " + tag.Name
+		var schemaItem *SchemaItem
+		if v.Schema != nil {
+			var ok bool
+			if schemaItem, ok = v.Schema.FindTag(tag.Name); !ok {
+				v.addIssue(index, 0, item, "", "tag %s does not exist in space %s", tag.Name, v.Schema.Space)
+			}
+		}
+		rules = append(rules, v.vidRule(index, item, "vid", &tag.ID))
+		rules = append(rules, v.propRules(index, item, schemaItem, tag.Props)...)
+	}
+	for i := range source.Edges {
+		edge := &source.Edges[i]
+		item := "edge " + edge.Name
+		var schemaItem *SchemaItem
+		if v.Schema != nil {
+			var ok bool
+			if schemaItem, ok = v.Schema.FindEdge(edge.Name); !ok {
+				v.addIssue(index, 0, item, "", "edge %s does not exist in space %s", edge.Name, v.Schema.Space)
+			}
+		}
+		rules = append(rules, v.vidRule(index, item, "src", &edge.Src.ID), v.vidRule(index, item, "dst", &edge.Dst.ID))
+		if edge.Rank != nil && edge.Rank.Index != nil {
+			rankIndex := *edge.Rank.Index
+			rules = append(rules, &columnRule{
+				item:    item,
+				field:   "rank",
+				indices: []int64{rankIndex},
+				value: func(row []string) (string, bool) {
+					return row[rankIndex], false
+				},
+				check: checkValue("INT"),
+			})
+		}
+		rules = append(rules, v.propRules(index, item, schemaItem, edge.Props)...)
+	}
+	return rules
+}
+
+func (v *ImportValidator) vidRule(index int, item, field string, id *types.NodeId) *columnRule {
+	rule := &columnRule{item: item, field: field}
+	if len(id.ConcatItems) > 0 {
+		for _, c := range id.ConcatItems {
+			if i, ok := c.(float64); ok {
+				rule.indices = append(rule.indices, int64(i))
+			}
+		}
+		rule.value = func(row []string) (string, bool) {
+			var sb strings.Builder
+			for _, c := range id.ConcatItems {
+				switch c := c.(type) {
+				case float64:
+					sb.WriteString(row[int(c)])
+				default:
+					sb.WriteString(fmt.Sprint(c))
+				}
+			}
+			return sb.String(), false
+		}
+	} else {
+		rule.indices = []int64{id.Index}
+		rule.value = func(row []string) (string, bool) {
+			return row[id.Index], false
+		}
+	}
+
+	if strings.EqualFold(id.Function, "hash") {
+		// any string is hashed to the int vid
+		rule.check = func(string) error { return nil }
+		return rule
+	}
+	if v.Schema == nil {
+		rule.check = checkValue(strings.ToUpper(id.Type))
+		return rule
+	}
+	if v.Schema.IsIntVid() {
+		if !strings.EqualFold(id.Type, "int") {
+			v.addIssue(index, 0, item, field, "the vid type of space %s is %s, but %s is given", v.Schema.Space, v.Schema.VidType, id.Type)
+		}
+		rule.check = checkValue("INT")
+		return rule
+	}
+	if !strings.EqualFold(id.Type, "string") {
+		v.addIssue(index, 0, item, field, "the vid type of space %s is %s, but %s is given", v.Schema.Space, v.Schema.VidType, id.Type)
+	}
+	maxLen := 0
+	if m := fixedStringRegex.FindStringSubmatch(v.Schema.VidType); m != nil {
+		maxLen, _ = strconv.Atoi(m[1])
+	}
+	rule.check = func(value string) error {
+		if maxLen > 0 && len(value) > maxLen {
+			return fmt.Errorf("the vid %q is longer than %d bytes of %s", value, maxLen, v.Schema.VidType)
+		}
+		return nil
+	}
+	return rule
+}
+
+func (v *ImportValidator) propRules(index int, item string, schemaItem *SchemaItem, props []types.Prop) []*columnRule {
+	rules := make([]*columnRule, 0, len(props))
+	for i := range props {
+		prop := &props[i]
+		typ := strings.ToUpper(prop.Type)
+		if schemaItem != nil {
+			if schemaProp, ok := schemaItem.FindProp(prop.Name); !ok {
+				v.addIssue(index, 0, item, prop.Name, "prop %s does not exist", prop.Name)
+			} else if schemaType := schemaProp.ImporterValueType(); !compatibleType(typ, schemaType) {
+				v.addIssue(index, 0, item, prop.Name, "type %s is not compatible with %s in the schema", prop.Type, schemaProp.Type)
+			} else if prop.Nullable && !schemaProp.Nullable {
+				v.addIssue(index, 0, item, prop.Name, "prop %s is not nullable in the schema", prop.Name)
+			}
+		}
+		indices := append([]int64{prop.Index}, prop.AlternativeIndices...)
+		rules = append(rules, &columnRule{
+			item:    item,
+			field:   prop.Name,
+			indices: indices,
+			value: func(row []string) (string, bool) {
+				for _, i := range indices {
+					if prop.Nullable && row[i] == prop.NullValue {
+						continue
+					}
+					return row[i], false
+				}
+				return "", true
+			},
+			check: checkValue(typ),
+		})
+	}
+	return rules
+}
+
+func compatibleType(typ, schemaType string) bool {
+	if typ == schemaType {
+		return true
+	}
+	// e.g. geography and geography(point)
+	return strings.HasPrefix(schemaType, typ+"(")
+}
+
+// checkValue returns the check of the value converted to the type in the nebula-importer config
+func checkValue(typ string) func(value string) error {
+	return func(value string) error {
+		var err error
+		switch typ {
+		case "INT":
+			_, err = strconv.ParseInt(value, 0, 64)
+		case "FLOAT", "DOUBLE":
+			_, err = strconv.ParseFloat(value, 64)
+		case "BOOL":
+			_, err = strconv.ParseBool(value)
+		case "DATE":
+			err = parseTime(value, dateLayouts)
+		case "TIME":
+			err = parseTime(value, timeLayouts)
+		case "DATETIME":
+			err = parseTime(value, datetimeLayouts)
+		case "TIMESTAMP":
+			if _, intErr := strconv.ParseInt(value, 0, 64); intErr != nil {
+				err = parseTime(value, datetimeLayouts)
+			}
+		default:
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%q can not be converted to %s", value, strings.ToLower(typ))
+		}
+		return nil
+	}
+}
+
+func parseTime(value string, layouts []string) error {
+	var err error
+	for _, layout := range layouts {
+		if _, err = time.Parse(layout, value); err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vesoft-inc/go-pkg/middleware"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ngql"
+)
+
+// maxFailedLineSize limits the line read from the error log
+const maxFailedLineSize = 1024 * 1024
+
+/*
+failedFile is a file in the err dir of the task:
+  - source-i.csv keeps the failed records of the i-th source of the csv import task, with the delimiter of the source
+  - failed.ngql keeps the failed statements of the nGQL script task, and error.log keeps their errors
+*/
+type failedFile struct {
+	name   string
+	path   string
+	source int
+	comma  rune
+}
+
+// GetImportTaskFailedFiles lists the files keeping the failed records of the task, with the count of the records in each file
+func (i *importService) GetImportTaskFailedFiles(req *types.GetImportTaskFailedFilesRequest) (*types.GetImportTaskFailedFilesData, error) {
+	taskInfo, err := i.findTask(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	files, err := i.failedFiles(taskInfo)
+	if err != nil {
+		return nil, err
+	}
+	data := &types.GetImportTaskFailedFilesData{List: []types.ImportTaskFailedFile{}}
+	for _, f := range files {
+		info, err := os.Stat(f.path)
+		if err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		records, err := f.readRecords(0, 0, nil)
+		if err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "read the failed records failed")
+		}
+		data.List = append(data.List, types.ImportTaskFailedFile{
+			Name:    f.name,
+			Source:  f.source,
+			Size:    info.Size(),
+			Records: records,
+		})
+	}
+	return data, nil
+}
+
+// PreviewImportTaskFailedFile reads one page of the failed records in the file
+func (i *importService) PreviewImportTaskFailedFile(req *types.PreviewImportTaskFailedFileRequest) (*types.PreviewImportTaskFailedFileData, error) {
+	taskInfo, err := i.findTask(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := i.findFailedFile(taskInfo, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	data := &types.PreviewImportTaskFailedFileData{Records: [][]string{}}
+	start := int64(req.Page-1) * int64(req.PageSize)
+	data.Total, err = f.readRecords(start, start+int64(req.PageSize), func(record []string) {
+		data.Records = append(data.Records, record)
+	})
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err, "read the failed records failed")
+	}
+	return data, nil
+}
+
+// DownloadImportTaskFailedFile writes the file of the failed records into the response
+func (i *importService) DownloadImportTaskFailedFile(req *types.DownloadImportTaskFailedFileRequest) error {
+	taskInfo, err := i.findTask(req.Id)
+	if err != nil {
+		return err
+	}
+	f, err := i.findFailedFile(taskInfo, req.Name)
+	if err != nil {
+		return err
+	}
+	httpResp, ok := middleware.GetResponseWriter(i.ctx)
+	if !ok {
+		return ecode.WithInternalServer(fmt.Errorf("unset KeepResponse Writer"))
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return ecode.WithInternalServer(err)
+	}
+	defer file.Close()
+	httpResp.Header().Set("Content-Type", "application/octet-stream")
+	httpResp.Header().Set("Content-Disposition", "attachment;filename="+f.name)
+	httpResp.WriteHeader(http.StatusOK)
+	io.Copy(httpResp, file)
+	return nil
+}
+
+// DownloadImportTaskFailedFiles writes all the files of the failed records into the response as a zip
+func (i *importService) DownloadImportTaskFailedFiles(req *types.DownloadImportTaskFailedFilesRequest) error {
+	taskInfo, err := i.findTask(req.Id)
+	if err != nil {
+		return err
+	}
+	files, err := i.failedFiles(taskInfo)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the task has no failed records"))
+	}
+	httpResp, ok := middleware.GetResponseWriter(i.ctx)
+	if !ok {
+		return ecode.WithInternalServer(fmt.Errorf("unset KeepResponse Writer"))
+	}
+
+	httpResp.Header().Set("Content-Type", "application/zip")
+	httpResp.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=failed_%s.zip", req.Id))
+	httpResp.WriteHeader(http.StatusOK)
+	zw := zip.NewWriter(httpResp)
+	defer zw.Close()
+	for _, f := range files {
+		if err := addFileToZip(zw, f.path); err != nil {
+			// the header has been written, so the error can only be logged
+			i.Logger.Errorf("zip the failed records file %s error: %s", f.name, err)
+			return nil
+		}
+	}
+	return nil
+}
+
+/*
+ReimportImportTaskFailedFiles imports the failed records of the task again in a new task,
+only the given files are imported if any, the new task is linked to the task as a retry
+  - the csv import task imports the failed records with the same mappings
+  - the nGQL script task runs the failed statements as the script
+*/
+func (i *importService) ReimportImportTaskFailedFiles(req *types.ReimportImportTaskFailedFilesRequest) (*types.CreateImportTaskData, error) {
+	taskInfo, err := i.findTask(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if taskInfo.TaskType == db.TaskTypeImport {
+		return i.retryImportTask(taskInfo, req.Files)
+	}
+
+	switch taskInfo.TaskStatus {
+	case importer.Finished.String(), importer.Stoped.String(), importer.Aborted.String(), importer.Interrupted.String():
+	default:
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the task in %s status can not be reimported", taskInfo.TaskStatus))
+	}
+	if len(req.Files) > 0 && (len(req.Files) > 1 || req.Files[0] != importer.NGQLFailedStmtsName) {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("only %s can be reimported for the nGQL script task", importer.NGQLFailedStmtsName))
+	}
+	if _, err := i.findFailedFile(taskInfo, importer.NGQLFailedStmtsName); err != nil {
+		return nil, err
+	}
+	cfg := &importer.NGQLConfig{}
+	if err := json.Unmarshal([]byte(taskInfo.RawConfig), cfg); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	data, err := i.createNGQLImportTask(&types.CreateNGQLImportTaskRequest{
+		Name:     taskInfo.Name,
+		Space:    cfg.Space,
+		Batch:    cfg.Batch,
+		Priority: taskInfo.Priority,
+	}, taskInfo.BID)
+	if err != nil {
+		return nil, err
+	}
+	if err := importer.GetTaskMgr().SetTaskParent(data.Id, taskInfo.BID, importer.RunTypeRetry); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return data, nil
+}
+
+func (i *importService) findTask(taskID string) (*db.TaskInfo, error) {
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	return importer.FindImportTask(taskID, host, auth.Username)
+}
+
+// failedFiles returns the files in the err dir of the task, the files of the sources are in the order of the sources
+func (i *importService) failedFiles(taskInfo *db.TaskInfo) ([]*failedFile, error) {
+	errDir := filepath.Join(i.svcCtx.Config.File.TasksDir, taskInfo.BID, errContentDir)
+	entries, err := os.ReadDir(errDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	var sources []*types.Source
+	if taskInfo.TaskType == db.TaskTypeImport {
+		config, err := i.getImportConfig(taskInfo.BID)
+		if err != nil {
+			return nil, err
+		}
+		var cfg types.ImportTaskConfig
+		if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		sources = cfg.Sources
+	}
+
+	files := make([]*failedFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		f := &failedFile{
+			name:   entry.Name(),
+			path:   filepath.Join(errDir, entry.Name()),
+			source: -1,
+			comma:  ',',
+		}
+		if idx, ok := importer.FailedRecordsSource(f.name); ok && idx < len(sources) {
+			f.source = idx
+			if delimiter := sources[idx].CSV.Delimiter; delimiter != nil {
+				if chars := []rune(*delimiter); len(chars) > 0 {
+					f.comma = chars[0]
+				}
+			}
+		}
+		files = append(files, f)
+	}
+	sort.SliceStable(files, func(a, b int) bool {
+		if files[a].source != files[b].source {
+			// the files which are not of a source are listed last
+			return files[b].source == -1 || (files[a].source != -1 && files[a].source < files[b].source)
+		}
+		return files[a].name < files[b].name
+	})
+	return files, nil
+}
+
+func (i *importService) findFailedFile(taskInfo *db.TaskInfo, name string) (*failedFile, error) {
+	files, err := i.failedFiles(taskInfo)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.name == name {
+			return f, nil
+		}
+	}
+	return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the failed records file %s does not exist", name))
+}
+
+/*
+readRecords counts the records in the file, and passes the records in [start, end) to fn,
+the record of the csv file is a row, of the nGQL file is a statement, and of the other files is a line
+*/
+func (f *failedFile) readRecords(start, end int64, fn func(record []string)) (int64, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var next func() ([]string, error)
+	switch filepath.Ext(f.name) {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.Comma = f.comma
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		next = reader.Read
+	case ".ngql":
+		scanner := ngql.NewScanner(file)
+		next = func() ([]string, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			return []string{scanner.Text()}, nil
+		}
+	default:
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxFailedLineSize)
+		next = func() ([]string, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			return []string{strings.TrimSuffix(scanner.Text(), "\r")}, nil
+		}
+	}
+
+	var count int64
+	for {
+		record, err := next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		if fn != nil && count >= start && count < end {
+			fn(record)
+		}
+		count++
+	}
+}
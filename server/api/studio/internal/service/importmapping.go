@@ -0,0 +1,140 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+)
+
+// mappingSamples is the number of the sampled values returned for each column
+const mappingSamples = 3
+
+/*
+SuggestImportMapping reads the header and the first rows of the file, infers the types of the columns,
+and maps the columns to the tags or the edges of the space as a draft config,
+the draft is saved as a task draft if the draft name is given
+*/
+func (i *importService) SuggestImportMapping(req *types.SuggestImportMappingRequest) (*types.SuggestImportMappingData, error) {
+	withHeader := true
+	if req.WithHeader != nil {
+		withHeader = *req.WithHeader
+	}
+	source := &types.Source{
+		CSV: types.ImportTaskCSV{
+			WithHeader: &withHeader,
+			Delimiter:  req.Delimiter,
+		},
+		Path:               req.File,
+		DatasourceId:       req.DatasourceId,
+		DatasourceFilePath: req.DatasourceFilePath,
+	}
+	fileName := req.File
+	if req.DatasourceId != nil {
+		if req.DatasourceFilePath == nil || *req.DatasourceFilePath == "" {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("datasourceFilePath is required"))
+		}
+		fileName = *req.DatasourceFilePath
+		source.Path = ""
+	} else if req.File == "" {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("file or datasourceId is required"))
+	}
+
+	// the source with the datasource resolved is only used to read the file, the draft keeps the datasource id
+	sampled := *source
+	if err := i.resolveSource(&sampled); err != nil {
+		return nil, err
+	}
+	sampleRows := req.SampleRows
+	if sampleRows == 0 {
+		sampleRows = defaultValidateSampleRows
+	}
+	rows, err := i.sampleSource(&sampled, sampleRows)
+	if err != nil && len(rows) == 0 {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "read the file failed")
+	}
+	if len(rows) == 0 {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the file is empty"))
+	}
+
+	columns := suggestColumns(rows, withHeader)
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	schema, err := importer.DescribeSpace(auth.NSID, req.Space)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "describe space failed")
+	}
+	source.Tags, source.Edges = importer.SuggestMappings(schema, columns, fileName)
+
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	data := &types.SuggestImportMappingData{
+		Columns: columns,
+		Config: types.ImportTaskConfig{
+			Client: types.Client{
+				Version: "v3",
+				Address: host,
+				User:    auth.Username,
+			},
+			Manager: types.Manager{
+				SpaceName: req.Space,
+			},
+			Sources: []*types.Source{source},
+		},
+	}
+	if req.DraftName != "" {
+		rawConfig, err := json.Marshal(data.Config)
+		if err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		id := i.svcCtx.IDGenerator.Generate()
+		if err := importer.GetTaskMgr().NewTaskDraft(id, host, auth.Username, req.DraftName, req.Space, string(rawConfig)); err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		data.DraftId = id
+	}
+	return data, nil
+}
+
+// suggestColumns takes the names of the columns from the header, and infers their types from the rows
+func suggestColumns(rows [][]string, withHeader bool) []types.ImportColumnSuggestion {
+	var header []string
+	if withHeader {
+		header, rows = rows[0], rows[1:]
+	}
+	count := len(header)
+	for _, row := range rows {
+		if len(row) > count {
+			count = len(row)
+		}
+	}
+
+	columns := make([]types.ImportColumnSuggestion, 0, count)
+	for idx := 0; idx < count; idx++ {
+		column := types.ImportColumnSuggestion{
+			Index:   int64(idx),
+			Name:    importer.ColumnName(idx),
+			Samples: []string{},
+		}
+		if idx < len(header) && header[idx] != "" {
+			column.Name = header[idx]
+		}
+		values := make([]string, 0, len(rows))
+		for _, row := range rows {
+			if idx < len(row) {
+				values = append(values, row[idx])
+			}
+		}
+		column.Type = importer.InferColumnType(values)
+		for _, value := range values {
+			if len(column.Samples) == mappingSamples {
+				break
+			}
+			column.Samples = append(column.Samples, value)
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
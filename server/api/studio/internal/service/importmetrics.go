@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+)
+
+// GetImportTaskMetrics returns the rows and bytes per second, the latency and the failure rate of the task over its lifetime
+func (i *importService) GetImportTaskMetrics(req *types.GetImportTaskMetricsRequest) (*types.GetImportTaskMetricsData, error) {
+	taskInfo, err := i.findTask(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return importer.GetTaskMetrics(taskInfo)
+}
+
+// GetImportTaskReport compares the performance of the finished task with the previous runs of the same config
+func (i *importService) GetImportTaskReport(req *types.GetImportTaskReportRequest) (*types.GetImportTaskReportData, error) {
+	taskInfo, err := i.findTask(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return importer.GetTaskReport(taskInfo, req.Limit)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+/*
+ResumeInterruptedTasks resumes the tasks interrupted by the last restart of the service,
+each task is resumed with the password of the user who runs it, it is called on startup when Import.AutoResume is enabled
+*/
+func ResumeInterruptedTasks(svcCtx *svc.ServiceContext) {
+	tasks, err := importer.FindInterruptedTasks()
+	if err != nil {
+		logx.Errorf("find the interrupted tasks error: %s", err)
+		return
+	}
+	for _, taskInfo := range tasks {
+		if err := resumeInterruptedTask(svcCtx, taskInfo); err != nil {
+			logx.Errorf("[task %s] resume the interrupted task error: %s", taskInfo.BID, err)
+			continue
+		}
+		logx.Infof("[task %s] the interrupted task is resumed", taskInfo.BID)
+	}
+}
+
+func resumeInterruptedTask(svcCtx *svc.ServiceContext, taskInfo *db.TaskInfo) error {
+	secret, err := importer.FindTaskSecret(taskInfo.BID)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return errors.New("the password of the user is not kept, please resume the task manually")
+	}
+	ctx, err := newUserContext(taskInfo.Address, taskInfo.User, secret)
+	if err != nil {
+		return err
+	}
+	switch taskInfo.TaskType {
+	case db.TaskTypeExport:
+		return NewExportService(ctx, svcCtx).ResumeExportTask(&types.ResumeExportTaskRequest{Id: taskInfo.BID})
+	case db.TaskTypeStage:
+		return NewStageService(ctx, svcCtx).ResumeStageTask(&types.ResumeStageTaskRequest{Id: taskInfo.BID})
+	case db.TaskTypeImport, db.TaskTypeNGQL:
+		_, err := NewImportService(ctx, svcCtx).ResumeImportTask(&types.ResumeImportTaskRequest{Id: taskInfo.BID})
+		return err
+	default:
+		return fmt.Errorf("the %s task can not be resumed", taskInfo.TaskType)
+	}
+}
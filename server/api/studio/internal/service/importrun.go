@@ -0,0 +1,214 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+)
+
+// importRun continues an existing csv import task, by resuming it from the checkpoints or retrying its failed records
+type importRun struct {
+	parent  *db.TaskInfo
+	runType string
+	resume  map[string]*db.TaskCheckpoint
+	base    db.Stats
+}
+
+/*
+ResumeImportTask continues a stopped, failed or interrupted task from where it stopped,
+  - the nGQL script task is continued in place
+  - a new run is created for the csv import task, which skips the bytes imported of each source
+*/
+func (i *importService) ResumeImportTask(req *types.ResumeImportTaskRequest) (*types.CreateImportTaskData, error) {
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	taskInfo, err := importer.FindImportTask(req.Id, host, auth.Username)
+	if err != nil {
+		return nil, err
+	}
+	switch taskInfo.TaskStatus {
+	case importer.Stoped.String(), importer.Aborted.String(), importer.Interrupted.String():
+	default:
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the task in %s status can not be resumed", taskInfo.TaskStatus))
+	}
+	if taskInfo.TaskType == db.TaskTypeNGQL {
+		return &types.CreateImportTaskData{Id: taskInfo.BID}, i.startNGQLTask(taskInfo, false)
+	}
+
+	var resumed db.TaskInfo
+	if err := db.CtxDB.Select("b_id").Where("parent_task_id = ? AND run_type = ?", taskInfo.BID, importer.RunTypeResume).Limit(1).Find(&resumed).Error; err != nil {
+		return nil, i.gormErrorWrapper(err)
+	}
+	if resumed.BID != "" {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the task has been resumed by the task %s", resumed.BID))
+	}
+	checkpoints, err := importer.FindTaskCheckpoints(taskInfo.BID)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	if len(checkpoints) == 0 {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the task has no checkpoint to resume from"))
+	}
+	run := &importRun{
+		parent:  taskInfo,
+		runType: importer.RunTypeResume,
+		resume:  map[string]*db.TaskCheckpoint{},
+		// the requests are counted by the task, while the records are counted up to the checkpoints
+		base: db.Stats{
+			TotalProcessed:  taskInfo.Stats.TotalProcessed,
+			FailedProcessed: taskInfo.Stats.FailedProcessed,
+			TotalRequest:    taskInfo.Stats.TotalRequest,
+			FailedRequest:   taskInfo.Stats.FailedRequest,
+			TotalLatency:    taskInfo.Stats.TotalLatency,
+			TotalRespTime:   taskInfo.Stats.TotalRespTime,
+		},
+	}
+	for _, cp := range checkpoints {
+		run.resume[cp.Name] = cp
+		run.base.ProcessedBytes += cp.Offset
+		run.base.TotalBytes += cp.Offset
+		run.base.TotalRecords += cp.Records
+		run.base.FailedRecords += cp.Failed
+	}
+	config, err := i.getImportConfig(taskInfo.BID)
+	if err != nil {
+		return nil, err
+	}
+	return i.createImportTask(&types.CreateImportTaskRequest{
+		Name:      taskInfo.Name,
+		Config:    config,
+		RawConfig: taskInfo.RawConfig,
+		Priority:  taskInfo.Priority,
+	}, run)
+}
+
+// RetryImportTask imports the failed records of the task again in a new run
+func (i *importService) RetryImportTask(req *types.RetryImportTaskRequest) (*types.CreateImportTaskData, error) {
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	taskInfo, err := importer.FindImportTask(req.Id, host, auth.Username)
+	if err != nil {
+		return nil, err
+	}
+	return i.retryImportTask(taskInfo, nil)
+}
+
+// retryImportTask imports the failed records of the task, only the records in the given files are imported if any
+func (i *importService) retryImportTask(taskInfo *db.TaskInfo, files []string) (*types.CreateImportTaskData, error) {
+	if taskInfo.TaskType != db.TaskTypeImport {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("only the csv import task can be retried"))
+	}
+	switch taskInfo.TaskStatus {
+	case importer.Finished.String(), importer.Stoped.String(), importer.Aborted.String(), importer.Interrupted.String():
+	default:
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the task in %s status can not be retried", taskInfo.TaskStatus))
+	}
+
+	config, err := i.getImportConfig(taskInfo.BID)
+	if err != nil {
+		return nil, err
+	}
+	var cfg types.ImportTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	sources, err := i.failedSources(taskInfo, cfg.Sources, files)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the task has no failed records to retry"))
+	}
+	cfg.Sources = sources
+	retryConfig, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return i.createImportTask(&types.CreateImportTaskRequest{
+		Name:      taskInfo.Name,
+		Config:    string(retryConfig),
+		RawConfig: taskInfo.RawConfig,
+		Priority:  taskInfo.Priority,
+	}, &importRun{
+		parent:  taskInfo,
+		runType: importer.RunTypeRetry,
+		base:    taskInfo.Stats,
+	})
+}
+
+/*
+failedSources builds the sources which import the failed records of the task with the same mappings,
+the records failed before the checkpoints are kept by the tasks resumed, so they are retried too,
+the files of the failed records are filtered by their names if the files are given
+*/
+func (i *importService) failedSources(taskInfo *db.TaskInfo, sources []*types.Source, files []string) ([]*types.Source, error) {
+	tasksDir, err := filepath.Abs(i.svcCtx.Config.File.TasksDir)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	withHeader := false
+	var failed []*types.Source
+	for t := taskInfo; t != nil; {
+		for idx, source := range sources {
+			path := importer.FailedRecordsPath(filepath.Join(tasksDir, t.BID), idx)
+			if len(files) > 0 && !utils.Contains(files, filepath.Base(path)) {
+				continue
+			}
+			if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+				continue
+			}
+			retry := *source
+			retry.CSV = types.ImportTaskCSV{
+				WithHeader: &withHeader,
+				LazyQuotes: source.CSV.LazyQuotes,
+				Delimiter:  source.CSV.Delimiter,
+			}
+			retry.Path = path
+			if (retry.Format != nil && *retry.Format != importer.SourceFormatCSV) || retry.SQL != nil {
+				// the failed records of the json, parquet or sql source are kept as the csv converted
+				delimiter := ","
+				retry.CSV.Delimiter = &delimiter
+				retry.Format, retry.JSON, retry.Parquet, retry.SQL = nil, nil, nil, nil
+			}
+			retry.S3, retry.SFTP, retry.OSS, retry.HTTP = nil, nil, nil, nil
+			retry.DatasourceId, retry.DatasourceFilePath = nil, nil
+			failed = append(failed, &retry)
+		}
+		if t.RunType != importer.RunTypeResume {
+			break
+		}
+		parent := new(db.TaskInfo)
+		if err := db.CtxDB.Where("b_id = ?", t.ParentTaskID).First(parent).Error; err != nil {
+			break
+		}
+		t = parent
+	}
+	return failed, nil
+}
+
+// getImportConfig returns the config the task is created with
+func (i *importService) getImportConfig(taskID string) (string, error) {
+	var taskEffect db.TaskEffect
+	if err := db.CtxDB.Select("import_config").Where("task_id = ?", taskID).First(&taskEffect).Error; err != nil {
+		return "", ecode.WithErrorMessage(ecode.ErrInternalDatabase, err)
+	}
+	if taskEffect.ImportConfig == "" {
+		return "", ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the config of the task is not kept, please rerun the task"))
+	}
+	config, err := secrets.Decrypt(taskEffect.ImportConfig)
+	if err != nil {
+		return "", ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/robfig/cron/v3"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ ImportScheduleService = (*importScheduleService)(nil)
+
+const importScheduleInterval = 10 * time.Second
+
+type (
+	ImportScheduleService interface {
+		CreateImportSchedule(*types.CreateImportScheduleRequest) (*types.CreateImportScheduleData, error)
+		UpdateImportSchedule(*types.UpdateImportScheduleRequest) error
+		GetImportSchedule(*types.GetImportScheduleRequest) (*types.ImportScheduleData, error)
+		GetManyImportSchedule(*types.GetManyImportScheduleRequest) (*types.GetManyImportScheduleData, error)
+		DeleteImportSchedule(*types.DeleteImportScheduleRequest) error
+		GetImportScheduleRuns(*types.GetImportScheduleRunsRequest) (*types.GetManyImportTaskData, error)
+	}
+
+	importScheduleService struct {
+		logx.Logger
+		ctx              context.Context
+		svcCtx           *svc.ServiceContext
+		gormErrorWrapper utils.GormErrorWrapper
+	}
+)
+
+func NewImportScheduleService(ctx context.Context, svcCtx *svc.ServiceContext) ImportScheduleService {
+	return &importScheduleService{
+		Logger:           logx.WithContext(ctx),
+		ctx:              ctx,
+		svcCtx:           svcCtx,
+		gormErrorWrapper: utils.GormErrorWithLogger(ctx),
+	}
+}
+
+func (s *importScheduleService) CreateImportSchedule(req *types.CreateImportScheduleRequest) (*types.CreateImportScheduleData, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	source, err := importer.FindImportTask(req.TaskId, host, auth.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	// the csv import config is generated by the client from the raw config, while the nGQL task runs its raw config
+	config := req.Config
+	if source.TaskType == db.TaskTypeNGQL {
+		config = source.RawConfig
+	} else if err := validateImportConfig(config); err != nil {
+		return nil, err
+	}
+	timeZone, nextRunTime, err := parseSchedule(req.Cron, req.TimeZone, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	encryptedConfig, err := secrets.Encrypt(config)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	secret, err := secrets.Encrypt(auth.Password)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	overlapPolicy := req.OverlapPolicy
+	if overlapPolicy == "" {
+		overlapPolicy = db.OverlapPolicySkip
+	}
+
+	schedule := &db.ImportSchedule{
+		BID:           s.svcCtx.IDGenerator.Generate(),
+		Name:          req.Name,
+		Address:       host,
+		User:          auth.Username,
+		Space:         source.Space,
+		TaskType:      source.TaskType,
+		SourceTaskID:  source.BID,
+		RawConfig:     source.RawConfig,
+		Config:        encryptedConfig,
+		Secret:        secret,
+		CronExpr:      req.Cron,
+		TimeZone:      timeZone,
+		OverlapPolicy: overlapPolicy,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+		NextRunTime:   nextRunTime,
+	}
+	if err := db.CtxDB.Create(schedule).Error; err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	return &types.CreateImportScheduleData{Id: schedule.BID}, nil
+}
+
+func (s *importScheduleService) UpdateImportSchedule(req *types.UpdateImportScheduleRequest) error {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	schedule, err := s.findOne(req.Id)
+	if err != nil {
+		return err
+	}
+	timeZone, nextRunTime, err := parseSchedule(req.Cron, req.TimeZone, time.Now())
+	if err != nil {
+		return err
+	}
+	// the password of the current session is kept, in case it is changed since the schedule is created
+	secret, err := secrets.Encrypt(auth.Password)
+	if err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	updates := map[string]interface{}{
+		"name":          req.Name,
+		"cron_expr":     req.Cron,
+		"time_zone":     timeZone,
+		"enabled":       req.Enabled,
+		"next_run_time": nextRunTime,
+		"secret":        secret,
+	}
+	if req.OverlapPolicy != "" {
+		updates["overlap_policy"] = req.OverlapPolicy
+	}
+	if !req.Enabled {
+		updates["queued"] = false
+	}
+	if req.Config != nil && schedule.TaskType != db.TaskTypeNGQL {
+		if err := validateImportConfig(*req.Config); err != nil {
+			return err
+		}
+		encryptedConfig, err := secrets.Encrypt(*req.Config)
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		updates["config"] = encryptedConfig
+	}
+	if err := db.CtxDB.Model(&db.ImportSchedule{}).Where("b_id = ?", schedule.BID).Updates(updates).Error; err != nil {
+		return s.gormErrorWrapper(err)
+	}
+	return nil
+}
+
+func (s *importScheduleService) GetImportSchedule(req *types.GetImportScheduleRequest) (*types.ImportScheduleData, error) {
+	schedule, err := s.findOne(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toImportScheduleData(schedule), nil
+}
+
+func (s *importScheduleService) GetManyImportSchedule(req *types.GetManyImportScheduleRequest) (*types.GetManyImportScheduleData, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	var (
+		schedules []*db.ImportSchedule
+		count     int64
+	)
+	tx := db.CtxDB.Model(&db.ImportSchedule{}).Where("address = ? AND user = ?", host, auth.Username)
+	if req.Space != "" {
+		tx = tx.Where("space = ?", req.Space)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	if err := tx.Order("id desc").Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&schedules).Error; err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	data := &types.GetManyImportScheduleData{
+		Total: count,
+		List:  []types.ImportScheduleData{},
+	}
+	for _, schedule := range schedules {
+		data.List = append(data.List, *toImportScheduleData(schedule))
+	}
+	return data, nil
+}
+
+// DeleteImportSchedule keeps the tasks which have been created by the schedule
+func (s *importScheduleService) DeleteImportSchedule(req *types.DeleteImportScheduleRequest) error {
+	schedule, err := s.findOne(req.Id)
+	if err != nil {
+		return err
+	}
+	if err := db.CtxDB.Delete(&db.ImportSchedule{}, "b_id = ?", schedule.BID).Error; err != nil {
+		return s.gormErrorWrapper(err)
+	}
+	return nil
+}
+
+func (s *importScheduleService) GetImportScheduleRuns(req *types.GetImportScheduleRunsRequest) (*types.GetManyImportTaskData, error) {
+	schedule, err := s.findOne(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := importer.GetScheduleRuns(schedule.BID, req.Page, req.PageSize)
+	if err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	return data, nil
+}
+
+func (s *importScheduleService) findOne(id string) (*db.ImportSchedule, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	schedule := new(db.ImportSchedule)
+	if err := db.CtxDB.Where("b_id = ? AND address = ? AND user = ?", id, host, auth.Username).First(schedule).Error; err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("schedule not existed"))
+	}
+	return schedule, nil
+}
+
+func validateImportConfig(config string) error {
+	if config == "" {
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("config is required"))
+	}
+	var cfg types.ImportTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+	}
+	return nil
+}
+
+// parseSchedule validates the cron expression in the time zone, and returns the time zone and the next run time after now
+func parseSchedule(expr, timeZone string, now time.Time) (string, time.Time, error) {
+	if timeZone == "" {
+		timeZone = time.Local.String()
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return "", time.Time{}, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "invalid time zone")
+	}
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return "", time.Time{}, ecode.WithErrorMessage(ecode.ErrBadRequest, err, "invalid cron expression")
+	}
+	next := sched.Next(now.In(loc))
+	if next.IsZero() {
+		return "", time.Time{}, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("the cron expression never fires"))
+	}
+	// keep the time in the local zone as the other times in db, so that they can be compared
+	return timeZone, next.In(time.Local).Truncate(time.Second), nil
+}
+
+func toImportScheduleData(s *db.ImportSchedule) *types.ImportScheduleData {
+	data := &types.ImportScheduleData{
+		Id:            s.BID,
+		Name:          s.Name,
+		Space:         s.Space,
+		TaskType:      s.TaskType,
+		TaskId:        s.SourceTaskID,
+		RawConfig:     s.RawConfig,
+		Cron:          s.CronExpr,
+		TimeZone:      s.TimeZone,
+		OverlapPolicy: s.OverlapPolicy,
+		Enabled:       s.Enabled,
+		Queued:        s.Queued,
+		NextRunTime:   s.NextRunTime.UnixMilli(),
+		LastTaskId:    s.LastTaskID,
+		LastMessage:   s.LastMessage,
+		CreateTime:    s.CreateTime.UnixMilli(),
+		UpdateTime:    s.UpdateTime.UnixMilli(),
+	}
+	if s.LastRunTime != nil {
+		data.LastRunTime = s.LastRunTime.UnixMilli()
+	}
+	return data
+}
+
+/*
+StartImportScheduler checks the schedules periodically and creates the tasks of the due ones.
+
+The schedules are stored in db, so the runs missed while the service is down are made up once after restart;
+when AppInstance is multi and the instances share the db, every instance runs the scheduler, and a schedule is still fired once,
+see fireImportSchedule.
+*/
+func StartImportScheduler(svcCtx *svc.ServiceContext) {
+	for {
+		fireImportSchedules(svcCtx, time.Now())
+		time.Sleep(importScheduleInterval)
+	}
+}
+
+func fireImportSchedules(svcCtx *svc.ServiceContext, now time.Time) {
+	var schedules []*db.ImportSchedule
+	if err := db.CtxDB.Where("enabled = ? AND (next_run_time <= ? OR queued = ?)", true, now, true).Find(&schedules).Error; err != nil {
+		logx.Errorf("find the import schedules error: %s", err)
+		return
+	}
+	for _, s := range schedules {
+		fireImportSchedule(svcCtx, s, now)
+	}
+}
+
+func fireImportSchedule(svcCtx *svc.ServiceContext, s *db.ImportSchedule, now time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			logx.Errorf("[import schedule %s error]: %+v", s.BID, r)
+		}
+	}()
+	running := s.LastTaskID != "" && isTaskRunning(s.LastTaskID)
+	if !s.NextRunTime.After(now) {
+		_, next, err := parseSchedule(s.CronExpr, s.TimeZone, now)
+		if err != nil {
+			db.CtxDB.Model(&db.ImportSchedule{}).Where("b_id = ?", s.BID).Updates(map[string]interface{}{
+				"enabled":      false,
+				"last_message": err.Error(),
+			})
+			return
+		}
+		queued := running && s.OverlapPolicy == db.OverlapPolicyQueue
+		// the compare-and-set on next_run_time guarantees the run is fired by a single instance when AppInstance is multi,
+		// only the instance which moves the next run time forward from the value it read gets the row affected
+		tx := db.CtxDB.Model(&db.ImportSchedule{}).Where("b_id = ? AND next_run_time = ?", s.BID, s.NextRunTime).
+			Updates(map[string]interface{}{
+				"next_run_time": next,
+				"queued":        queued,
+			})
+		if tx.Error != nil || tx.RowsAffected == 0 {
+			// fired by another instance
+			return
+		}
+		if running {
+			if !queued {
+				updateScheduleMessage(s.BID, fmt.Sprintf("the run at %s is skipped, since the task %s is still running",
+					s.NextRunTime.Format("2006-01-02 15:04:05"), s.LastTaskID))
+			}
+			return
+		}
+	} else {
+		if running {
+			return
+		}
+		// the queued run is claimed by the compare-and-set on queued in the same way
+		tx := db.CtxDB.Model(&db.ImportSchedule{}).Where("b_id = ? AND queued = ?", s.BID, true).Update("queued", false)
+		if tx.Error != nil || tx.RowsAffected == 0 {
+			return
+		}
+	}
+
+	taskID, err := runImportSchedule(svcCtx, s, now)
+	updates := map[string]interface{}{
+		"last_run_time": now,
+		"last_message":  "",
+	}
+	if err != nil {
+		logx.Errorf("run the import schedule %s error: %s", s.BID, err)
+		updates["last_message"] = err.Error()
+	} else {
+		updates["last_task_id"] = taskID
+	}
+	db.CtxDB.Model(&db.ImportSchedule{}).Where("b_id = ?", s.BID).Updates(updates)
+}
+
+// runImportSchedule creates the task as the user who owns the schedule
+func runImportSchedule(svcCtx *svc.ServiceContext, s *db.ImportSchedule, now time.Time) (string, error) {
+	config, err := secrets.Decrypt(s.Config)
+	if err != nil {
+		return "", err
+	}
+	ctx, err := newUserContext(s.Address, s.User, s.Secret)
+	if err != nil {
+		return "", err
+	}
+
+	name := s.Name
+	if loc, err := time.LoadLocation(s.TimeZone); err == nil {
+		name = fmt.Sprintf("%s_%s", s.Name, now.In(loc).Format("20060102150405"))
+	}
+	importService := NewImportService(ctx, svcCtx)
+	var data *types.CreateImportTaskData
+	if s.TaskType == db.TaskTypeNGQL {
+		cfg := &importer.NGQLConfig{}
+		if err := json.Unmarshal([]byte(config), cfg); err != nil {
+			return "", err
+		}
+		req := &types.CreateNGQLImportTaskRequest{
+			Name:  name,
+			Space: cfg.Space,
+			Batch: cfg.Batch,
+		}
+		if cfg.DatasourceId != "" {
+			req.DatasourceId = &cfg.DatasourceId
+			req.DatasourceFilePath = &cfg.FilePath
+		} else {
+			req.File = cfg.FilePath
+		}
+		data, err = importService.CreateNGQLImportTask(req)
+	} else {
+		data, err = importService.CreateImportTask(&types.CreateImportTaskRequest{
+			Name:      name,
+			Config:    config,
+			RawConfig: s.RawConfig,
+		})
+	}
+	if err != nil {
+		return "", err
+	}
+	return data.Id, importer.GetTaskMgr().SetTaskSchedule(data.Id, s.BID)
+}
+
+// newUserContext builds the context of the nebula user for the work done in background, the secret is the encrypted password
+func newUserContext(host, user, secret string) (context.Context, error) {
+	password, err := secrets.Decrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	address, portStr, err := net.SplitHostPort(host)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(context.Background(), auth.CtxKeyUserInfo{}, &auth.AuthData{
+		Address:  address,
+		Port:     port,
+		Username: user,
+		Password: password,
+	}), nil
+}
+
+func isTaskRunning(taskID string) bool {
+	var count int64
+	db.CtxDB.Model(&db.TaskInfo{}).Where("b_id = ? AND task_status IN ?", taskID, []string{importer.Processing.String(), importer.Queued.String()}).Count(&count)
+	return count > 0
+}
+
+func updateScheduleMessage(id, message string) {
+	db.CtxDB.Model(&db.ImportSchedule{}).Where("b_id = ?", id).Update("last_message", message)
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/filestore"
+)
+
+const defaultValidateSampleRows = 100
+
+/*
+ValidateImportTask checks the config of the import task before it is created,
+the mappings are checked against the schema of the space, and the first rows of each source are sampled to check the values
+*/
+func (i *importService) ValidateImportTask(req *types.ValidateImportTaskRequest) (*types.ValidateImportTaskData, error) {
+	config, err := i.updateDatasourceConfig(&types.CreateImportTaskRequest{Config: req.Config})
+	if err != nil {
+		return nil, err
+	}
+	if _, _, _, err := normalizeSources(config.Sources); err != nil {
+		return nil, err
+	}
+	if _, _, err := remoteSources(config.Sources); err != nil {
+		return nil, err
+	}
+	sampleRows := req.SampleRows
+	if sampleRows == 0 {
+		sampleRows = defaultValidateSampleRows
+	}
+
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	schema, err := importer.DescribeSpace(auth.NSID, config.Manager.SpaceName)
+	validator := importer.NewImportValidator(schema)
+	if err != nil {
+		validator.AddSourceIssue(-1, "describe space %s failed: %s", config.Manager.SpaceName, err)
+	}
+
+	data := &types.ValidateImportTaskData{
+		Sources: []types.ImportSourceValidation{},
+	}
+	for idx, source := range config.Sources {
+		rows, err := i.sampleSource(source, sampleRows)
+		if err != nil {
+			validator.AddSourceIssue(idx, "sample the rows failed: %s", err)
+		}
+		data.Sources = append(data.Sources, *validator.CheckSource(idx, source, rows, 1))
+	}
+	data.Issues = validator.Issues
+	if data.Issues == nil {
+		data.Issues = []types.ImportValidationIssue{}
+	}
+	data.Valid = validator.Valid()
+	return data, nil
+}
+
+/*
+sampleSource reads the first rows of the source, the header is read besides the rows,
+the json records, the parquet rows and the rows of the database are read as the csv converted,
+the local file of the source is resolved by resolveSource
+*/
+func (i *importService) sampleSource(source *types.Source, rows int) ([][]string, error) {
+	ss, err := importer.NewSQLSource(source)
+	if err != nil {
+		return nil, err
+	}
+	js, err := importer.NewJSONSource(source)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := importer.NewParquetSource(source)
+	if err != nil {
+		return nil, err
+	}
+	converted := js != nil || ps != nil || ss != nil
+	if !converted && source.CSV.WithHeader != nil && *source.CSV.WithHeader {
+		rows++
+	}
+	// only the first lines of the remote file are read, the last record of the json file may be truncated
+	truncated := false
+	var r io.Reader
+	switch {
+	case ss != nil:
+		sr, err := ss.NewCSVReader()
+		if err != nil {
+			return nil, err
+		}
+		defer sr.Close()
+		r = sr
+	case ps != nil:
+		pr, err := i.openParquetSource(source, ps)
+		if err != nil {
+			return nil, err
+		}
+		defer pr.Close()
+		r = pr
+	case source.S3 != nil || source.OSS != nil || source.SFTP != nil || source.HTTP != nil:
+		store, path, err := openSourceStore(source)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+		lines, err := store.ReadFile(path, 0, rows)
+		if err != nil {
+			return nil, err
+		}
+		r = strings.NewReader(strings.Join(lines, "\n"))
+		truncated = js != nil && !js.Lines
+	case source.Path != "":
+		f, err := os.Open(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	default:
+		return nil, errors.New("the source has no file")
+	}
+
+	if js != nil {
+		r = js.NewCSVReader(r)
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	if !converted && source.CSV.Delimiter != nil {
+		if chars := []rune(*source.CSV.Delimiter); len(chars) > 0 {
+			reader.Comma = chars[0]
+		}
+	}
+	if source.CSV.LazyQuotes != nil {
+		reader.LazyQuotes = *source.CSV.LazyQuotes
+	}
+	records := make([][]string, 0, rows)
+	for len(records) < rows {
+		record, err := reader.Read()
+		if err == io.EOF || (truncated && errors.Is(err, io.ErrUnexpectedEOF)) {
+			break
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// openParquetSource reads the parquet file of the source as csv, the remote file is read by ranges
+func (i *importService) openParquetSource(source *types.Source, ps *importer.ParquetSource) (io.ReadCloser, error) {
+	if source.S3 == nil && source.OSS == nil && source.SFTP == nil && source.HTTP == nil {
+		pf, err := filestore.OpenLocalParquetFile(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		return ps.NewCSVReader(pf, nil)
+	}
+	store, path, err := openSourceStore(source)
+	if err != nil {
+		return nil, err
+	}
+	opener, ok := store.(filestore.ParquetOpener)
+	if !ok {
+		store.Close()
+		return nil, errors.New("the parquet file can not be read from the store")
+	}
+	pf, err := opener.OpenParquetFile(path)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return ps.NewCSVReader(pf, store)
+}
+
+// openSourceStore opens the store of the remote source, and returns the path of the file in the store
+func openSourceStore(source *types.Source) (filestore.FileStore, string, error) {
+	switch {
+	case source.S3 != nil:
+		platform := "aws"
+		if source.S3.Endpoint != "" {
+			platform = "customize"
+		}
+		store, err := filestore.NewS3Store(platform, source.S3.Endpoint, source.S3.Region, source.S3.Bucket, source.S3.AccessKeyID, source.S3.AccessKeySecret)
+		return store, source.S3.Key, err
+	case source.OSS != nil:
+		store, err := filestore.NewS3Store("oss", source.OSS.Endpoint, "", source.OSS.Bucket, source.OSS.AccessKeyID, source.OSS.AccessKeySecret)
+		return store, source.OSS.Key, err
+	case source.HTTP != nil:
+		store, err := filestore.NewHTTPStore("", nil, source.HTTP.AuthType, source.HTTP.Username, source.HTTP.Password)
+		return store, source.HTTP.URL, err
+	default:
+		store, err := importer.OpenSFTPStore(source.SFTP)
+		return store, source.SFTP.Path, err
+	}
+}
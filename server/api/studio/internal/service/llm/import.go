@@ -11,6 +11,7 @@ import (
 	"github.com/vesoft-inc/go-pkg/response"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
 	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/base"
@@ -18,6 +19,9 @@ import (
 	"gorm.io/datatypes"
 )
 
+// datasourceFilesDir is where the files of the http datasources are downloaded for the jobs, under the gql path
+const datasourceFilesDir = "datasource"
+
 func hashString(s string) string {
 	h := fnv.New64a()
 	h.Write([]byte(s))
@@ -49,6 +53,15 @@ func (g *llmService) AddImportJob(req *types.LLMImportRequest) (resp *types.LLMR
 		UserPrompt: req.UserPrompt,
 		JobID:      time.Now().Format("20060102150405000") + "_" + hashString(space),
 	}
+	if req.DatasourceId != "" {
+		// the file of the datasource is read in place or downloaded, which is kept when the job is rerun
+		dir := filepath.Join(g.svcCtx.Config.LLM.GQLPath, datasourceFilesDir, job.JobID)
+		filePath, err := service.FetchDatasourceFile(g.ctx, g.svcCtx, req.DatasourceId, req.DatasourceFilePath, dir)
+		if err != nil {
+			return nil, err
+		}
+		job.File, job.FilePath = filepath.Base(filePath), filePath
+	}
 	task := &db.TaskInfo{
 		BID:     job.JobID,
 		LLMJob:  job,
@@ -149,6 +162,10 @@ func (g *llmService) DeleteLLMImportJob(req *types.DeleteLLMImportRequest) (resp
 	if err != nil {
 		return nil, fmt.Errorf("remove job path error: %v", err)
 	}
+	err = os.RemoveAll(filepath.Join(config.GetConfig().LLM.GQLPath, datasourceFilesDir, job.JobID))
+	if err != nil {
+		return nil, fmt.Errorf("remove the files downloaded error: %v", err)
+	}
 
 	return &types.LLMResponse{
 		Data: response.StandardHandlerDataFieldAny(job),
@@ -11,6 +11,7 @@ import (
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/llm"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -62,6 +63,9 @@ func (g *llmService) GetLLMConfig() (resp *types.LLMResponse, err error) {
 	if res.RowsAffected == 0 {
 		return nil, nil
 	}
+	if err := llm.DecryptLLMConfig(&llmConfig); err != nil {
+		return nil, err
+	}
 	return &types.LLMResponse{
 		Data: response.StandardHandlerDataFieldAny(map[string]any{
 			"gqlPath": config.GetConfig().LLM.GQLPath,
@@ -82,9 +86,13 @@ func (g *llmService) LLMConfig(req *types.LLMConfigRequest) (err error) {
 			return res.Error
 		}
 	}
+	key, err := secrets.Encrypt(req.Key)
+	if err != nil {
+		return err
+	}
 	llmConfig := db.LLMConfig{
 		URL:                req.URL,
-		Key:                req.Key,
+		Key:                key,
 		APIType:            db.APIType(req.APIType),
 		Config:             req.Config,
 		Host:               fmt.Sprintf("%s:%d", auth.Address, auth.Port),
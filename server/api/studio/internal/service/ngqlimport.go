@@ -0,0 +1,119 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strconv"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+)
+
+// CreateNGQLImportTask runs an uploaded or datasource .ngql script as an import task
+func (i *importService) CreateNGQLImportTask(req *types.CreateNGQLImportTaskRequest) (*types.CreateImportTaskData, error) {
+	return i.createNGQLImportTask(req, "")
+}
+
+// createNGQLImportTask runs the script of the request, or the failed statements of the task if failedTaskID is given
+func (i *importService) createNGQLImportTask(req *types.CreateNGQLImportTaskRequest, failedTaskID string) (*types.CreateImportTaskData, error) {
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+
+	cfg := &importer.NGQLConfig{
+		Space:    req.Space,
+		FilePath: req.File,
+		Batch:    req.Batch,
+	}
+	switch {
+	case failedTaskID != "":
+		cfg.FilePath = importer.NGQLFailedStmtsName
+		cfg.FailedTaskId = failedTaskID
+	case req.DatasourceId != nil:
+		if req.DatasourceFilePath == nil || *req.DatasourceFilePath == "" {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("datasourceFilePath is required"))
+		}
+		cfg.DatasourceId = *req.DatasourceId
+		cfg.FilePath = *req.DatasourceFilePath
+	case req.File == "":
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("file or datasourceId is required"))
+	default:
+		if _, err := uploadFilePath(i.svcCtx.Config.File.UploadDir, req.File); err != nil {
+			return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+	}
+
+	id := i.svcCtx.IDGenerator.Generate()
+	rawConfig, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return &types.CreateImportTaskData{Id: id}, i.startNGQLTask(&db.TaskInfo{
+		BID:           id,
+		Name:          req.Name,
+		Address:       host,
+		Space:         req.Space,
+		ImportAddress: host,
+		User:          auth.Username,
+		RawConfig:     string(rawConfig),
+		TaskType:      db.TaskTypeNGQL,
+		Priority:      req.Priority,
+	}, true)
+}
+
+func (i *importService) startNGQLTask(taskInfo *db.TaskInfo, isNew bool) error {
+	cfg := &importer.NGQLConfig{}
+	if err := json.Unmarshal([]byte(taskInfo.RawConfig), cfg); err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	var scriptPath string
+	switch {
+	case cfg.FailedTaskId != "":
+		// the failed statements reimported are in the err dir of the task
+		scriptPath = filepath.Join(i.svcCtx.Config.File.TasksDir, cfg.FailedTaskId, errContentDir, importer.NGQLFailedStmtsName)
+	case cfg.DatasourceId == "":
+		path, err := uploadFilePath(i.svcCtx.Config.File.UploadDir, cfg.FilePath)
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+		}
+		scriptPath = path
+	}
+	store, err := openDatasourceStore(i.ctx, i.svcCtx, cfg.DatasourceId)
+	if err != nil {
+		return err
+	}
+	taskDir, err := importer.CreateNewTaskDir(i.svcCtx.Config.File.TasksDir, taskInfo.BID)
+	if err != nil {
+		closeStore(store)
+		return err
+	}
+	auth := i.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	runner := importer.NewNGQLRunner(taskInfo.BID, taskDir, scriptPath, cfg, auth, store)
+
+	taskMgr := importer.GetTaskMgr()
+	task, err := taskMgr.NewRunnerTask(taskInfo, runner)
+	if err != nil {
+		closeStore(store)
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	if isNew {
+		secret, err := secrets.Encrypt(auth.Password)
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		if err = taskMgr.NewTaskEffect(&db.TaskEffect{BID: taskInfo.BID, Secret: secret}); err != nil {
+			return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+	}
+	if err = importer.StartRunnerTask(taskInfo.BID); err != nil {
+		task.TaskInfo.TaskStatus = importer.Aborted.String()
+		task.TaskInfo.TaskMessage = err.Error()
+		taskMgr.AbortTask(taskInfo.BID)
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// secretColumns are the encrypted columns of the tables, the values of the plain columns were saved unencrypted before the envelopes
+var secretColumns = []struct {
+	model   interface{}
+	columns []string
+	plain   bool
+}{
+	{model: &db.Datasource{}, columns: []string{"secret", "private_key", "passphrase"}},
+	{model: &db.ImportSchedule{}, columns: []string{"config", "secret"}},
+	{model: &db.TaskEffect{}, columns: []string{"import_config", "secret"}},
+	{model: &db.Webhook{}, columns: []string{"secret"}},
+	{model: &db.LLMConfig{}, columns: []string{"key"}, plain: true},
+}
+
+/*
+RotateSecrets re-encrypts the secrets saved by the current master key, the secrets of the legacy key and the previous keys are rotated,
+the rows are updated in a transaction, nothing is changed if any secret can not be decrypted, it returns the count of the secrets rotated
+*/
+func RotateSecrets() (int, error) {
+	keyring := secrets.Default()
+	rotated := 0
+	err := db.CtxDB.Transaction(func(tx *gorm.DB) error {
+		for _, table := range secretColumns {
+			var rows []map[string]interface{}
+			if err := tx.Model(table.model).Select(append([]string{"id"}, table.columns...)).Find(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				updates := map[string]interface{}{}
+				for _, column := range table.columns {
+					value := columnString(row[column])
+					if table.plain && value != "" && !secrets.IsEnvelope(value) {
+						encrypted, err := keyring.Encrypt(value)
+						if err != nil {
+							return err
+						}
+						updates[column] = encrypted
+						continue
+					}
+					value, ok, err := keyring.Rotate(value)
+					if err != nil {
+						return fmt.Errorf("rotate the %s of the row %v error: %v", column, row["id"], err)
+					}
+					if ok {
+						updates[column] = value
+					}
+				}
+				if len(updates) == 0 {
+					continue
+				}
+				if err := tx.Model(table.model).Where("id = ?", row["id"]).Updates(updates).Error; err != nil {
+					return err
+				}
+				rotated += len(updates)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	logx.Infof("%d secrets are rotated to the master key %s", rotated, keyring.KeyID())
+	return rotated, nil
+}
+
+// columnString is the text of the column, which is scanned as bytes by some drivers
+func columnString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ StageService = (*stageService)(nil)
+
+type (
+	StageService interface {
+		CreateStageTask(*types.CreateStageTaskRequest) (*types.CreateStageTaskData, error)
+		GetStageTask(*types.GetStageTaskRequest) (*types.GetStageTaskData, error)
+		GetManyStageTask(*types.GetManyStageTaskRequest) (*types.GetManyStageTaskData, error)
+		StopStageTask(*types.StopStageTaskRequest) error
+		ResumeStageTask(*types.ResumeStageTaskRequest) error
+		DeleteStageTask(*types.DeleteStageTaskRequest) error
+	}
+
+	stageService struct {
+		logx.Logger
+		ctx              context.Context
+		svcCtx           *svc.ServiceContext
+		gormErrorWrapper utils.GormErrorWrapper
+	}
+)
+
+func NewStageService(ctx context.Context, svcCtx *svc.ServiceContext) StageService {
+	return &stageService{
+		Logger:           logx.WithContext(ctx),
+		ctx:              ctx,
+		svcCtx:           svcCtx,
+		gormErrorWrapper: utils.GormErrorWithLogger(ctx),
+	}
+}
+
+// CreateStageTask copies the files of the datasource into the upload dir in background
+func (s *stageService) CreateStageTask(req *types.CreateStageTaskRequest) (*types.CreateStageTaskData, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	if len(req.Files) == 0 && len(req.Prefixes) == 0 {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("files or prefixes are required"))
+	}
+	cfg := &importer.StageConfig{
+		DatasourceId: req.DatasourceId,
+		Files:        req.Files,
+		Prefixes:     req.Prefixes,
+		Pattern:      req.Pattern,
+		Overwrite:    req.Overwrite,
+	}
+	for _, typ := range strings.Split(req.Types, ",") {
+		if typ = strings.TrimSpace(typ); typ != "" {
+			cfg.Types = append(cfg.Types, typ)
+		}
+	}
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("stage %d files and %d prefixes", len(req.Files), len(req.Prefixes))
+	}
+
+	id := s.svcCtx.IDGenerator.Generate()
+	rawConfig, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	taskInfo := &db.TaskInfo{
+		BID:           id,
+		Name:          name,
+		Address:       host,
+		ImportAddress: host,
+		User:          auth.Username,
+		RawConfig:     string(rawConfig),
+		TaskType:      db.TaskTypeStage,
+	}
+	if err := s.startStageTask(taskInfo, cfg, true); err != nil {
+		return nil, err
+	}
+	return &types.CreateStageTaskData{Id: id}, nil
+}
+
+// ResumeStageTask stages the files which are not staged by the stopped, failed or interrupted task
+func (s *stageService) ResumeStageTask(req *types.ResumeStageTaskRequest) error {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	taskInfo, err := importer.FindStageTask(req.Id, host, auth.Username)
+	if err != nil {
+		return err
+	}
+	switch taskInfo.TaskStatus {
+	case importer.Stoped.String(), importer.Aborted.String(), importer.Interrupted.String():
+	default:
+		return ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("the task in %s status can not be resumed", taskInfo.TaskStatus))
+	}
+	cfg, err := importer.ParseStageConfig(taskInfo.RawConfig)
+	if err != nil {
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return s.startStageTask(taskInfo, cfg, false)
+}
+
+func (s *stageService) startStageTask(taskInfo *db.TaskInfo, cfg *importer.StageConfig, isNew bool) error {
+	store, err := openDatasourceStore(s.ctx, s.svcCtx, cfg.DatasourceId)
+	if err != nil {
+		return err
+	}
+	taskDir, err := importer.CreateNewTaskDir(s.svcCtx.Config.File.TasksDir, taskInfo.BID)
+	if err != nil {
+		closeStore(store)
+		return err
+	}
+	stager := importer.NewStager(taskInfo.BID, taskDir, s.svcCtx.Config.File.UploadDir, cfg, store)
+
+	taskMgr := importer.GetTaskMgr()
+	task, err := taskMgr.NewRunnerTask(taskInfo, stager)
+	if err != nil {
+		closeStore(store)
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	if isNew {
+		// the password is kept as the other tasks, so that the task interrupted is resumed on startup
+		auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+		secret, err := secrets.Encrypt(auth.Password)
+		if err != nil {
+			return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+		if err = taskMgr.NewTaskEffect(&db.TaskEffect{BID: taskInfo.BID, Secret: secret}); err != nil {
+			return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+		}
+	}
+	if err = importer.StartRunnerTask(taskInfo.BID); err != nil {
+		task.TaskInfo.TaskStatus = importer.Aborted.String()
+		task.TaskInfo.TaskMessage = err.Error()
+		taskMgr.AbortTask(taskInfo.BID)
+		return ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return nil
+}
+
+func (s *stageService) GetStageTask(req *types.GetStageTaskRequest) (*types.GetStageTaskData, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	return importer.GetStageTask(req.Id, host, auth.Username)
+}
+
+func (s *stageService) GetManyStageTask(req *types.GetManyStageTaskRequest) (*types.GetManyStageTaskData, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	data, err := importer.GetManyStageTask(host, auth.Username, req.Page, req.PageSize)
+	if err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	return data, nil
+}
+
+func (s *stageService) StopStageTask(req *types.StopStageTaskRequest) error {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	return importer.StopStageTask(req.Id, host, auth.Username)
+}
+
+// DeleteStageTask deletes the task, the files staged are removed with their settings unless KeepFiles is set
+func (s *stageService) DeleteStageTask(req *types.DeleteStageTaskRequest) error {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	removed, err := importer.DeleteStageTask(s.svcCtx.Config.File.TasksDir, s.svcCtx.Config.File.UploadDir, req.Id, host, auth.Username, req.KeepFiles)
+	if len(removed) > 0 {
+		if result := db.CtxDB.Where("name IN ?", removed).Delete(&db.File{}); result.Error != nil {
+			s.Logger.Errorf("delete the settings of the files staged error: %s", result.Error)
+		}
+	}
+	return err
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/webhook"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ WebhookService = (*webhookService)(nil)
+
+type (
+	WebhookService interface {
+		CreateWebhook(*types.CreateWebhookRequest) (*types.CreateWebhookData, error)
+		UpdateWebhook(*types.UpdateWebhookRequest) error
+		GetManyWebhook(*types.GetManyWebhookRequest) (*types.GetManyWebhookData, error)
+		DeleteWebhook(*types.DeleteWebhookRequest) error
+		GetWebhookDeliveries(*types.GetWebhookDeliveriesRequest) (*types.GetWebhookDeliveriesData, error)
+	}
+
+	webhookService struct {
+		logx.Logger
+		ctx              context.Context
+		svcCtx           *svc.ServiceContext
+		gormErrorWrapper utils.GormErrorWrapper
+	}
+)
+
+func NewWebhookService(ctx context.Context, svcCtx *svc.ServiceContext) WebhookService {
+	return &webhookService{
+		Logger:           logx.WithContext(ctx),
+		ctx:              ctx,
+		svcCtx:           svcCtx,
+		gormErrorWrapper: utils.GormErrorWithLogger(ctx),
+	}
+}
+
+// StartWebhooks starts to deliver the events of the tasks and the jobs to the global webhooks in the config and the webhooks of the users
+func StartWebhooks(svcCtx *svc.ServiceContext) {
+	webhook.Start(svcCtx.Config.Webhook, secrets.Default())
+}
+
+func (s *webhookService) CreateWebhook(req *types.CreateWebhookRequest) (*types.CreateWebhookData, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	events, err := validateWebhook(req.URL, req.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := encryptWebhookSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+	hook := &db.Webhook{
+		BID:      s.svcCtx.IDGenerator.Generate(),
+		Name:     req.Name,
+		Host:     host,
+		Username: auth.Username,
+		URL:      req.URL,
+		Secret:   secret,
+		Events:   events,
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	}
+	if err := db.CtxDB.Create(hook).Error; err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	return &types.CreateWebhookData{Id: hook.BID}, nil
+}
+
+func (s *webhookService) UpdateWebhook(req *types.UpdateWebhookRequest) error {
+	hook, err := s.findOne(req.Id)
+	if err != nil {
+		return err
+	}
+	events, err := validateWebhook(req.URL, req.Events)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"name":    req.Name,
+		"url":     req.URL,
+		"events":  events,
+		"enabled": req.Enabled,
+	}
+	if req.Secret != nil {
+		secret, err := encryptWebhookSecret(*req.Secret)
+		if err != nil {
+			return err
+		}
+		updates["secret"] = secret
+	}
+	if err := db.CtxDB.Model(&db.Webhook{}).Where("b_id = ?", hook.BID).Updates(updates).Error; err != nil {
+		return s.gormErrorWrapper(err)
+	}
+	return nil
+}
+
+func (s *webhookService) GetManyWebhook(req *types.GetManyWebhookRequest) (*types.GetManyWebhookData, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	var (
+		hooks []*db.Webhook
+		count int64
+	)
+	tx := db.CtxDB.Model(&db.Webhook{}).Where("host = ? AND username = ?", host, auth.Username)
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	if err := tx.Order("id desc").Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&hooks).Error; err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	data := &types.GetManyWebhookData{
+		Total: count,
+		List:  []types.WebhookData{},
+	}
+	for _, hook := range hooks {
+		events := webhook.SplitEvents(hook.Events)
+		if events == nil {
+			events = []string{}
+		}
+		data.List = append(data.List, types.WebhookData{
+			Id:         hook.BID,
+			Name:       hook.Name,
+			URL:        hook.URL,
+			HasSecret:  hook.Secret != "",
+			Events:     events,
+			Enabled:    hook.Enabled,
+			CreateTime: hook.CreateTime.UnixMilli(),
+			UpdateTime: hook.UpdateTime.UnixMilli(),
+		})
+	}
+	return data, nil
+}
+
+// DeleteWebhook keeps the deliveries of the webhook
+func (s *webhookService) DeleteWebhook(req *types.DeleteWebhookRequest) error {
+	hook, err := s.findOne(req.Id)
+	if err != nil {
+		return err
+	}
+	if err := db.CtxDB.Delete(&db.Webhook{}, "b_id = ?", hook.BID).Error; err != nil {
+		return s.gormErrorWrapper(err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries lists the deliveries to the webhooks of the user, the deliveries to the global webhooks are not listed
+func (s *webhookService) GetWebhookDeliveries(req *types.GetWebhookDeliveriesRequest) (*types.GetWebhookDeliveriesData, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	var (
+		deliveries []*db.WebhookDelivery
+		count      int64
+	)
+	tx := db.CtxDB.Model(&db.WebhookDelivery{}).Where("host = ? AND username = ? AND webhook_id <> ?", host, auth.Username, "")
+	if req.WebhookId != "" {
+		tx = tx.Where("webhook_id = ?", req.WebhookId)
+	}
+	if req.Status != "" {
+		tx = tx.Where("status = ?", req.Status)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	if err := tx.Order("id desc").Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&deliveries).Error; err != nil {
+		return nil, s.gormErrorWrapper(err)
+	}
+	data := &types.GetWebhookDeliveriesData{
+		Total: count,
+		List:  []types.WebhookDeliveryData{},
+	}
+	for _, d := range deliveries {
+		data.List = append(data.List, types.WebhookDeliveryData{
+			Id:           d.BID,
+			WebhookId:    d.WebhookID,
+			URL:          d.URL,
+			Event:        d.Event,
+			Payload:      d.Payload,
+			Status:       d.Status,
+			Attempts:     d.Attempts,
+			ResponseCode: d.ResponseCode,
+			Error:        d.Error,
+			CreateTime:   d.CreateTime.UnixMilli(),
+			UpdateTime:   d.UpdateTime.UnixMilli(),
+		})
+	}
+	return data, nil
+}
+
+func (s *webhookService) findOne(id string) (*db.Webhook, error) {
+	auth := s.ctx.Value(auth.CtxKeyUserInfo{}).(*auth.AuthData)
+	host := auth.Address + ":" + strconv.Itoa(auth.Port)
+	hook := new(db.Webhook)
+	if err := db.CtxDB.Where("b_id = ? AND host = ? AND username = ?", id, host, auth.Username).First(hook).Error; err != nil {
+		return nil, ecode.WithErrorMessage(ecode.ErrBadRequest, errors.New("webhook not existed"))
+	}
+	return hook, nil
+}
+
+// validateWebhook checks the url and the event patterns of the webhook, and returns the patterns joined to be saved
+func validateWebhook(rawURL string, events []string) (string, error) {
+	if err := webhook.CheckURL(rawURL, config.GetConfig().Webhook.AllowedHosts); err != nil {
+		return "", ecode.WithErrorMessage(ecode.ErrBadRequest, err)
+	}
+	patterns := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if strings.Contains(e, ",") || !(e == "*" || strings.HasPrefix(e, webhook.KindTask+".") || strings.HasPrefix(e, webhook.KindLLMJob+".")) {
+			return "", ecode.WithErrorMessage(ecode.ErrBadRequest, fmt.Errorf("invalid webhook event: %s", e))
+		}
+		patterns = append(patterns, e)
+	}
+	return strings.Join(patterns, ","), nil
+}
+
+func encryptWebhookSecret(secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	encrypted, err := secrets.Encrypt(secret)
+	if err != nil {
+		return "", ecode.WithErrorMessage(ecode.ErrInternalServer, err)
+	}
+	return encrypted, nil
+}
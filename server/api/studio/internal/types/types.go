@@ -35,6 +35,10 @@ type FileStat struct {
 	Delimiter  string `json:"delimiter"`
 	Name       string `json:"name"`
 	Size       int64  `json:"size"`
+	// Quote, Encoding and Columns are detected when the file is uploaded or previewed
+	Quote    string       `json:"quote"`
+	Encoding string       `json:"encoding"`
+	Columns  []FileColumn `json:"columns"`
 }
 
 type FilesIndexData struct {
@@ -47,12 +51,90 @@ type FileConfigUpdateRequest struct {
 	Name       string `json:"name" validate:"required"`
 }
 
+type FileColumn struct {
+	Name string `json:"name"`
+	// Type is int, double, bool, date, datetime or string
+	Type string `json:"type"`
+}
+
+type FilePreviewRequest struct {
+	Name string `json:"name" validate:"required"`
+	// Delimiter, Quote and WithHeader override the settings saved or detected, the quote is none if the fields are not quoted
+	Delimiter  string `json:"delimiter,optional"`
+	Quote      string `json:"quote,optional"`
+	WithHeader *bool  `json:"withHeader,optional"`
+	// Rows is the most rows previewed after the header
+	Rows int `json:"rows,optional"`
+	// Detect detects the settings again rather than using the settings saved
+	Detect bool `json:"detect,optional"`
+}
+
+type FilePreviewData struct {
+	Encoding   string       `json:"encoding"`
+	Delimiter  string       `json:"delimiter"`
+	Quote      string       `json:"quote"`
+	WithHeader bool         `json:"withHeader"`
+	Columns    []FileColumn `json:"columns"`
+	Rows       [][]string   `json:"rows"`
+	// Truncated tells only the head of the file is previewed
+	Truncated bool `json:"truncated"`
+}
+
+type FileUploadInitRequest struct {
+	Name string `json:"name" validate:"required"`
+	Size int64  `json:"size" validate:"gte=0"`
+	// Checksum is the hex sha256 of the whole file, which is verified when the upload is completed
+	Checksum string `json:"checksum,optional"`
+	// WithHeader and Delimiter are saved on completion, the settings are detected if they are not given
+	WithHeader *bool  `json:"withHeader,optional"`
+	Delimiter  string `json:"delimiter,optional"`
+}
+
+type FileUploadData struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// Offset is the bytes received, the next chunk starts from it
+	Offset int64 `json:"offset"`
+	// ChunkSize is the most bytes of a chunk
+	ChunkSize int64  `json:"chunkSize"`
+	Checksum  string `json:"checksum"`
+}
+
+type FileUploadStatusRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type FileUploadChunkRequest struct {
+	Id     string `path:"id" validate:"required"`
+	Offset int64  `form:"offset" validate:"gte=0"`
+	// Checksum is the hex sha256 of the chunk in the body
+	Checksum string `form:"checksum,optional"`
+}
+
+type FileUploadCompleteRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type FileUploadAbortRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
 type ImportTaskCSV struct {
 	WithHeader *bool   `json:"withHeader,optional"`
 	LazyQuotes *bool   `json:"lazyQuotes,optional"`
 	Delimiter  *string `json:"delimiter,optional"`
 }
 
+type ImportTaskJSON struct {
+	Root   string   `json:"root,optional,omitempty"`
+	Fields []string `json:"fields"`
+}
+
+type ImportTaskParquet struct {
+	Columns []string `json:"columns"`
+}
+
 type NodeId struct {
 	Name        string        `json:"name,optional"`
 	Type        string        `json:"type" validate:"required"`
@@ -114,6 +196,9 @@ type SFTPConfig struct {
 	KeyData    string `json:"keyData,optional,omitempty"`
 	Passphrase string `json:"passphrase,optional,omitempty"`
 	Path       string `json:"path,omitempty"`
+	// HostKeyFingerprint and KnownHosts verify the host, the file is read by the studio instead of nebula-importer with them
+	HostKeyFingerprint string `json:"hostKeyFingerprint,optional,omitempty"`
+	KnownHosts         string `json:"knownHosts,optional,omitempty"`
 }
 
 type OSSConfig struct {
@@ -128,6 +213,26 @@ type LocalConfig struct {
 	Path string `json:"path,omitempty"`
 }
 
+type SQLConfig struct {
+	Driver   string `json:"driver"`
+	Host     string `json:"host,optional,omitempty"`
+	Port     int    `json:"port,optional,omitempty"`
+	Database string `json:"database"`
+	User     string `json:"user,optional,omitempty"`
+	Password string `json:"password,optional,omitempty"`
+	Params   string `json:"params,optional,omitempty"`
+	Table    string `json:"table,optional,omitempty"`
+	Query    string `json:"query,optional,omitempty"`
+}
+
+type HTTPConfig struct {
+	URL string `json:"url"`
+	// AuthType is none, basic or bearer, the password is the token of bearer
+	AuthType string `json:"authType,optional,omitempty"`
+	Username string `json:"username,optional,omitempty"`
+	Password string `json:"password,optional,omitempty"`
+}
+
 type ImportTaskConfig struct {
 	Client  Client    `json:"client" validate:"required"`
 	Manager Manager   `json:"manager" validate:"required"`
@@ -155,15 +260,20 @@ type Manager struct {
 }
 
 type Source struct {
-	CSV                ImportTaskCSV `json:"csv" validate:"required"`
-	Path               string        `json:"path,optional,omitempty"`
-	S3                 *S3Config     `json:"s3,optional,omitempty"`
-	SFTP               *SFTPConfig   `json:"sftp,optional,omitempty"`
-	OSS                *OSSConfig    `json:"oss,optional,omitempty"`
-	DatasourceId       *string       `json:"datasourceId,optional,omitempty"`
-	DatasourceFilePath *string       `json:"datasourceFilePath,optional,omitempty"`
-	Tags               []Tag         `json:"tags,optional"`
-	Edges              []Edge        `json:"edges,optional"`
+	Format             *string            `json:"format,optional,omitempty" validate:"omitempty,oneof=csv json jsonl parquet"`
+	CSV                ImportTaskCSV      `json:"csv" validate:"required"`
+	JSON               *ImportTaskJSON    `json:"json,optional,omitempty"`
+	Parquet            *ImportTaskParquet `json:"parquet,optional,omitempty"`
+	Path               string             `json:"path,optional,omitempty"`
+	S3                 *S3Config          `json:"s3,optional,omitempty"`
+	SFTP               *SFTPConfig        `json:"sftp,optional,omitempty"`
+	OSS                *OSSConfig         `json:"oss,optional,omitempty"`
+	SQL                *SQLConfig         `json:"sql,optional,omitempty"`
+	HTTP               *HTTPConfig        `json:"http,optional,omitempty"`
+	DatasourceId       *string            `json:"datasourceId,optional,omitempty"`
+	DatasourceFilePath *string            `json:"datasourceFilePath,optional,omitempty"`
+	Tags               []Tag              `json:"tags,optional"`
+	Edges              []Edge             `json:"edges,optional"`
 }
 
 type Log struct {
@@ -183,6 +293,7 @@ type CreateImportTaskRequest struct {
 	Name      string  `json:"name" validate:"required"`
 	Config    string  `json:"config" validate:"required"`
 	RawConfig string  `json:"rawConfig" validate:"required"`
+	Priority  int     `json:"priority,optional"`
 }
 
 type CreateTaskDraftRequest struct {
@@ -220,6 +331,11 @@ type GetImportTaskData struct {
 	Stats         ImportTaskStats `json:"stats"`
 	RawConfig     string          `json:"rawConfig"`
 	LLMJob        interface{}     `json:"llmJob"`
+	ScheduleId    string          `json:"scheduleId,omitempty"`
+	ParentTaskId  string          `json:"parentTaskId,omitempty"`
+	RunType       string          `json:"runType,omitempty"`
+	Priority      int             `json:"priority"`
+	QueuePosition int             `json:"queuePosition,omitempty"`
 }
 
 type ImportTaskStats struct {
@@ -284,6 +400,188 @@ type GetWorkingDirResult struct {
 	UploadDir string `json:"uploadDir,omitempty"`
 }
 
+type CreateNGQLImportTaskRequest struct {
+	Name               string  `json:"name" validate:"required"`
+	Space              string  `json:"space,optional"`
+	File               string  `json:"file,optional"`
+	DatasourceId       *string `json:"datasourceId,optional"`
+	DatasourceFilePath *string `json:"datasourceFilePath,optional"`
+	Batch              int     `json:"batch,optional" validate:"gte=0,lte=10000"`
+	Priority           int     `json:"priority,optional"`
+}
+
+type ResumeImportTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type RetryImportTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type ValidateImportTaskRequest struct {
+	Config     string `json:"config" validate:"required"`
+	SampleRows int    `json:"sampleRows,optional" validate:"gte=0,lte=10000"`
+}
+
+type ValidateImportTaskData struct {
+	Valid   bool                     `json:"valid"`
+	Sources []ImportSourceValidation `json:"sources"`
+	Issues  []ImportValidationIssue  `json:"issues"`
+}
+
+type ImportSourceValidation struct {
+	Index       int `json:"index"`
+	SampledRows int `json:"sampledRows"`
+	FailedRows  int `json:"failedRows"`
+}
+
+type ImportValidationIssue struct {
+	Source  int    `json:"source"`
+	Row     int    `json:"row,omitempty"`
+	Item    string `json:"item,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type SuggestImportMappingRequest struct {
+	Space              string  `json:"space" validate:"required"`
+	File               string  `json:"file,optional"`
+	DatasourceId       *string `json:"datasourceId,optional"`
+	DatasourceFilePath *string `json:"datasourceFilePath,optional"`
+	Delimiter          *string `json:"delimiter,optional"`
+	WithHeader         *bool   `json:"withHeader,optional"`
+	SampleRows         int     `json:"sampleRows,optional" validate:"gte=0,lte=10000"`
+	DraftName          string  `json:"draftName,optional"`
+}
+
+type SuggestImportMappingData struct {
+	Columns []ImportColumnSuggestion `json:"columns"`
+	Config  ImportTaskConfig         `json:"config"`
+	DraftId string                   `json:"draftId,omitempty"`
+}
+
+type ImportColumnSuggestion struct {
+	Index   int64    `json:"index"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Samples []string `json:"samples"`
+}
+
+type CloneImportTaskRequest struct {
+	Id       string              `path:"id" validate:"required"`
+	Name     string              `json:"name,optional"`
+	Space    string              `json:"space,optional"`
+	Address  string              `json:"address,optional"`
+	Sources  []CloneImportSource `json:"sources,optional"`
+	Draft    bool                `json:"draft,optional"`
+	Priority int                 `json:"priority,optional"`
+}
+
+type CloneImportSource struct {
+	Index              int     `json:"index"`
+	Path               *string `json:"path,optional"`
+	DatasourceId       *string `json:"datasourceId,optional"`
+	DatasourceFilePath *string `json:"datasourceFilePath,optional"`
+}
+
+type ImportTaskFailedFile struct {
+	Name    string `json:"name"`
+	Source  int    `json:"source"`
+	Size    int64  `json:"size"`
+	Records int64  `json:"records"`
+}
+
+type GetImportTaskFailedFilesRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type GetImportTaskFailedFilesData struct {
+	List []ImportTaskFailedFile `json:"list"`
+}
+
+type PreviewImportTaskFailedFileRequest struct {
+	Id       string `path:"id" validate:"required"`
+	Name     string `form:"name" validate:"required"`
+	Page     int    `form:"page,default=1" validate:"gte=1"`
+	PageSize int    `form:"pageSize,default=100" validate:"gte=1,lte=1000"`
+}
+
+type PreviewImportTaskFailedFileData struct {
+	Total   int64      `json:"total"`
+	Records [][]string `json:"records"`
+}
+
+type DownloadImportTaskFailedFileRequest struct {
+	Id   string `path:"id" validate:"required"`
+	Name string `form:"name" validate:"required"`
+}
+
+type DownloadImportTaskFailedFilesRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type ReimportImportTaskFailedFilesRequest struct {
+	Id    string   `path:"id" validate:"required"`
+	Files []string `json:"files,optional"`
+}
+
+type GetImportTaskMetricsRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type ImportTaskMetricPoint struct {
+	Time           int64   `json:"time"`
+	RowsPerSecond  float64 `json:"rowsPerSecond"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+	// Latency is the average latency of the requests in microseconds
+	Latency     int64   `json:"latency"`
+	FailureRate float64 `json:"failureRate"`
+}
+
+type ImportTaskPerformance struct {
+	TaskId    string `json:"taskId"`
+	Status    string `json:"status"`
+	StartTime int64  `json:"startTime"`
+	// Duration is the running time in milliseconds, and the latencies are in microseconds
+	Duration          int64   `json:"duration"`
+	Records           int64   `json:"records"`
+	Bytes             int64   `json:"bytes"`
+	FailedRecords     int64   `json:"failedRecords"`
+	RowsPerSecond     float64 `json:"rowsPerSecond"`
+	BytesPerSecond    float64 `json:"bytesPerSecond"`
+	PeakRowsPerSecond float64 `json:"peakRowsPerSecond"`
+	LatencyP50        int64   `json:"latencyP50"`
+	LatencyP90        int64   `json:"latencyP90"`
+	LatencyP99        int64   `json:"latencyP99"`
+	FailureRate       float64 `json:"failureRate"`
+}
+
+type GetImportTaskMetricsData struct {
+	Points  []ImportTaskMetricPoint `json:"points"`
+	Summary ImportTaskPerformance   `json:"summary"`
+}
+
+type GetImportTaskReportRequest struct {
+	Id    string `path:"id" validate:"required"`
+	Limit int    `form:"limit,default=5" validate:"gte=1,lte=50"`
+}
+
+type ImportTaskPerformanceChange struct {
+	Duration       float64 `json:"duration"`
+	RowsPerSecond  float64 `json:"rowsPerSecond"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+	LatencyP50     float64 `json:"latencyP50"`
+	LatencyP99     float64 `json:"latencyP99"`
+	FailureRate    float64 `json:"failureRate"`
+}
+
+type GetImportTaskReportData struct {
+	Report   ImportTaskPerformance   `json:"report"`
+	Previous []ImportTaskPerformance `json:"previous"`
+	// Change compares the run with the average of the previous runs in percent, the failure rate by the difference, it is null without previous runs
+	Change *ImportTaskPerformanceChange `json:"change"`
+}
+
 type GetSketchesRequest struct {
 	Page     int64  `form:"page,range=[0:],optional"`
 	PageSize int64  `form:"pageSize,default=10,range=[1:1000],optional"`
@@ -378,7 +676,43 @@ type DatasourceSFTPConfig struct {
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	Username string `json:"username"`
-	Password string `json:"password"`
+	Password string `json:"password,optional"`
+	// PrivateKey is in PEM, which is tried before the password, it is saved encrypted and never listed
+	PrivateKey string `json:"privateKey,optional"`
+	Passphrase string `json:"passphrase,optional"`
+	// HasPrivateKey tells whether the private key is saved
+	HasPrivateKey bool `json:"hasPrivateKey,optional"`
+	// HostKeyFingerprint is the SHA256 fingerprint of the host key, the host is trusted without it and the known hosts
+	HostKeyFingerprint string `json:"hostKeyFingerprint,optional"`
+	// KnownHosts are the lines of the known_hosts file, the host key must be one of them
+	KnownHosts string `json:"knownHosts,optional"`
+}
+
+type DatasourceSQLConfig struct {
+	Driver   string `json:"driver"`
+	Host     string `json:"host,optional"`
+	Port     int    `json:"port,optional"`
+	Database string `json:"database"`
+	Username string `json:"username,optional"`
+	Password string `json:"password,optional"`
+	Params   string `json:"params,optional"`
+}
+
+type DatasourceLocalConfig struct {
+	// Root is the dir of the server, which must be under one of the local roots allowed in the config
+	Root string `json:"root"`
+}
+
+type DatasourceHTTPConfig struct {
+	// URL is the base url of the directory index, the paths are relative to it
+	URL string `json:"url,optional"`
+	// URLs are the files listed instead of the directory index, which are absolute or relative to the base url
+	URLs []string `json:"urls,optional"`
+	// AuthType is none, basic or bearer
+	AuthType string `json:"authType,optional"`
+	Username string `json:"username,optional"`
+	Password string `json:"password,optional"`
+	Token    string `json:"token,optional"`
 }
 
 type DatasourceS3UpdateConfig struct {
@@ -394,23 +728,53 @@ type DatasourceSFTPUpdateConfig struct {
 	Port     int    `json:"port,optional,omitempty"`
 	Username string `json:"username,optional,omitempty"`
 	Password string `json:"password,optional,omitempty"`
+	// PrivateKey and Passphrase are kept if they are empty
+	PrivateKey         string `json:"privateKey,optional,omitempty"`
+	Passphrase         string `json:"passphrase,optional,omitempty"`
+	HostKeyFingerprint string `json:"hostKeyFingerprint,optional,omitempty"`
+	KnownHosts         string `json:"knownHosts,optional,omitempty"`
+}
+
+type DatasourceSQLUpdateConfig struct {
+	Driver   string `json:"driver,optional,omitempty"`
+	Host     string `json:"host,optional,omitempty"`
+	Port     int    `json:"port,optional,omitempty"`
+	Database string `json:"database,optional,omitempty"`
+	Username string `json:"username,optional,omitempty"`
+	Password string `json:"password,optional,omitempty"`
+	Params   string `json:"params,optional,omitempty"`
+}
+
+type DatasourceHTTPUpdateConfig struct {
+	URL      string   `json:"url,optional,omitempty"`
+	URLs     []string `json:"urls,optional,omitempty"`
+	AuthType string   `json:"authType,optional,omitempty"`
+	Username string   `json:"username,optional,omitempty"`
+	Password string   `json:"password,optional,omitempty"`
+	Token    string   `json:"token,optional,omitempty"`
 }
 
 type DatasourceAddRequest struct {
-	Type       string                `json:"type"`
-	Platform   string                `json:"platform,optional,omitempty"`
-	Name       string                `json:"name"`
-	S3Config   *DatasourceS3Config   `json:"s3Config,optional"`
-	SFTPConfig *DatasourceSFTPConfig `json:"sftpConfig,optional"`
+	Type        string                 `json:"type"`
+	Platform    string                 `json:"platform,optional,omitempty"`
+	Name        string                 `json:"name"`
+	S3Config    *DatasourceS3Config    `json:"s3Config,optional"`
+	SFTPConfig  *DatasourceSFTPConfig  `json:"sftpConfig,optional"`
+	SQLConfig   *DatasourceSQLConfig   `json:"sqlConfig,optional"`
+	LocalConfig *DatasourceLocalConfig `json:"localConfig,optional"`
+	HTTPConfig  *DatasourceHTTPConfig  `json:"httpConfig,optional"`
 }
 
 type DatasourceUpdateRequest struct {
-	ID         string                      `path:"id"`
-	Platform   string                      `json:"platform,optional,omitempty"`
-	Type       string                      `json:"type"`
-	Name       string                      `json:"name"`
-	S3Config   *DatasourceS3UpdateConfig   `json:"s3Config,optional"`
-	SFTPConfig *DatasourceSFTPUpdateConfig `json:"sftpConfig,optional"`
+	ID          string                      `path:"id"`
+	Platform    string                      `json:"platform,optional,omitempty"`
+	Type        string                      `json:"type"`
+	Name        string                      `json:"name"`
+	S3Config    *DatasourceS3UpdateConfig   `json:"s3Config,optional"`
+	SFTPConfig  *DatasourceSFTPUpdateConfig `json:"sftpConfig,optional"`
+	SQLConfig   *DatasourceSQLUpdateConfig  `json:"sqlConfig,optional"`
+	LocalConfig *DatasourceLocalConfig      `json:"localConfig,optional"`
+	HTTPConfig  *DatasourceHTTPUpdateConfig `json:"httpConfig,optional"`
 }
 
 type DatasourceAddData struct {
@@ -430,18 +794,31 @@ type DatasourceBatchRemoveRequest struct {
 }
 
 type DatasourceConfig struct {
-	ID         string                `json:"id"`
-	Type       string                `json:"type"`
-	Name       string                `json:"name"`
-	Platform   string                `json:"platform"`
-	S3Config   *DatasourceS3Config   `json:"s3Config,optional"`
-	SFTPConfig *DatasourceSFTPConfig `json:"sftpConfig,optional"`
-	CreateTime int64                 `json:"createTime,optional"`
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	Name        string                 `json:"name"`
+	Platform    string                 `json:"platform"`
+	S3Config    *DatasourceS3Config    `json:"s3Config,optional"`
+	SFTPConfig  *DatasourceSFTPConfig  `json:"sftpConfig,optional"`
+	SQLConfig   *DatasourceSQLConfig   `json:"sqlConfig,optional"`
+	LocalConfig *DatasourceLocalConfig `json:"localConfig,optional"`
+	HTTPConfig  *DatasourceHTTPConfig  `json:"httpConfig,optional"`
+	CreateTime  int64                  `json:"createTime,optional"`
 }
 
 type DatasourceListContentsRequest struct {
 	DatasourceID string `path:"id"`
 	Path         string `form:"path,optional"`
+	// Marker is the nextMarker of the last page
+	Marker string `form:"marker,optional"`
+	// PageSize is the most files in a page, all the files are listed if it is 0
+	PageSize int `form:"pageSize,optional" validate:"gte=0,lte=1000"`
+	// Prefix filters the names of the files and the directories
+	Prefix string `form:"prefix,optional"`
+	// Pattern is the glob pattern of the names of the files, e.g. *.csv
+	Pattern string `form:"pattern,optional"`
+	// Types are the comma separated types of the files, e.g. csv,jsonl,parquet,pdf,txt,gz
+	Types string `form:"types,optional"`
 }
 
 type FileConfig struct {
@@ -452,6 +829,8 @@ type FileConfig struct {
 
 type DatasourceListContentsData struct {
 	List []FileConfig `json:"list"`
+	// NextMarker is empty on the last page
+	NextMarker string `json:"nextMarker"`
 }
 
 type DatasourceData struct {
@@ -461,10 +840,36 @@ type DatasourceData struct {
 type DatasourcePreviewFileRequest struct {
 	DatasourceID string `path:"id"`
 	Path         string `form:"path"`
+	// Delimiter, Quote and WithHeader override the settings detected of the text file
+	Delimiter  string `form:"delimiter,optional"`
+	Quote      string `form:"quote,optional"`
+	WithHeader *bool  `form:"withHeader,optional"`
+	Rows       int    `form:"rows,optional"`
+}
+
+type DatasourceFileColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
 }
 
 type DatasourcePreviewFileData struct {
-	Contents []string `json:"contents"`
+	Contents []string               `json:"contents"`
+	Columns  []DatasourceFileColumn `json:"columns,omitempty"`
+	// Preview is the rows parsed by the settings detected of the text file
+	Preview *FilePreviewData `json:"preview,omitempty"`
+}
+
+type DatasourcePreviewRowsRequest struct {
+	DatasourceID string `path:"id"`
+	Table        string `json:"table,optional"`
+	Query        string `json:"query,optional"`
+	Page         int    `json:"page,optional,default=1"`
+	PageSize     int    `json:"pageSize,optional,default=20"`
+}
+
+type DatasourcePreviewRowsData struct {
+	Columns []DatasourceFileColumn `json:"columns"`
+	Rows    [][]string             `json:"rows"`
 }
 
 type LLMRequest struct {
@@ -489,6 +894,9 @@ type LLMImportRequest struct {
 	FilePath   string `json:"filePath,optional"`
 	Type       string `json:"type"`
 	UserPrompt string `json:"userPrompt"`
+	// DatasourceId is the local or the http datasource of the file, the file is uploaded without it
+	DatasourceId       string `json:"datasourceId,optional"`
+	DatasourceFilePath string `json:"datasourceFilePath,optional"`
 }
 
 type LLMImportJobsRequest struct {
@@ -513,3 +921,295 @@ type DeleteLLMImportRequest struct {
 type DownloadLLMImportNgqlRequest struct {
 	JobID string `json:"jobId"`
 }
+
+type ExportTaskItem struct {
+	Type  string   `json:"type" validate:"required,oneof=tag edge"`
+	Name  string   `json:"name" validate:"required"`
+	Props []string `json:"props,optional"`
+}
+
+type CreateExportTaskRequest struct {
+	Name           string           `json:"name" validate:"required"`
+	Space          string           `json:"space" validate:"required"`
+	Format         string           `json:"format,optional" validate:"omitempty,oneof=csv ngql"`
+	Batch          int              `json:"batch,optional" validate:"gte=0,lte=10000"`
+	Items          []ExportTaskItem `json:"items,optional"`
+	DatasourceId   *string          `json:"datasourceId,optional"`
+	DatasourcePath *string          `json:"datasourcePath,optional"`
+}
+
+type CreateExportTaskData struct {
+	Id string `json:"id"`
+}
+
+type GetExportTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type GetExportTaskData struct {
+	Id         string          `json:"id"`
+	Name       string          `json:"name"`
+	User       string          `json:"user"`
+	Address    string          `json:"address"`
+	Space      string          `json:"space"`
+	Status     string          `json:"status"`
+	Message    string          `json:"message"`
+	CreateTime int64           `json:"createTime"`
+	UpdateTime int64           `json:"updateTime"`
+	Stats      ImportTaskStats `json:"stats"`
+	RawConfig  string          `json:"rawConfig"`
+}
+
+type GetManyExportTaskRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=999"`
+	Space    string `form:"space,optional"`
+}
+
+type GetManyExportTaskData struct {
+	Total int64               `json:"total"`
+	List  []GetExportTaskData `json:"list"`
+}
+
+type StopExportTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type ResumeExportTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type DeleteExportTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type GetExportTaskLogRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type GetExportTaskLogData struct {
+	Logs string `json:"logs"`
+}
+
+type DownloadExportTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type CreateImportScheduleRequest struct {
+	Name string `json:"name" validate:"required"`
+	// TaskId is the draft or the task whose raw config is scheduled
+	TaskId string `json:"taskId" validate:"required"`
+	// Config is the import config like CreateImportTaskRequest, it is required unless the task runs an nGQL script
+	Config        string `json:"config,optional"`
+	Cron          string `json:"cron" validate:"required"`
+	TimeZone      string `json:"timeZone,optional"`
+	OverlapPolicy string `json:"overlapPolicy,optional" validate:"omitempty,oneof=skip queue"`
+	Enabled       *bool  `json:"enabled,optional"`
+}
+
+type CreateImportScheduleData struct {
+	Id string `json:"id"`
+}
+
+type UpdateImportScheduleRequest struct {
+	Id            string  `path:"id" validate:"required"`
+	Name          string  `json:"name" validate:"required"`
+	Config        *string `json:"config,optional"`
+	Cron          string  `json:"cron" validate:"required"`
+	TimeZone      string  `json:"timeZone,optional"`
+	OverlapPolicy string  `json:"overlapPolicy,optional" validate:"omitempty,oneof=skip queue"`
+	Enabled       bool    `json:"enabled"`
+}
+
+type GetImportScheduleRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type ImportScheduleData struct {
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	Space         string `json:"space"`
+	TaskType      string `json:"taskType"`
+	TaskId        string `json:"taskId"`
+	RawConfig     string `json:"rawConfig"`
+	Cron          string `json:"cron"`
+	TimeZone      string `json:"timeZone"`
+	OverlapPolicy string `json:"overlapPolicy"`
+	Enabled       bool   `json:"enabled"`
+	Queued        bool   `json:"queued"`
+	NextRunTime   int64  `json:"nextRunTime"`
+	LastRunTime   int64  `json:"lastRunTime"`
+	LastTaskId    string `json:"lastTaskId"`
+	LastMessage   string `json:"lastMessage"`
+	CreateTime    int64  `json:"createTime"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+type GetManyImportScheduleRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=999"`
+	Space    string `form:"space,optional"`
+}
+
+type GetManyImportScheduleData struct {
+	Total int64                `json:"total"`
+	List  []ImportScheduleData `json:"list"`
+}
+
+type DeleteImportScheduleRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type GetImportScheduleRunsRequest struct {
+	Id       string `path:"id" validate:"required"`
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=999"`
+}
+
+type CreateWebhookRequest struct {
+	Name string `json:"name" validate:"required"`
+	URL  string `json:"url" validate:"required"`
+	// Secret signs the requests by HMAC-SHA256, the requests are not signed without it
+	Secret string `json:"secret,optional"`
+	// Events are the patterns of the events notified, e.g. task.* or llm_job.failed, all the events are notified if it is empty
+	Events  []string `json:"events,optional"`
+	Enabled *bool    `json:"enabled,optional"`
+}
+
+type CreateWebhookData struct {
+	Id string `json:"id"`
+}
+
+type UpdateWebhookRequest struct {
+	Id   string `path:"id" validate:"required"`
+	Name string `json:"name" validate:"required"`
+	URL  string `json:"url" validate:"required"`
+	// Secret is kept if it is not given, and removed if it is empty
+	Secret  *string  `json:"secret,optional"`
+	Events  []string `json:"events,optional"`
+	Enabled bool     `json:"enabled"`
+}
+
+type WebhookData struct {
+	Id         string   `json:"id"`
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	HasSecret  bool     `json:"hasSecret"`
+	Events     []string `json:"events"`
+	Enabled    bool     `json:"enabled"`
+	CreateTime int64    `json:"createTime"`
+	UpdateTime int64    `json:"updateTime"`
+}
+
+type GetManyWebhookRequest struct {
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"pageSize,default=999"`
+}
+
+type GetManyWebhookData struct {
+	Total int64         `json:"total"`
+	List  []WebhookData `json:"list"`
+}
+
+type DeleteWebhookRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type GetWebhookDeliveriesRequest struct {
+	WebhookId string `form:"webhookId,optional"`
+	Status    string `form:"status,optional" validate:"omitempty,oneof=pending success failed"`
+	Page      int    `form:"page,default=1"`
+	PageSize  int    `form:"pageSize,default=20"`
+}
+
+type WebhookDeliveryData struct {
+	Id           string `json:"id"`
+	WebhookId    string `json:"webhookId"`
+	URL          string `json:"url"`
+	Event        string `json:"event"`
+	Payload      string `json:"payload"`
+	Status       string `json:"status"`
+	Attempts     int    `json:"attempts"`
+	ResponseCode int    `json:"responseCode"`
+	Error        string `json:"error"`
+	CreateTime   int64  `json:"createTime"`
+	UpdateTime   int64  `json:"updateTime"`
+}
+
+type GetWebhookDeliveriesData struct {
+	Total int64                 `json:"total"`
+	List  []WebhookDeliveryData `json:"list"`
+}
+
+type CreateStageTaskRequest struct {
+	DatasourceId string `json:"datasourceId" validate:"required"`
+	Name         string `json:"name,optional"`
+	// Files are the paths of the files in the datasource
+	Files []string `json:"files,optional"`
+	// Prefixes are the dirs in the datasource whose files are staged recursively
+	Prefixes []string `json:"prefixes,optional"`
+	// Pattern is the glob pattern of the names of the files under the prefixes, e.g. *.csv
+	Pattern string `json:"pattern,optional"`
+	// Types are the comma separated types of the files under the prefixes, e.g. csv,parquet
+	Types string `json:"types,optional"`
+	// Overwrite stages the file even if the file with the same name and size is uploaded
+	Overwrite bool `json:"overwrite,optional"`
+}
+
+type CreateStageTaskData struct {
+	Id string `json:"id"`
+}
+
+type GetStageTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type StagedFile struct {
+	// Path is the path in the datasource, Name is the name in the upload dir
+	Path     string `json:"path"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+	// Skipped is set if the file has been uploaded before, which is not cleaned up with the task
+	Skipped  bool `json:"skipped"`
+	Finished bool `json:"finished"`
+}
+
+type GetStageTaskData struct {
+	Id           string          `json:"id"`
+	Name         string          `json:"name"`
+	User         string          `json:"user"`
+	Address      string          `json:"address"`
+	DatasourceId string          `json:"datasourceId"`
+	Status       string          `json:"status"`
+	Message      string          `json:"message"`
+	CreateTime   int64           `json:"createTime"`
+	UpdateTime   int64           `json:"updateTime"`
+	Stats        ImportTaskStats `json:"stats"`
+	// Files are the files staged so far, which are only returned by the task detail
+	Files []StagedFile `json:"files,omitempty"`
+}
+
+type GetManyStageTaskRequest struct {
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"pageSize,default=999"`
+}
+
+type GetManyStageTaskData struct {
+	Total int64              `json:"total"`
+	List  []GetStageTaskData `json:"list"`
+}
+
+type StopStageTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type ResumeStageTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+}
+
+type DeleteStageTaskRequest struct {
+	Id string `path:"id" validate:"required"`
+	// KeepFiles keeps the files staged in the upload dir
+	KeepFiles bool `form:"keepFiles,optional"`
+}
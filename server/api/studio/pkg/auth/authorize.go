@@ -17,6 +17,7 @@ import (
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/types"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/client"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ecode"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
 	"github.com/zeromicro/go-zero/rest"
 )
@@ -62,12 +63,19 @@ func IsSessionError(err error) bool {
 	return false
 }
 
+// CreateToken signs the auth data, the password is encrypted as the claims of the token are readable by the client
 func CreateToken(authData *AuthData, config *config.Config) (string, error) {
+	secret, err := secrets.Encrypt(authData.Password)
+	if err != nil {
+		return "", err
+	}
+	claimsData := *authData
+	claimsData.Password = secret
 	now := time.Now()
 	expiresAt := now.Add(time.Duration(config.Auth.AccessExpire) * time.Second).Unix()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		authClaims{
-			AuthData: authData,
+			AuthData: &claimsData,
 			RegisteredClaims: jwt.RegisteredClaims{
 				ExpiresAt: &jwt.NumericDate{Time: time.Unix(expiresAt, 0)},
 				// ExpiresAt: expiresAt,
@@ -103,6 +111,16 @@ func Decode(tokenString, secret string) (*AuthData, error) {
 		return nil, errors.New("couldn't handle this token")
 	}
 
+	// the token signed before the password is encrypted or by the master key removed is not valid
+	if !secrets.IsEnvelope(auth.Password) {
+		return nil, errors.New("couldn't handle this token")
+	}
+	password, err := secrets.Decrypt(auth.Password)
+	if err != nil {
+		return nil, errors.New("couldn't handle this token")
+	}
+	auth.Password = password
+
 	return auth.AuthData, nil
 }
 
@@ -137,13 +155,17 @@ func ParseConnectDBParams(params *types.ConnectDBParams, config *config.Config)
 	if err != nil {
 		return "", err
 	}
-	// cache auth info key for llm import use
+	// cache auth info key for llm import use, the password is encrypted
+	secret, err := secrets.Encrypt(password)
+	if err != nil {
+		return "", err
+	}
 	key := fmt.Sprintf("%s:%d:%s", params.Address, params.Port, username)
 	CtxUserInfoMap[key] = AuthData{
 		Address:  params.Address,
 		Port:     params.Port,
 		Username: username,
-		Password: password,
+		Password: secret,
 	}
 
 	tokenString, err := CreateToken(
@@ -0,0 +1,192 @@
+package filestore
+
+import (
+	"sync"
+	"time"
+)
+
+// Pinger is the store whose connection is checked before it is reused, e.g. the ssh connection of sftp or the pool of sql
+type Pinger interface {
+	Ping() error
+}
+
+type (
+	// StoreCache keeps the stores connected by the keys for reuse, e.g. the ids of the datasources, the store is shared by the requests at the same time,
+	// it is closed when it is not used for the idle timeout, or it is invalidated and the last user releases it
+	StoreCache struct {
+		// IdleTimeout closes the store not used for it
+		IdleTimeout time.Duration
+		// CheckInterval is the time after the last check that the store reused is checked to be alive, it is checked every time if it is 0
+		CheckInterval time.Duration
+
+		mu      sync.Mutex
+		entries map[string]*cacheEntry
+		stop    chan struct{}
+	}
+
+	cacheEntry struct {
+		store FileStore
+		// version is the fingerprint of the config, the store of the config changed is not reused
+		version   string
+		refs      int
+		lastUsed  time.Time
+		lastCheck time.Time
+		// stale is set when the entry is removed from the cache, the store is closed after the users release it
+		stale bool
+	}
+)
+
+// NewStoreCache starts to evict the idle stores in the background until the cache is closed
+func NewStoreCache(idleTimeout, checkInterval time.Duration) *StoreCache {
+	c := &StoreCache{
+		IdleTimeout:   idleTimeout,
+		CheckInterval: checkInterval,
+		entries:       map[string]*cacheEntry{},
+		stop:          make(chan struct{}),
+	}
+	go c.evictLoop()
+	return c
+}
+
+/*
+Get returns the store of the key and the function to release it, the store should not be closed by the user but be released,
+the store is opened if it is not cached, the version of the config differs from the cached one, or the cached one fails the check
+*/
+func (c *StoreCache) Get(key, version string, open func() (FileStore, error)) (FileStore, func(), error) {
+	c.mu.Lock()
+	e := c.entries[key]
+	if e != nil && e.version != version {
+		c.removeLocked(key, e)
+		e = nil
+	}
+	if e != nil {
+		e.refs++
+		check := time.Since(e.lastCheck) >= c.CheckInterval
+		c.mu.Unlock()
+		if !check || ping(e.store) == nil {
+			c.mu.Lock()
+			e.lastCheck = time.Now()
+			c.mu.Unlock()
+			return e.store, c.releaser(e), nil
+		}
+		// the broken store is closed after the others release it
+		c.mu.Lock()
+		if c.entries[key] == e {
+			c.removeLocked(key, e)
+		}
+		c.mu.Unlock()
+		c.release(e)
+	} else {
+		c.mu.Unlock()
+	}
+
+	// the store is opened without the lock, as connecting may be slow
+	store, err := open()
+	if err != nil {
+		return nil, nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached := c.entries[key]; cached != nil && cached.version == version {
+		// another request opened the store meanwhile
+		store.Close()
+		cached.refs++
+		return cached.store, c.releaser(cached), nil
+	}
+	if cached := c.entries[key]; cached != nil {
+		c.removeLocked(key, cached)
+	}
+	e = &cacheEntry{store: store, version: version, refs: 1, lastUsed: time.Now(), lastCheck: time.Now()}
+	c.entries[key] = e
+	return store, c.releaser(e), nil
+}
+
+// Invalidate removes the store of the key, e.g. the datasource is updated or removed, it is closed once it is not used
+func (c *StoreCache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.entries[key]; e != nil {
+		c.removeLocked(key, e)
+	}
+}
+
+// Len is the count of the stores cached
+func (c *StoreCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Close stops the eviction and closes the stores which are not used, the others are closed when they are released
+func (c *StoreCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.stop:
+		return
+	default:
+		close(c.stop)
+	}
+	for key, e := range c.entries {
+		c.removeLocked(key, e)
+	}
+}
+
+func (c *StoreCache) releaser(e *cacheEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { c.release(e) })
+	}
+}
+
+func (c *StoreCache) release(e *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refs--
+	e.lastUsed = time.Now()
+	if e.stale && e.refs == 0 {
+		e.store.Close()
+	}
+}
+
+func (c *StoreCache) removeLocked(key string, e *cacheEntry) {
+	delete(c.entries, key)
+	e.stale = true
+	if e.refs == 0 {
+		e.store.Close()
+	}
+}
+
+func (c *StoreCache) evictLoop() {
+	interval := c.IdleTimeout / 2
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.evictIdle()
+		}
+	}
+}
+
+func (c *StoreCache) evictIdle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if e.refs == 0 && time.Since(e.lastUsed) >= c.IdleTimeout {
+			c.removeLocked(key, e)
+		}
+	}
+}
+
+func ping(store FileStore) error {
+	if p, ok := store.(Pinger); ok {
+		return p.Ping()
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package filestore
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	FileStore
+	closed int32
+	broken int32
+}
+
+func (s *fakeStore) Close() error {
+	atomic.AddInt32(&s.closed, 1)
+	return nil
+}
+
+func (s *fakeStore) Ping() error {
+	if atomic.LoadInt32(&s.broken) == 1 {
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+func TestStoreCache(t *testing.T) {
+	c := NewStoreCache(time.Hour, 0)
+	defer c.Close()
+	var opened []*fakeStore
+	open := func() (FileStore, error) {
+		s := &fakeStore{}
+		opened = append(opened, s)
+		return s, nil
+	}
+
+	s1, release1, err := c.Get("ds", "v1", open)
+	assert.Nil(t, err)
+	s2, release2, err := c.Get("ds", "v1", open)
+	assert.Nil(t, err)
+	assert.Same(t, s1, s2)
+	assert.Len(t, opened, 1)
+	release1()
+	release1()
+	release2()
+	assert.Equal(t, int32(0), opened[0].closed)
+
+	// the store of the config changed is reopened, the old one is closed as it is not used
+	s3, release3, err := c.Get("ds", "v2", open)
+	assert.Nil(t, err)
+	assert.NotSame(t, s1, s3)
+	assert.Equal(t, int32(1), opened[0].closed)
+
+	// the store invalidated is closed after it is released
+	c.Invalidate("ds")
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, int32(0), opened[1].closed)
+	release3()
+	assert.Equal(t, int32(1), opened[1].closed)
+
+	// the broken store is reopened
+	s4, release4, err := c.Get("ds", "v2", open)
+	assert.Nil(t, err)
+	release4()
+	opened[2].broken = 1
+	s5, release5, err := c.Get("ds", "v2", open)
+	assert.Nil(t, err)
+	assert.NotSame(t, s4, s5)
+	assert.Equal(t, int32(1), opened[2].closed)
+	release5()
+
+	_, _, err = c.Get("other", "v1", func() (FileStore, error) { return nil, errors.New("refused") })
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestStoreCache_Evict(t *testing.T) {
+	c := NewStoreCache(20*time.Millisecond, time.Minute)
+	defer c.Close()
+	idle, inUse := &fakeStore{}, &fakeStore{}
+	_, releaseIdle, err := c.Get("idle", "v1", func() (FileStore, error) { return idle, nil })
+	assert.Nil(t, err)
+	releaseIdle()
+	_, releaseInUse, err := c.Get("in-use", "v1", func() (FileStore, error) { return inUse, nil })
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&idle.closed) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&inUse.closed))
+	releaseInUse()
+	assert.Eventually(t, func() bool { return c.Len() == 0 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inUse.closed))
+}
+
+func TestStoreCache_Concurrent(t *testing.T) {
+	c := NewStoreCache(time.Hour, 0)
+	var opens int32
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, release, err := c.Get("ds", "v1", func() (FileStore, error) {
+				atomic.AddInt32(&opens, 1)
+				return &fakeStore{}, nil
+			})
+			assert.Nil(t, err)
+			release()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, c.Len())
+	c.Close()
+	assert.Equal(t, 0, c.Len())
+}
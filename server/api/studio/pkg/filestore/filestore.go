@@ -1,14 +1,33 @@
 package filestore
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
 )
 
 type (
 	FileStore interface {
 		ReadFile(path string, startLine ...int) ([]string, error)
 		ListFiles(dir string) ([]FileConfig, error)
+		// ListPage lists a page of the files in the dir filtered by the options
+		ListPage(dir string, opts ListOptions) (*FilePage, error)
+		// Open streams the whole file
+		Open(path string) (io.ReadCloser, error)
+		// OpenRange streams length bytes from the offset, it reads to the end of the file if length is negative
+		OpenRange(path string, offset, length int64) (io.ReadCloser, error)
+		Stat(path string) (*FileInfo, error)
+		// WriteFile creates or overwrites the file with the content read from r
+		WriteFile(path string, r io.Reader) error
 		Close() error
 	}
 
@@ -18,11 +37,45 @@ type (
 		Size int64
 	}
 
+	FileInfo struct {
+		Name    string
+		Size    int64
+		ModTime time.Time
+		IsDir   bool
+	}
+
+	// ListOptions filters and pages the files listed, the directories are always listed for navigation unless they are out of the prefix
+	ListOptions struct {
+		// Prefix filters the names of the files and the directories
+		Prefix string
+		// Marker is the NextMarker of the last page
+		Marker string
+		// Limit is the most files in a page, all the files are listed if it is not positive
+		Limit int
+		// Pattern is the glob pattern of the names of the files, e.g. *.csv
+		Pattern string
+		// Types are the types of the files, e.g. csv and parquet
+		Types []string
+	}
+
+	// FilePage is a page of the files, NextMarker is empty on the last page
+	FilePage struct {
+		Files      []FileConfig
+		NextMarker string
+	}
+
+	readCloser struct {
+		io.Reader
+		io.Closer
+	}
+
 	SftpConfig struct {
-		Host     string
-		Port     int
-		Username string
-		Password string
+		Host               string
+		Port               int
+		Username           string
+		Password           string
+		HostKeyFingerprint string
+		KnownHosts         string
 	}
 
 	S3Config struct {
@@ -32,9 +85,84 @@ type (
 		AccessKeyID  string
 		AccessSecret string
 	}
+
+	// SQLConfig is the connection of the database, the database of sqlite is the path of the file
+	SQLConfig struct {
+		Driver   string
+		Host     string
+		Port     int
+		Database string
+		Username string
+		Params   string
+	}
+
+	// LocalConfig is the dir of the server read, which must be under one of the local roots allowed in the config
+	LocalConfig struct {
+		Root string
+	}
+
+	// HTTPConfig is the base url of the directory index or the urls of the files, the secret is the password or the token
+	HTTPConfig struct {
+		URL      string
+		URLs     []string
+		AuthType string
+		Username string
+	}
 )
 
-func NewFileStore(typ, config, secret, platform string) (FileStore, error) {
+// FileTypes are the types of the files listed, the files of the other types are typed as file, and the tables of the databases are table or view
+var FileTypes = []string{"directory", "csv", "json", "jsonl", "parquet", "pdf", "txt", "gz", "file", "table", "view"}
+
+// FileType tells the type of the file by its name
+func FileType(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".csv"):
+		return "csv"
+	case strings.HasSuffix(lower, ".jsonl"), strings.HasSuffix(lower, ".ndjson"):
+		return "jsonl"
+	case strings.HasSuffix(lower, ".json"):
+		return "json"
+	case IsParquetFile(lower):
+		return "parquet"
+	case strings.HasSuffix(lower, ".pdf"):
+		return "pdf"
+	case strings.HasSuffix(lower, ".txt"):
+		return "txt"
+	case strings.HasSuffix(lower, ".gz"):
+		return "gz"
+	}
+	return "file"
+}
+
+// Validate checks the pattern and the types
+func (o *ListOptions) Validate() error {
+	if _, err := path.Match(o.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %s: %s", o.Pattern, err)
+	}
+	for _, t := range o.Types {
+		if !utils.Contains(FileTypes, t) {
+			return fmt.Errorf("unknown file type %s", t)
+		}
+	}
+	return nil
+}
+
+// Match tells whether the file is listed, the prefix of the names is matched by the stores
+func (o *ListOptions) Match(f FileConfig) bool {
+	if f.Type == "directory" {
+		return true
+	}
+	if o.Pattern != "" {
+		if ok, _ := path.Match(o.Pattern, f.Name); !ok {
+			return false
+		}
+	}
+	return len(o.Types) == 0 || utils.Contains(o.Types, f.Type)
+}
+
+// NewFileStore connects the store of the datasource, the key is the private key of sftp
+func NewFileStore(typ, config, secret, platform string, key ...SftpKey) (FileStore, error) {
 	switch typ {
 	case "s3":
 		var c S3Config
@@ -47,8 +175,92 @@ func NewFileStore(typ, config, secret, platform string) (FileStore, error) {
 		if err := json.Unmarshal([]byte(config), &c); err != nil {
 			return nil, errors.New("parse the s3 config error")
 		}
-		return NewSftpStore(c.Host, c.Port, c.Username, secret)
+		auth := SftpAuth{Password: secret, HostKeyFingerprint: c.HostKeyFingerprint, KnownHosts: c.KnownHosts}
+		if len(key) > 0 {
+			auth.SftpKey = key[0]
+		}
+		return NewSftpStoreWithAuth(c.Host, c.Port, c.Username, auth)
+	case "sql":
+		var c SQLConfig
+		if err := json.Unmarshal([]byte(config), &c); err != nil {
+			return nil, errors.New("parse the sql config error")
+		}
+		return NewSQLStore(c.Driver, c.Host, c.Port, c.Database, c.Username, secret, c.Params, LocalRoots())
+	case "local":
+		var c LocalConfig
+		if err := json.Unmarshal([]byte(config), &c); err != nil {
+			return nil, errors.New("parse the local config error")
+		}
+		return NewLocalStore(c.Root, LocalRoots())
+	case "http":
+		var c HTTPConfig
+		if err := json.Unmarshal([]byte(config), &c); err != nil {
+			return nil, errors.New("parse the http config error")
+		}
+		return NewHTTPStore(c.URL, c.URLs, c.AuthType, c.Username, secret)
 	}
 
 	return nil, errors.New("don't support this store type")
 }
+
+// LocalRoots are the dirs of the server allowed to be read by the local datasources and the sqlite datasources
+func LocalRoots() []string {
+	if c := config.GetConfig(); c != nil {
+		return c.Datasource.LocalRoots
+	}
+	return nil
+}
+
+// readLines reads numLines lines from the start line, all the lines after the start line are read if numLines is negative
+func readLines(r io.Reader, start, numLines int) ([]string, error) {
+	fileScanner := bufio.NewScanner(r)
+
+	var lines []string
+	for i := 0; i < start; i++ {
+		if !fileScanner.Scan() {
+			return nil, errors.New("start line is beyond end of file")
+		}
+	}
+
+	for i := 0; numLines < 0 || i < numLines; i++ {
+		if !fileScanner.Scan() {
+			break
+		}
+		lines = append(lines, fileScanner.Text())
+	}
+
+	if err := fileScanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// lineRange parses the start line and the count of the lines of ReadFile, the count is negative without limit
+func lineRange(startLine []int) (start, numLines int) {
+	switch len(startLine) {
+	case 0:
+		return 0, -1
+	case 1:
+		return startLine[0], -1
+	default:
+		return startLine[0], startLine[1]
+	}
+}
+
+// pageFiles pages the files listed at once by their names, the marker is the name of the last file of the previous page
+func pageFiles(files []FileConfig, opts ListOptions) *FilePage {
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	page := &FilePage{Files: []FileConfig{}}
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name, opts.Prefix) || (opts.Marker != "" && f.Name <= opts.Marker) || !opts.Match(f) {
+			continue
+		}
+		if opts.Limit > 0 && len(page.Files) == opts.Limit {
+			page.NextMarker = page.Files[opts.Limit-1].Name
+			break
+		}
+		page.Files = append(page.Files, f)
+	}
+	return page
+}
@@ -0,0 +1,330 @@
+package filestore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	HTTPAuthNone   = "none"
+	HTTPAuthBasic  = "basic"
+	HTTPAuthBearer = "bearer"
+)
+
+// httpTimeout is the timeout to connect and to wait for the response header, the body is streamed without timeout
+const httpTimeout = 30 * time.Second
+
+// hrefPattern matches the links of the directory index, e.g. the autoindex of nginx or the index of apache
+var hrefPattern = regexp.MustCompile(`(?i)<a\s[^>]*?href\s*=\s*["']([^"']+)["']`)
+
+/*
+HTTPStore reads the files served over http(s), the files are listed from the directory index of the base url or from the urls given,
+the paths are relative to the base url or the absolute urls, the store is read only
+*/
+type HTTPStore struct {
+	BaseURL string
+	URLs    []string
+	// AuthType is none, basic or bearer, the password is the token of bearer
+	AuthType string
+	Username string
+	Password string
+	Client   *http.Client
+
+	base *url.URL
+}
+
+func NewHTTPStore(baseURL string, urls []string, authType, username, password string) (*HTTPStore, error) {
+	s := &HTTPStore{
+		BaseURL:  baseURL,
+		URLs:     urls,
+		AuthType: authType,
+		Username: username,
+		Password: password,
+		Client: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			TLSHandshakeTimeout:   httpTimeout,
+			ResponseHeaderTimeout: httpTimeout,
+		}},
+	}
+	switch authType {
+	case "", HTTPAuthNone, HTTPAuthBasic, HTTPAuthBearer:
+	default:
+		return nil, fmt.Errorf("unknown auth type %s", authType)
+	}
+	if baseURL != "" {
+		base, err := parseHTTPURL(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		// the base is a dir, the paths are resolved under it
+		if !strings.HasSuffix(base.Path, "/") {
+			base.Path += "/"
+		}
+		s.base = base
+	}
+	for _, u := range urls {
+		if _, err := s.Resolve(u); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func parseHTTPURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid http url: %s", rawURL)
+	}
+	return u, nil
+}
+
+/*
+Resolve returns the url of the path, the path is an absolute url or relative to the base url,
+the absolute url must be on the host of the base url or be one of the urls given, as the credentials are sent with it,
+only the urls given are read without the base url
+*/
+func (s *HTTPStore) Resolve(p string) (string, error) {
+	if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
+		u, err := parseHTTPURL(p)
+		if err != nil {
+			return "", err
+		}
+		if s.base != nil && u.Host == s.base.Host {
+			return u.String(), nil
+		}
+		for _, listed := range s.URLs {
+			if listed == p {
+				return p, nil
+			}
+		}
+		if s.base == nil {
+			return "", fmt.Errorf("the url %s is not one of the urls given", p)
+		}
+		return "", fmt.Errorf("the url %s is not on the host %s", p, s.base.Host)
+	}
+	if s.base == nil {
+		return "", fmt.Errorf("the path %s is not a url", p)
+	}
+	ref, err := url.Parse(strings.TrimPrefix(p, "/"))
+	if err != nil {
+		return "", err
+	}
+	u := s.base.ResolveReference(ref)
+	if !strings.HasPrefix(u.Path, s.base.Path) {
+		return "", fmt.Errorf("the path %s is out of the base url", p)
+	}
+	return u.String(), nil
+}
+
+// ReadFile streams the file until the lines are read
+func (s *HTTPStore) ReadFile(path string, startLine ...int) ([]string, error) {
+	start, numLines := lineRange(startLine)
+	body, err := s.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return readLines(body, start, numLines)
+}
+
+// ListFiles lists the urls given in the root, or the links in the directory index of the dir which are right under it
+func (s *HTTPStore) ListFiles(dir string) ([]FileConfig, error) {
+	if len(s.URLs) > 0 && (dir == "" || dir == "/") {
+		files := make([]FileConfig, 0, len(s.URLs))
+		for _, u := range s.URLs {
+			name := u
+			if s.base != nil && strings.HasPrefix(u, s.base.String()) {
+				name = strings.TrimPrefix(u, s.base.String())
+			}
+			files = append(files, FileConfig{Name: name, Type: FileType(strings.SplitN(name, "?", 2)[0])})
+		}
+		return files, nil
+	}
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	dirURL, err := s.Resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(http.MethodGet, dirURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// the index is small, a page larger than it is not an index
+	page, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	return indexFiles(dirURL, string(page))
+}
+
+// indexFiles parses the links in the directory index, the links out of the dir and the links to sort the index are skipped
+func indexFiles(dirURL, page string) ([]FileConfig, error) {
+	base, err := url.Parse(dirURL)
+	if err != nil {
+		return nil, err
+	}
+	var files []FileConfig
+	seen := map[string]bool{}
+	for _, match := range hrefPattern.FindAllStringSubmatch(page, -1) {
+		href := strings.ReplaceAll(match[1], "&amp;", "&")
+		ref, err := url.Parse(href)
+		if err != nil || ref.RawQuery != "" || ref.Fragment != "" {
+			continue
+		}
+		u := base.ResolveReference(ref)
+		if u.Host != base.Host || !strings.HasPrefix(u.Path, base.Path) {
+			continue
+		}
+		name := strings.TrimPrefix(u.Path, base.Path)
+		isDir := strings.HasSuffix(name, "/")
+		name = strings.TrimSuffix(name, "/")
+		if name == "" || strings.Contains(name, "/") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if isDir {
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
+			files = append(files, FileConfig{Name: name, Type: "directory"})
+			continue
+		}
+		files = append(files, FileConfig{Name: name, Type: FileType(name)})
+	}
+	return files, nil
+}
+
+// ListPage pages the files of the index by their names, the sizes are unknown in the index
+func (s *HTTPStore) ListPage(dir string, opts ListOptions) (*FilePage, error) {
+	files, err := s.ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return pageFiles(files, opts), nil
+}
+
+func (s *HTTPStore) Open(path string) (io.ReadCloser, error) {
+	u, err := s.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// OpenRange requests the range of the file, the bytes before the offset are skipped if the server does not support ranges
+func (s *HTTPStore) OpenRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	u, err := s.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	rng := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		rng += fmt.Sprint(offset + length - 1)
+	}
+	resp, err := s.do(http.MethodGet, u, http.Header{"Range": {rng}})
+	if err != nil {
+		var statusErr *httpStatusError
+		if errors.As(err, &statusErr) && statusErr.code == http.StatusRequestedRangeNotSatisfiable {
+			// the offset is beyond the end of the file
+			return io.NopCloser(strings.NewReader("")), nil
+		}
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK && offset > 0 {
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil && err != io.EOF {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	if length < 0 || resp.StatusCode == http.StatusPartialContent {
+		return resp.Body, nil
+	}
+	return &readCloser{Reader: io.LimitReader(resp.Body, length), Closer: resp.Body}, nil
+}
+
+// Stat requests the head of the file, the size is unknown if the server does not tell the length
+func (s *HTTPStore) Stat(p string) (*FileInfo, error) {
+	u, err := s.Resolve(p)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(http.MethodHead, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	info := &FileInfo{
+		Name:  path.Base(resp.Request.URL.Path),
+		Size:  resp.ContentLength,
+		IsDir: strings.HasSuffix(resp.Request.URL.Path, "/"),
+	}
+	if info.Size < 0 {
+		info.Size = 0
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info, nil
+}
+
+func (s *HTTPStore) WriteFile(string, io.Reader) error {
+	return errors.New("the files can not be written over http")
+}
+
+func (s *HTTPStore) Close() error {
+	s.Client.CloseIdleConnections()
+	return nil
+}
+
+type httpStatusError struct {
+	url    string
+	code   int
+	status string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("request %s failed: %s", e.url, e.status)
+}
+
+// do sends the request with the credentials, the response which is not 2xx is closed and returned as the error
+func (s *HTTPStore) do(method, u string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	switch s.AuthType {
+	case HTTPAuthBasic:
+		req.SetBasicAuth(s.Username, s.Password)
+	case HTTPAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+s.Password)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &httpStatusError{url: u, code: resp.StatusCode, status: resp.Status}
+	}
+	return resp, nil
+}
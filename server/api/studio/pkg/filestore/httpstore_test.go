@@ -0,0 +1,98 @@
+package filestore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPStore(t *testing.T) {
+	content := "id,name\n1,Tom\n2,Jerry\n"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/data/", func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "root" || password != "nebula" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/data/":
+			io.WriteString(w, `<html><body><h1>Index of /data/</h1>
+<a href="?C=N;O=D">Name</a> <a href="../">Parent Directory</a>
+<a href="person.csv">person.csv</a> <a href="/data/sub/">sub/</a>
+<a href="http://example.com/other.csv">other.csv</a> <a href="sub/nested.csv">nested.csv</a>
+</body></html>`)
+		case "/data/person.csv":
+			http.ServeContent(w, r, "person.csv", time.Unix(1700000000, 0), strings.NewReader(content))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, err := NewHTTPStore("ftp://127.0.0.1/data", nil, "", "", "")
+	assert.NotNil(t, err)
+	store, err := NewHTTPStore(server.URL+"/data", nil, HTTPAuthBasic, "root", "nebula")
+	assert.Nil(t, err)
+	defer store.Close()
+
+	files, err := store.ListFiles("")
+	assert.Nil(t, err)
+	assert.Equal(t, []FileConfig{{Type: "csv", Name: "person.csv"}, {Type: "directory", Name: "sub"}}, files)
+	lines, err := store.ReadFile("person.csv", 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1,Tom"}, lines)
+	r, err := store.OpenRange("person.csv", 8, 5)
+	assert.Nil(t, err)
+	b, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "1,Tom", string(b))
+	r, err = store.OpenRange("person.csv", 100, -1)
+	assert.Nil(t, err)
+	b, _ = io.ReadAll(r)
+	assert.Empty(t, b)
+	info, err := store.Stat("person.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)), info.Size)
+
+	// the credentials are not sent to the other hosts
+	_, err = store.Resolve("http://example.com/other.csv")
+	assert.NotNil(t, err)
+	_, err = store.Resolve("../secret.csv")
+	assert.NotNil(t, err)
+	unauthorized, err := NewHTTPStore(server.URL+"/data/", nil, HTTPAuthNone, "", "")
+	assert.Nil(t, err)
+	_, err = unauthorized.ReadFile("person.csv")
+	assert.NotNil(t, err)
+
+	// the urls are listed instead of the index
+	listed, err := NewHTTPStore(server.URL+"/data/", []string{"person.csv", "http://example.com/other.json"}, HTTPAuthBasic, "root", "nebula")
+	assert.Nil(t, err)
+	files, err = listed.ListFiles("")
+	assert.Nil(t, err)
+	assert.Equal(t, []FileConfig{{Type: "csv", Name: "person.csv"}, {Type: "json", Name: "http://example.com/other.json"}}, files)
+	_, err = listed.Resolve("http://example.com/other.json")
+	assert.Nil(t, err)
+
+	// only the urls given are read without the base url
+	urlsOnly, err := NewHTTPStore("", []string{server.URL + "/data/person.csv"}, HTTPAuthBasic, "root", "nebula")
+	assert.Nil(t, err)
+	lines, err = urlsOnly.ReadFile(server.URL+"/data/person.csv", 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1,Tom"}, lines)
+	for _, p := range []string{server.URL + "/data/", server.URL + "/data/sub/nested.csv", "http://169.254.169.254/latest/meta-data", "person.csv"} {
+		_, err = urlsOnly.Resolve(p)
+		assert.NotNil(t, err, p)
+		_, err = urlsOnly.Open(p)
+		assert.NotNil(t, err, p)
+		_, err = urlsOnly.Stat(p)
+		assert.NotNil(t, err, p)
+	}
+	_, err = NewHTTPStore("", []string{"person.csv"}, HTTPAuthNone, "", "")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,198 @@
+package filestore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// LocalStore reads the files under the root dir of the server, e.g. a mounted NFS share, the paths are relative to the root
+type LocalStore struct {
+	Root string
+}
+
+// NewLocalStore checks that the root is an allowed dir or under one of them, no local dir is allowed without the allowed roots
+func NewLocalStore(root string, allowedRoots []string) (*LocalStore, error) {
+	if root == "" {
+		return nil, errors.New("the root of the local datasource is required")
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	abs = realPath(abs)
+	if !underRoots(abs, allowedRoots) {
+		return nil, fmt.Errorf("the dir %s is not allowed to be read", root)
+	}
+	return &LocalStore{Root: abs}, nil
+}
+
+// Resolve returns the absolute path of the path relative to the root, the path can not be out of the root even by the symlinks
+func (s *LocalStore) Resolve(path string) (string, error) {
+	abs := filepath.Join(s.Root, filepath.Clean("/"+filepath.FromSlash(path)))
+	// the file written may not exist, the symlinks of its dir are checked
+	real := realPath(abs)
+	if _, err := os.Lstat(abs); os.IsNotExist(err) {
+		real = filepath.Join(realPath(filepath.Dir(abs)), filepath.Base(abs))
+	}
+	if !isUnder(s.Root, real) {
+		return "", fmt.Errorf("the path %s is out of the root", path)
+	}
+	return abs, nil
+}
+
+func (s *LocalStore) ReadFile(path string, startLine ...int) ([]string, error) {
+	start, numLines := lineRange(startLine)
+	f, err := s.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readLines(f, start, numLines)
+}
+
+func (s *LocalStore) ListFiles(dir string) ([]FileConfig, error) {
+	abs, err := s.Resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return nil, err
+	}
+	var files []FileConfig
+	for _, entry := range entries {
+		name := entry.Name()
+		info, err := entry.Info()
+		if err != nil {
+			// the file is removed after the dir is read
+			continue
+		}
+		fileType := FileType(name)
+		if info.IsDir() {
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
+			fileType = "directory"
+		}
+		files = append(files, FileConfig{
+			Name: name,
+			Size: info.Size(),
+			Type: fileType,
+		})
+	}
+	return files, nil
+}
+
+func (s *LocalStore) ListPage(dir string, opts ListOptions) (*FilePage, error) {
+	files, err := s.ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return pageFiles(files, opts), nil
+}
+
+func (s *LocalStore) Open(path string) (io.ReadCloser, error) {
+	abs, err := s.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(abs)
+}
+
+func (s *LocalStore) OpenRange(path string, offset, length int64) (io.ReadCloser, error) {
+	f, err := s.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.(*os.File).Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return &readCloser{Reader: io.LimitReader(f, length), Closer: f}, nil
+}
+
+func (s *LocalStore) Stat(path string) (*FileInfo, error) {
+	abs, err := s.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	return &FileInfo{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+	}, nil
+}
+
+func (s *LocalStore) WriteFile(path string, r io.Reader) error {
+	abs, err := s.Resolve(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(abs), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(abs)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func (s *LocalStore) OpenParquetFile(path string) (source.ParquetFile, error) {
+	abs, err := s.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return OpenLocalParquetFile(abs)
+}
+
+func (s *LocalStore) Close() error {
+	return nil
+}
+
+// realPath follows the symlinks of the path, the path is returned as it is if it does not exist
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return path
+}
+
+// underRoots tells whether the real path is one of the allowed roots or under them, the symlinks of the roots are followed
+func underRoots(path string, allowedRoots []string) bool {
+	for _, allowed := range allowedRoots {
+		if allowed == "" {
+			continue
+		}
+		allowedAbs, err := filepath.Abs(allowed)
+		if err != nil {
+			continue
+		}
+		if isUnder(realPath(allowedAbs), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnder tells whether the path is the dir or under it
+func isUnder(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
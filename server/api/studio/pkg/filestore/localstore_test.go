@@ -0,0 +1,52 @@
+package filestore
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+	allowed := t.TempDir()
+	root := filepath.Join(allowed, "share")
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "dir"), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "person.csv"), []byte("id,name\n1,Tom\n2,Jerry\n"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(allowed, "secret.txt"), []byte("secret"), 0o644))
+	assert.Nil(t, os.Symlink(filepath.Join(allowed, "secret.txt"), filepath.Join(root, "link.txt")))
+
+	_, err := NewLocalStore(root, nil)
+	assert.NotNil(t, err)
+	_, err = NewLocalStore(root, []string{filepath.Join(allowed, "other")})
+	assert.NotNil(t, err)
+	store, err := NewLocalStore(root, []string{allowed})
+	assert.Nil(t, err)
+
+	page, err := store.ListPage("/", ListOptions{Types: []string{"csv"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []FileConfig{{Type: "directory", Name: "dir", Size: page.Files[0].Size}, {Type: "csv", Name: "person.csv", Size: 22}}, page.Files)
+
+	lines, err := store.ReadFile("person.csv", 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1,Tom"}, lines)
+	r, err := store.OpenRange("person.csv", 8, 5)
+	assert.Nil(t, err)
+	content, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "1,Tom", string(content))
+	info, err := store.Stat("dir")
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir)
+
+	// the paths can not be out of the root
+	abs, err := store.Resolve("../secret.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(store.Root, "secret.txt"), abs)
+	_, err = store.Open("link.txt")
+	assert.NotNil(t, err)
+	assert.NotNil(t, store.WriteFile("link.txt", nil))
+	_, err = NewLocalStore(filepath.Join(root, "..", ".."), []string{allowed})
+	assert.NotNil(t, err)
+}
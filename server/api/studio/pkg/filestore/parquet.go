@@ -0,0 +1,290 @@
+package filestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	pqtypes "github.com/xitongsys/parquet-go/types"
+)
+
+// datetimeLayout is the layout of the timestamps read from the parquet file or the database, which can be parsed by datetime() of nGQL
+const datetimeLayout = "2006-01-02T15:04:05.999999"
+
+var errParquetReadOnly = errors.New("the parquet file is read only")
+
+type (
+	// ParquetOpener is implemented by the stores which can read the file randomly, the footer of the parquet file is at its end
+	ParquetOpener interface {
+		OpenParquetFile(path string) (source.ParquetFile, error)
+	}
+
+	// ParquetColumn is a top-level column of the parquet file, the nested columns are read as json
+	ParquetColumn struct {
+		Name string
+		Type string
+	}
+
+	// ParquetReader reads the rows of the parquet file, each value of the row is formatted as the csv value of the column
+	ParquetReader struct {
+		Columns []ParquetColumn
+		pf      source.ParquetFile
+		pr      *reader.ParquetReader
+		schemas []*parquet.SchemaElement
+		read    int64
+	}
+
+	localParquetFile struct {
+		*os.File
+		path string
+	}
+
+	s3ParquetFile struct {
+		store  *S3Store
+		key    string
+		size   int64
+		offset int64
+		// buf caches the range read ahead from bufOff
+		buf    []byte
+		bufOff int64
+	}
+)
+
+// s3ReadAhead is the least bytes fetched in one ranged request, the pages of the parquet file are read in small pieces
+const s3ReadAhead = 1 << 20
+
+// NewParquetReader reads the footer of the parquet file, the file is closed with the reader
+func NewParquetReader(pf source.ParquetFile) (*ParquetReader, error) {
+	pr, err := reader.NewParquetReader(pf, nil, 1)
+	if err != nil {
+		pf.Close()
+		return nil, fmt.Errorf("read the parquet footer failed: %w", err)
+	}
+	r := &ParquetReader{pf: pf, pr: pr}
+	schemas := pr.Footer.GetSchema()
+	if len(schemas) == 0 {
+		r.Close()
+		return nil, errors.New("the parquet file has no schema")
+	}
+	// the children of the root are the top-level columns, the schema elements are listed in depth-first order,
+	// the names in the footer have been renamed for the go structs, the names in the file are in the infos
+	for idx := 1; idx < len(schemas); idx = skipSchemaElement(schemas, idx) {
+		r.Columns = append(r.Columns, ParquetColumn{Name: pr.SchemaHandler.Infos[idx].ExName, Type: parquetTypeName(schemas[idx])})
+		r.schemas = append(r.schemas, schemas[idx])
+	}
+	return r, nil
+}
+
+// OpenLocalParquetFile opens the local parquet file
+func OpenLocalParquetFile(path string) (source.ParquetFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &localParquetFile{File: f, path: path}, nil
+}
+
+// NumRows is the count of the rows in the parquet file
+func (r *ParquetReader) NumRows() int64 {
+	return r.pr.GetNumRows()
+}
+
+// EstimatedSize is the uncompressed size of the rows, which is close to the size of the rows in csv
+func (r *ParquetReader) EstimatedSize() int64 {
+	var size int64
+	for _, rg := range r.pr.Footer.GetRowGroups() {
+		size += rg.GetTotalByteSize()
+	}
+	return size
+}
+
+// Read reads at most n rows, the values are in the order of the columns, the null value is empty, io.EOF is returned after the last row
+func (r *ParquetReader) Read(n int) ([][]string, error) {
+	remaining := r.NumRows() - r.read
+	if remaining <= 0 {
+		return nil, io.EOF
+	}
+	if int64(n) > remaining {
+		n = int(remaining)
+	}
+	objs, err := r.pr.ReadByNumber(n)
+	if err != nil {
+		return nil, err
+	}
+	r.read += int64(len(objs))
+	rows := make([][]string, 0, len(objs))
+	for _, obj := range objs {
+		v := reflect.ValueOf(obj)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		row := make([]string, len(r.schemas))
+		for i := range row {
+			if v.IsValid() && i < v.NumField() {
+				row[i] = parquetValueString(v.Field(i), r.schemas[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (r *ParquetReader) Close() error {
+	r.pr.ReadStop()
+	return r.pf.Close()
+}
+
+// skipSchemaElement returns the index after the element and its descendants
+func skipSchemaElement(schemas []*parquet.SchemaElement, idx int) int {
+	children := schemas[idx].GetNumChildren()
+	idx++
+	for i := int32(0); i < children && idx < len(schemas); i++ {
+		idx = skipSchemaElement(schemas, idx)
+	}
+	return idx
+}
+
+func parquetTypeName(se *parquet.SchemaElement) string {
+	if se.GetNumChildren() > 0 {
+		switch {
+		case se.IsSetConvertedType() && se.GetConvertedType() == parquet.ConvertedType_LIST:
+			return "list"
+		case se.IsSetConvertedType() && (se.GetConvertedType() == parquet.ConvertedType_MAP || se.GetConvertedType() == parquet.ConvertedType_MAP_KEY_VALUE):
+			return "map"
+		}
+		return "struct"
+	}
+	if lt := se.GetLogicalType(); lt != nil {
+		switch {
+		case lt.IsSetSTRING(), lt.IsSetENUM(), lt.IsSetJSON():
+			return "string"
+		case lt.IsSetDATE():
+			return "date"
+		case lt.IsSetTIME():
+			return "time"
+		case lt.IsSetTIMESTAMP():
+			return "timestamp"
+		case lt.IsSetDECIMAL():
+			return "decimal"
+		}
+	}
+	if se.IsSetConvertedType() {
+		switch se.GetConvertedType() {
+		case parquet.ConvertedType_UTF8, parquet.ConvertedType_ENUM, parquet.ConvertedType_JSON:
+			return "string"
+		case parquet.ConvertedType_DATE:
+			return "date"
+		case parquet.ConvertedType_TIME_MILLIS, parquet.ConvertedType_TIME_MICROS:
+			return "time"
+		case parquet.ConvertedType_TIMESTAMP_MILLIS, parquet.ConvertedType_TIMESTAMP_MICROS:
+			return "timestamp"
+		case parquet.ConvertedType_DECIMAL:
+			return "decimal"
+		}
+	}
+	switch se.GetType() {
+	case parquet.Type_BOOLEAN:
+		return "bool"
+	case parquet.Type_INT32:
+		return "int32"
+	case parquet.Type_INT64:
+		return "int64"
+	case parquet.Type_INT96:
+		return "timestamp"
+	case parquet.Type_FLOAT:
+		return "float"
+	case parquet.Type_DOUBLE:
+		return "double"
+	}
+	return "binary"
+}
+
+// parquetValueString formats the value of the top-level column by its logical type
+func parquetValueString(v reflect.Value, se *parquet.SchemaElement) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if se.GetNumChildren() > 0 || v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		// the nested values are kept as json, e.g. for the string props
+		b, _ := json.Marshal(v.Interface())
+		return string(b)
+	}
+	switch typ := parquetTypeName(se); {
+	case typ == "date" && v.Kind() == reflect.Int32:
+		return time.Unix(v.Int()*24*3600, 0).UTC().Format("2006-01-02")
+	case typ == "timestamp" && v.Kind() == reflect.Int64:
+		return parquetTimestamp(v.Int(), se).Format(datetimeLayout)
+	case typ == "timestamp" && se.GetType() == parquet.Type_INT96:
+		return pqtypes.INT96ToTime(v.String()).UTC().Format(datetimeLayout)
+	case typ == "decimal" && (v.Kind() == reflect.Int32 || v.Kind() == reflect.Int64):
+		return pqtypes.DECIMAL_INT_ToString(v.Int(), int(se.GetPrecision()), int(se.GetScale()))
+	case typ == "decimal" && v.Kind() == reflect.String:
+		return pqtypes.DECIMAL_BYTE_ARRAY_ToString([]byte(v.String()), int(se.GetPrecision()), int(se.GetScale()))
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.String:
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func parquetTimestamp(value int64, se *parquet.SchemaElement) time.Time {
+	unit := "millis"
+	if lt := se.GetLogicalType(); lt != nil && lt.IsSetTIMESTAMP() {
+		switch u := lt.GetTIMESTAMP().GetUnit(); {
+		case u.IsSetMICROS():
+			unit = "micros"
+		case u.IsSetNANOS():
+			unit = "nanos"
+		}
+	} else if se.GetConvertedType() == parquet.ConvertedType_TIMESTAMP_MICROS {
+		unit = "micros"
+	}
+	switch unit {
+	case "micros":
+		return pqtypes.TIMESTAMP_MICROSToTime(value, true).UTC()
+	case "nanos":
+		return pqtypes.TIMESTAMP_NANOSToTime(value, true).UTC()
+	}
+	return pqtypes.TIMESTAMP_MILLISToTime(value, true).UTC()
+}
+
+func (f *localParquetFile) Open(name string) (source.ParquetFile, error) {
+	if name == "" {
+		name = f.path
+	}
+	return OpenLocalParquetFile(name)
+}
+
+func (f *localParquetFile) Create(string) (source.ParquetFile, error) {
+	return nil, errParquetReadOnly
+}
+
+func (f *localParquetFile) Write([]byte) (int, error) {
+	return 0, errParquetReadOnly
+}
+
+// IsParquetFile tells whether the file is a parquet file by its name
+func IsParquetFile(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".parquet")
+}
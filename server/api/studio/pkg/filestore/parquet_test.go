@@ -0,0 +1,192 @@
+package filestore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type parquetPerson struct {
+	ID       int64    `parquet:"name=id, type=INT64"`
+	Name     string   `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Score    *float64 `parquet:"name=score, type=DOUBLE, repetitiontype=OPTIONAL"`
+	Birthday int32    `parquet:"name=birthday, type=INT32, convertedtype=DATE"`
+}
+
+// memS3Client keeps the objects in memory like a local MinIO, the ranges of the objects can be read
+type memS3Client struct {
+	s3iface.S3API
+	objects map[string][]byte
+	gets    int
+	bytes   int
+}
+
+func (m *memS3Client) HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	data, ok := m.objects[*input.Key]
+	if !ok {
+		return nil, errors.New("NotFound")
+	}
+	return &s3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(data)))}, nil
+}
+
+func (m *memS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	data, ok := m.objects[*input.Key]
+	if !ok {
+		return nil, errors.New("NoSuchKey")
+	}
+	m.gets++
+	if input.Range != nil {
+		// the open range reads to the end, and the range is cut by the end of the object like s3
+		start, end := 0, len(data)-1
+		if _, err := fmt.Sscanf(*input.Range, "bytes=%d-%d", &start, &end); err != nil && !strings.HasSuffix(*input.Range, "-") {
+			return nil, err
+		}
+		if start >= len(data) {
+			return nil, awserr.New("InvalidRange", "the range is not satisfiable", nil)
+		}
+		if end >= len(data) {
+			end = len(data) - 1
+		}
+		data = data[start : end+1]
+	}
+	m.bytes += len(data)
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+// ListObjectsV2 groups the keys by the delimiter, and the continuation token is the index of the next key
+func (m *memS3Client) ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	var keys []string
+	prefixes := map[string]bool{}
+	for key := range m.objects {
+		if !strings.HasPrefix(key, *input.Prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, *input.Prefix)
+		if i := strings.Index(rest, aws.StringValue(input.Delimiter)); input.Delimiter != nil && i >= 0 {
+			key = *input.Prefix + rest[:i+1]
+			if prefixes[key] {
+				continue
+			}
+			prefixes[key] = true
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	start, _ := strconv.Atoi(aws.StringValue(input.ContinuationToken))
+	end := len(keys)
+	if max := int(aws.Int64Value(input.MaxKeys)); max > 0 && start+max < end {
+		end = start + max
+	}
+	output := &s3.ListObjectsV2Output{IsTruncated: aws.Bool(end < len(keys))}
+	if end < len(keys) {
+		output.NextContinuationToken = aws.String(strconv.Itoa(end))
+	}
+	for _, key := range keys[start:end] {
+		if prefixes[key] {
+			output.CommonPrefixes = append(output.CommonPrefixes, &s3.CommonPrefix{Prefix: aws.String(key)})
+		} else {
+			output.Contents = append(output.Contents, &s3.Object{Key: aws.String(key), Size: aws.Int64(int64(len(m.objects[key])))})
+		}
+	}
+	return output, nil
+}
+
+func writeParquet(t *testing.T, rows int) []byte {
+	var buf bytes.Buffer
+	pw, err := writer.NewParquetWriterFromWriter(&buf, new(parquetPerson), 1)
+	assert.Nil(t, err)
+	for i := 0; i < rows; i++ {
+		p := &parquetPerson{ID: int64(i), Name: fmt.Sprintf("person,%d", i), Birthday: int32(i)}
+		if i%2 == 0 {
+			score := float64(i) + 0.5
+			p.Score = &score
+		}
+		assert.Nil(t, pw.Write(p))
+	}
+	assert.Nil(t, pw.WriteStop())
+	return buf.Bytes()
+}
+
+func readAllParquet(t *testing.T, r *ParquetReader) [][]string {
+	var rows [][]string
+	for {
+		batch, err := r.Read(3)
+		if err == io.EOF {
+			return rows
+		}
+		assert.Nil(t, err)
+		rows = append(rows, batch...)
+	}
+}
+
+func TestParquetReader_Local(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "person.parquet")
+	assert.Nil(t, os.WriteFile(path, writeParquet(t, 5), 0o644))
+
+	pf, err := OpenLocalParquetFile(path)
+	assert.Nil(t, err)
+	r, err := NewParquetReader(pf)
+	assert.Nil(t, err)
+	defer r.Close()
+
+	assert.Equal(t, []ParquetColumn{
+		{Name: "id", Type: "int64"},
+		{Name: "name", Type: "string"},
+		{Name: "score", Type: "double"},
+		{Name: "birthday", Type: "date"},
+	}, r.Columns)
+	assert.Equal(t, int64(5), r.NumRows())
+	rows := readAllParquet(t, r)
+	assert.Equal(t, [][]string{
+		{"0", "person,0", "0.5", "1970-01-01"},
+		{"1", "person,1", "", "1970-01-02"},
+		{"2", "person,2", "2.5", "1970-01-03"},
+		{"3", "person,3", "", "1970-01-04"},
+		{"4", "person,4", "4.5", "1970-01-05"},
+	}, rows)
+}
+
+func TestParquetReader_S3(t *testing.T) {
+	client := &memS3Client{objects: map[string][]byte{
+		"lake/person.parquet": writeParquet(t, 10),
+		"lake/person.csv":     []byte("id,name\n"),
+		"lake/readme.txt":     []byte("readme"),
+	}}
+	store := &S3Store{Bucket: "test-bucket", S3Client: client}
+
+	files, err := store.ListFiles("lake/")
+	assert.Nil(t, err)
+	types := map[string]string{}
+	for _, f := range files {
+		types[f.Name] = f.Type
+	}
+	assert.Equal(t, map[string]string{"person.parquet": "parquet", "person.csv": "csv", "readme.txt": "txt"}, types)
+
+	pf, err := store.OpenParquetFile("lake/person.parquet")
+	assert.Nil(t, err)
+	r, err := NewParquetReader(pf)
+	assert.Nil(t, err)
+	defer r.Close()
+	rows := readAllParquet(t, r)
+	assert.Len(t, rows, 10)
+	assert.Equal(t, []string{"9", "person,9", "", "1970-01-10"}, rows[9])
+	// the small file is read ahead in one range for each handle
+	assert.Less(t, client.gets, 10)
+
+	_, err = store.OpenParquetFile("lake/missing.parquet")
+	assert.NotNil(t, err)
+}
@@ -1,9 +1,11 @@
 package filestore
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
+	"io"
+	"path"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -13,12 +15,36 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/xitongsys/parquet-go/source"
 )
 
-type S3Store struct {
-	S3Client s3iface.S3API
-	Bucket   string
-}
+const (
+	// s3FirstChunk is the first range read for the lines of an object, which is enough for a preview
+	s3FirstChunk = 64 << 10
+	s3MaxChunk   = 8 << 20
+	// s3MaxListRequests limits the pages of s3 fetched for a page of files
+	s3MaxListRequests = 10
+)
+
+type (
+	S3Store struct {
+		S3Client s3iface.S3API
+		Bucket   string
+	}
+
+	// s3RangeReader reads the object sequentially by ranged GETs
+	s3RangeReader struct {
+		store  *S3Store
+		key    string
+		offset int64
+		chunk  int64
+		// left is the bytes not read in the current range
+		left int64
+		body io.ReadCloser
+		eof  bool
+	}
+)
 
 func NewS3Store(platform, endpoint, region, bucket, accessKeyID, accessSecret string) (*S3Store, error) {
 	cfg := &aws.Config{
@@ -59,61 +85,135 @@ func NewS3Store(platform, endpoint, region, bucket, accessKeyID, accessSecret st
 	}, nil
 }
 
+/*
+ReadFile reads the lines of the object, when the count of the lines is limited,
+the object is read by growing ranges so that a preview does not download the whole object
+*/
 func (s *S3Store) ReadFile(s3path string, startLine ...int) ([]string, error) {
-	var numLines int
-	var start int
-	if len(startLine) == 0 {
-		start = 0
-		numLines = -1
-	} else if len(startLine) == 1 {
-		start = startLine[0]
-		numLines = -1
+	start, numLines := lineRange(startLine)
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	if numLines < 0 {
+		r, err = s.Open(s3path)
 	} else {
-		start = startLine[0]
-		numLines = startLine[1]
+		r, err = s.openChunked(s3path)
 	}
-
-	resp, err := s.S3Client.GetObject(&s3.GetObjectInput{
-		Bucket: aws.String(s.Bucket),
-		Key:    aws.String(s3path),
-	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	fileScanner := bufio.NewScanner(resp.Body)
+	defer r.Close()
+	return readLines(r, start, numLines)
+}
 
-	var lines []string
-	for i := 0; i < start; i++ {
-		if !fileScanner.Scan() {
-			return nil, errors.New("start line is beyond end of file")
-		}
+// ListFiles lists all the objects and the common prefixes in the dir, page by page
+func (s *S3Store) ListFiles(s3path string) ([]FileConfig, error) {
+	page, err := s.ListPage(s3path, ListOptions{})
+	if err != nil {
+		return nil, err
 	}
+	return page.Files, nil
+}
 
-	for i := 0; numLines < 0 || i < numLines; i++ {
-		if !fileScanner.Scan() {
+/*
+ListPage lists the objects by the continuation tokens of s3, the pages of s3 are fetched until the page is filled,
+and at most s3MaxListRequests pages of s3 are fetched for a page of the selective options, the marker is the continuation token
+*/
+func (s *S3Store) ListPage(dir string, opts ListOptions) (*FilePage, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.Bucket),
+		Prefix:    aws.String(dir + opts.Prefix),
+		Delimiter: aws.String("/"),
+	}
+	page := &FilePage{Files: []FileConfig{}}
+	token := opts.Marker
+	for i := 0; opts.Limit <= 0 || i < s3MaxListRequests; i++ {
+		if token != "" {
+			input.ContinuationToken = aws.String(token)
+		}
+		if opts.Limit > 0 {
+			// the page of s3 is consumed entirely, so that the next page starts from its token
+			input.MaxKeys = aws.Int64(int64(opts.Limit - len(page.Files)))
+		}
+		resp, err := s.S3Client.ListObjectsV2(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range s3Files(resp, dir) {
+			if opts.Match(f) {
+				page.Files = append(page.Files, f)
+			}
+		}
+		if !aws.BoolValue(resp.IsTruncated) {
+			token = ""
+			break
+		}
+		token = aws.StringValue(resp.NextContinuationToken)
+		if opts.Limit > 0 && len(page.Files) >= opts.Limit {
 			break
 		}
-		lines = append(lines, fileScanner.Text())
 	}
+	page.NextMarker = token
+	return page, nil
+}
 
-	if err := fileScanner.Err(); err != nil {
+func (s *S3Store) Open(s3path string) (io.ReadCloser, error) {
+	resp, err := s.S3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s3path),
+	})
+	if err != nil {
 		return nil, err
 	}
+	return resp.Body, nil
+}
 
-	return lines, nil
+// OpenRange reads the range by a ranged GET, the range beyond the end of the object is empty
+func (s *S3Store) OpenRange(s3path string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		byteRange += strconv.FormatInt(offset+length-1, 10)
+	}
+	resp, err := s.S3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s3path),
+		Range:  aws.String(byteRange),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidRange" {
+			return io.NopCloser(strings.NewReader("")), nil
+		}
+		return nil, err
+	}
+	return resp.Body, nil
 }
 
-func (s *S3Store) ListFiles(s3path string) ([]FileConfig, error) {
-	resp, err := s.S3Client.ListObjectsV2(&s3.ListObjectsV2Input{
-		Bucket:    aws.String(s.Bucket),
-		Prefix:    aws.String(s3path),
-		Delimiter: aws.String("/"),
+func (s *S3Store) Stat(s3path string) (*FileInfo, error) {
+	resp, err := s.S3Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s3path),
 	})
 	if err != nil {
 		return nil, err
 	}
+	return &FileInfo{
+		Name:    path.Base(s3path),
+		Size:    aws.Int64Value(resp.ContentLength),
+		ModTime: aws.TimeValue(resp.LastModified),
+		IsDir:   strings.HasSuffix(s3path, "/"),
+	}, nil
+}
+
+// openChunked reads the object by consecutive ranges, each range is twice as large as the last one
+func (s *S3Store) openChunked(s3path string) (io.ReadCloser, error) {
+	return &s3RangeReader{store: s, key: s3path, chunk: s3FirstChunk}, nil
+}
+
+func s3Files(resp *s3.ListObjectsV2Output, s3path string) []FileConfig {
 	var files []FileConfig
 	for _, obj := range resp.CommonPrefixes {
 		name := (*obj.Prefix)[:len(*obj.Prefix)-1] // remove trailing slash
@@ -123,25 +223,44 @@ func (s *S3Store) ListFiles(s3path string) ([]FileConfig, error) {
 		})
 	}
 	for _, obj := range resp.Contents {
-		var objType string
 		key := *obj.Key
-		if key[len(*obj.Key)-1:] == "/" {
+		objType := FileType(key)
+		if strings.HasSuffix(key, "/") {
 			objType = "directory"
-		} else if strings.HasSuffix(key, ".csv") {
-			objType = "csv"
 		}
 		name := strings.TrimPrefix(key, s3path)
-		if objType != "" && name != "" {
-			s3Object := FileConfig{
+		if name != "" {
+			files = append(files, FileConfig{
 				Name: name,
 				Type: objType,
-				Size: *obj.Size,
-			}
-			files = append(files, s3Object)
+				Size: aws.Int64Value(obj.Size),
+			})
 		}
 	}
 
-	return files, nil
+	return files
+}
+
+func (s *S3Store) WriteFile(s3path string, r io.Reader) error {
+	uploader := s3manager.NewUploaderWithClient(s.S3Client)
+	_, err := uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s3path),
+		Body:   r,
+	})
+	return err
+}
+
+// OpenParquetFile opens the object for the parquet reader, the object is read by ranges
+func (s *S3Store) OpenParquetFile(s3path string) (source.ParquetFile, error) {
+	resp, err := s.S3Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s3path),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s3ParquetFile{store: s, key: s3path, size: aws.Int64Value(resp.ContentLength)}, nil
 }
 
 func (s *S3Store) ListBuckets() ([]string, error) {
@@ -160,3 +279,112 @@ func (s *S3Store) ListBuckets() ([]string, error) {
 func (s *S3Store) Close() error {
 	return nil
 }
+
+func (f *s3ParquetFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *s3ParquetFile) Read(p []byte) (int, error) {
+	if f.offset >= f.size {
+		return 0, io.EOF
+	}
+	if f.offset < f.bufOff || f.offset >= f.bufOff+int64(len(f.buf)) {
+		length := int64(len(p))
+		if length < s3ReadAhead {
+			length = s3ReadAhead
+		}
+		end := f.offset + length
+		if end > f.size {
+			end = f.size
+		}
+		body, err := f.store.OpenRange(f.key, f.offset, end-f.offset)
+		if err != nil {
+			return 0, err
+		}
+		buf, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return 0, err
+		}
+		if len(buf) == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		f.buf, f.bufOff = buf, f.offset
+	}
+	n := copy(p, f.buf[f.offset-f.bufOff:])
+	f.offset += int64(n)
+	return n, nil
+}
+
+func (f *s3ParquetFile) Write([]byte) (int, error) {
+	return 0, errParquetReadOnly
+}
+
+func (f *s3ParquetFile) Close() error {
+	f.buf = nil
+	return nil
+}
+
+// Open opens the object again for another column, the name is the key of the object or empty for the same one
+func (f *s3ParquetFile) Open(name string) (source.ParquetFile, error) {
+	if name == "" || name == f.key {
+		return &s3ParquetFile{store: f.store, key: f.key, size: f.size}, nil
+	}
+	return f.store.OpenParquetFile(name)
+}
+
+func (f *s3ParquetFile) Create(string) (source.ParquetFile, error) {
+	return nil, errParquetReadOnly
+}
+
+func (r *s3RangeReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if r.eof {
+				return 0, io.EOF
+			}
+			body, err := r.store.OpenRange(r.key, r.offset, r.chunk)
+			if err != nil {
+				return 0, err
+			}
+			r.body, r.left = body, r.chunk
+			if r.chunk < s3MaxChunk {
+				r.chunk *= 2
+			}
+		}
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		r.left -= int64(n)
+		if err == io.EOF {
+			r.body.Close()
+			r.body = nil
+			// the range is cut short by the end of the object
+			r.eof = r.left > 0
+			if n == 0 {
+				continue
+			}
+			return n, nil
+		}
+		return n, err
+	}
+}
+
+func (r *s3RangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
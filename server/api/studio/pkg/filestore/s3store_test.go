@@ -2,6 +2,8 @@ package filestore
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"strings"
 	"testing"
@@ -109,3 +111,98 @@ func TestS3Store_ListFiles(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, got)
 }
+
+func TestS3Store_Ranges(t *testing.T) {
+	var big strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&big, "line %d\n", i)
+	}
+	client := &memS3Client{objects: map[string][]byte{
+		"data/big.csv": []byte(big.String()),
+		"data/a.csv":   []byte("a,b\n1,2\n"),
+	}}
+	store := &S3Store{Bucket: "test-bucket", S3Client: client}
+
+	// the preview only fetches the first range of the object
+	lines, err := store.ReadFile("data/big.csv", 0, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"line 0", "line 1", "line 2", "line 3"}, lines)
+	assert.Equal(t, 1, client.gets)
+	assert.LessOrEqual(t, client.bytes, s3FirstChunk)
+
+	// the lines across the ranges are all read
+	lines, err = store.ReadFile("data/big.csv", 19998, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"line 19998", "line 19999"}, lines)
+
+	r, err := store.OpenRange("data/a.csv", 4, 3)
+	assert.Nil(t, err)
+	content, _ := io.ReadAll(r)
+	assert.Equal(t, "1,2", string(content))
+	r, err = store.OpenRange("data/a.csv", 100, -1)
+	assert.Nil(t, err)
+	content, _ = io.ReadAll(r)
+	assert.Empty(t, content)
+
+	info, err := store.Stat("data/a.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, &FileInfo{Name: "a.csv", Size: 8}, info)
+}
+
+func TestS3Store_ListPage(t *testing.T) {
+	objects := map[string][]byte{"lake/sub/a.csv": nil, "lake/readme.txt": nil}
+	for i := 0; i < 2500; i++ {
+		objects[fmt.Sprintf("lake/part-%04d.parquet", i)] = nil
+		objects[fmt.Sprintf("lake/part-%04d.csv", i)] = nil
+	}
+	store := &S3Store{Bucket: "test-bucket", S3Client: &memS3Client{objects: objects}}
+
+	// nothing is truncated by the pages of s3
+	files, err := store.ListFiles("lake/")
+	assert.Nil(t, err)
+	assert.Len(t, files, 5002)
+
+	var names []string
+	opts := ListOptions{Limit: 700, Types: []string{"csv"}}
+	for {
+		page, err := store.ListPage("lake/", opts)
+		assert.Nil(t, err)
+		assert.LessOrEqual(t, len(page.Files), 700)
+		for _, f := range page.Files {
+			names = append(names, f.Name)
+		}
+		if page.NextMarker == "" {
+			break
+		}
+		opts.Marker = page.NextMarker
+	}
+	// the directory is kept for navigation
+	assert.Len(t, names, 2501)
+	assert.Contains(t, names, "sub")
+
+	page, err := store.ListPage("lake/", ListOptions{Prefix: "part-00", Pattern: "*-000?.parquet"})
+	assert.Nil(t, err)
+	assert.Len(t, page.Files, 10)
+	assert.Equal(t, FileConfig{Name: "part-0000.parquet", Type: "parquet"}, page.Files[0])
+}
+
+func TestPageFiles(t *testing.T) {
+	files := []FileConfig{{Name: "b.csv", Type: "csv"}, {Name: "a.csv", Type: "csv"}, {Name: "c.parquet", Type: "parquet"}, {Name: "bb.csv", Type: "csv"}, {Name: "d", Type: "directory"}}
+	page := pageFiles(files, ListOptions{Limit: 2})
+	assert.Equal(t, []FileConfig{{Name: "a.csv", Type: "csv"}, {Name: "b.csv", Type: "csv"}}, page.Files)
+	assert.Equal(t, "b.csv", page.NextMarker)
+	page = pageFiles(files, ListOptions{Limit: 2, Marker: page.NextMarker})
+	assert.Equal(t, []FileConfig{{Name: "bb.csv", Type: "csv"}, {Name: "c.parquet", Type: "parquet"}}, page.Files)
+	page = pageFiles(files, ListOptions{Prefix: "b"})
+	assert.Equal(t, []FileConfig{{Name: "b.csv", Type: "csv"}, {Name: "bb.csv", Type: "csv"}}, page.Files)
+	page = pageFiles(files, ListOptions{Types: []string{"parquet"}})
+	assert.Equal(t, []FileConfig{{Name: "c.parquet", Type: "parquet"}, {Name: "d", Type: "directory"}}, page.Files)
+	page = pageFiles(files, ListOptions{Pattern: "?.csv"})
+	assert.Equal(t, "", page.NextMarker)
+	assert.Len(t, page.Files, 3)
+
+	assert.NotNil(t, (&ListOptions{Pattern: "[a"}).Validate())
+	assert.NotNil(t, (&ListOptions{Types: []string{"exe"}}).Validate())
+	assert.Equal(t, "jsonl", FileType("events.NDJSON"))
+	assert.Equal(t, "gz", FileType("person.csv.gz"))
+}
@@ -1,15 +1,27 @@
 package filestore
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
 	"strings"
 
 	"github.com/pkg/sftp"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/zeromicro/go-zero/core/logx"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
+type sftpParquetFile struct {
+	*sftp.File
+	client *sftp.Client
+	path   string
+}
+
 type SftpStore struct {
 	Host       string
 	Port       int
@@ -18,13 +30,49 @@ type SftpStore struct {
 	SftpClient *sftp.Client
 }
 
+// SftpKey is the private key in PEM and its passphrase, the key is tried before the password
+type SftpKey struct {
+	PrivateKey string
+	Passphrase string
+}
+
+// SftpAuth is how the client is authenticated and how the host is verified, the host is trusted with a warning without the fingerprint and the known hosts
+type SftpAuth struct {
+	Password string
+	SftpKey
+	// HostKeyFingerprint is the SHA256 fingerprint of the host key, e.g. SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8
+	HostKeyFingerprint string
+	// KnownHosts are the lines of the known_hosts file, the host key must be one of the keys of the host
+	KnownHosts string
+}
+
 func NewSftpStore(host string, port int, username string, password string) (*SftpStore, error) {
+	return NewSftpStoreWithAuth(host, port, username, SftpAuth{Password: password})
+}
+
+func NewSftpStoreWithAuth(host string, port int, username string, auth SftpAuth) (*SftpStore, error) {
+	var methods []ssh.AuthMethod
+	if auth.PrivateKey != "" {
+		signer, err := parsePrivateKey(auth.PrivateKey, auth.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	if auth.Password != "" {
+		methods = append(methods, ssh.Password(auth.Password))
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("either the password or the private key is required")
+	}
+	hostKeyCallback, err := sftpHostKeyCallback(auth)
+	if err != nil {
+		return nil, err
+	}
 	config := &ssh.ClientConfig{
-		User: username,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		User:            username,
+		Auth:            methods,
+		HostKeyCallback: hostKeyCallback,
 	}
 
 	addr := fmt.Sprintf("%s:%d", host, port)
@@ -35,6 +83,7 @@ func NewSftpStore(host string, port int, username string, password string) (*Sft
 
 	client, err := sftp.NewClient(conn)
 	if err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("failed to create SFTP client: %s", err)
 	}
 
@@ -42,52 +91,96 @@ func NewSftpStore(host string, port int, username string, password string) (*Sft
 		Host:       host,
 		Port:       port,
 		Username:   username,
-		Password:   password,
+		Password:   auth.Password,
 		SftpClient: client,
 	}, nil
 }
 
-func (s *SftpStore) ReadFile(path string, startLine ...int) ([]string, error) {
-	var numLines int
-	var start int
-	if len(startLine) == 0 {
-		start = 0
-		numLines = -1
-	} else if len(startLine) == 1 {
-		start = startLine[0]
-		numLines = -1
+func parsePrivateKey(key, passphrase string) (ssh.Signer, error) {
+	var (
+		signer ssh.Signer
+		err    error
+	)
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
 	} else {
-		start = startLine[0]
-		numLines = startLine[1]
+		signer, err = ssh.ParsePrivateKey([]byte(key))
 	}
+	if err != nil {
+		return nil, fmt.Errorf("parse the private key error: %s", err)
+	}
+	return signer, nil
+}
 
-	f, err := s.SftpClient.Open(path)
+/*
+sftpHostKeyCallback verifies the host key by the fingerprint and the known hosts, the hostname and the port are matched by the known hosts,
+the fingerprint of the host key is told when it is rejected, and every connection is warned if the host is trusted without them
+*/
+func sftpHostKeyCallback(auth SftpAuth) (ssh.HostKeyCallback, error) {
+	fingerprint := strings.TrimSpace(auth.HostKeyFingerprint)
+	if fingerprint != "" && !strings.HasPrefix(fingerprint, "SHA256:") {
+		fingerprint = "SHA256:" + fingerprint
+	}
+	known, err := parseKnownHosts(auth.KnownHosts)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-
-	fileScanner := bufio.NewScanner(f)
-
-	var lines []string
-	for i := 0; i < start; i++ {
-		if !fileScanner.Scan() {
-			return nil, errors.New("start line is beyond end of file")
-		}
+	if fingerprint == "" && known == nil {
+		return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+			logx.Errorf("[sftp] the host key %s of %s is trusted without being verified, set the fingerprint or the known hosts of the datasource", ssh.FingerprintSHA256(key), hostname)
+			return nil
+		}, nil
 	}
-
-	for i := 0; numLines < 0 || i < numLines; i++ {
-		if !fileScanner.Scan() {
-			break
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if known != nil {
+			err := known(hostname, remote, key)
+			if err == nil {
+				return nil
+			}
+			var revokedErr *knownhosts.RevokedError
+			if errors.As(err, &revokedErr) {
+				return fmt.Errorf("the host key %s of %s is revoked", ssh.FingerprintSHA256(key), hostname)
+			}
 		}
-		lines = append(lines, fileScanner.Text())
-	}
+		if fingerprint != "" && ssh.FingerprintSHA256(key) == fingerprint {
+			return nil
+		}
+		return fmt.Errorf("the host key %s of %s is not trusted", ssh.FingerprintSHA256(key), hostname)
+	}, nil
+}
 
-	if err := fileScanner.Err(); err != nil {
+// parseKnownHosts returns the callback of the lines of the known_hosts file, which are written to a temp file for knownhosts only reads the files
+func parseKnownHosts(lines string) (ssh.HostKeyCallback, error) {
+	if strings.TrimSpace(lines) == "" {
+		return nil, nil
+	}
+	f, err := os.CreateTemp("", "studio-known-hosts-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(lines)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		return nil, err
 	}
+	callback, err := knownhosts.New(f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("parse the known hosts error: %s", err)
+	}
+	return callback, nil
+}
 
-	return lines, nil
+func (s *SftpStore) ReadFile(path string, startLine ...int) ([]string, error) {
+	start, numLines := lineRange(startLine)
+	f, err := s.SftpClient.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readLines(f, start, numLines)
 }
 
 func (s *SftpStore) ListFiles(dir string) ([]FileConfig, error) {
@@ -105,26 +198,112 @@ func (s *SftpStore) ListFiles(dir string) ([]FileConfig, error) {
 		return nil, err
 	}
 	for _, file := range _files {
-		isDir := file.IsDir()
 		name := file.Name()
-		var fileType string
-		if isDir && !strings.HasPrefix(name, ".") {
+		fileType := FileType(name)
+		if file.IsDir() {
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
 			fileType = "directory"
-		} else if strings.HasSuffix(name, ".csv") {
-			fileType = "csv"
-		}
-		if fileType != "" {
-			files = append(files, FileConfig{
-				Name: name,
-				Size: file.Size(),
-				Type: fileType,
-			})
 		}
-
+		files = append(files, FileConfig{
+			Name: name,
+			Size: file.Size(),
+			Type: fileType,
+		})
 	}
 	return files, nil
 }
 
+// ListPage pages the files of the dir by their names, the dir is read at once as sftp can not read it from a name
+func (s *SftpStore) ListPage(dir string, opts ListOptions) (*FilePage, error) {
+	files, err := s.ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return pageFiles(files, opts), nil
+}
+
+func (s *SftpStore) Open(path string) (io.ReadCloser, error) {
+	return s.SftpClient.Open(path)
+}
+
+// OpenRange seeks to the offset in the remote file, only the range is transferred
+func (s *SftpStore) OpenRange(path string, offset, length int64) (io.ReadCloser, error) {
+	f, err := s.SftpClient.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return &readCloser{Reader: io.LimitReader(f, length), Closer: f}, nil
+}
+
+func (s *SftpStore) Stat(path string) (*FileInfo, error) {
+	info, err := s.SftpClient.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileInfo{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+	}, nil
+}
+
+func (s *SftpStore) WriteFile(filePath string, r io.Reader) error {
+	if err := s.SftpClient.MkdirAll(path.Dir(filePath)); err != nil {
+		return err
+	}
+	f, err := s.SftpClient.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// OpenParquetFile opens the file for the parquet reader, the sftp file can be read randomly
+func (s *SftpStore) OpenParquetFile(path string) (source.ParquetFile, error) {
+	f, err := s.SftpClient.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &sftpParquetFile{File: f, client: s.SftpClient, path: path}, nil
+}
+
 func (s *SftpStore) Close() error {
 	return s.SftpClient.Close()
 }
+
+// Ping checks that the connection is alive by a round trip to the server
+func (s *SftpStore) Ping() error {
+	_, err := s.SftpClient.Getwd()
+	return err
+}
+
+func (f *sftpParquetFile) Open(name string) (source.ParquetFile, error) {
+	if name == "" {
+		name = f.path
+	}
+	file, err := f.client.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sftpParquetFile{File: file, client: f.client, path: name}, nil
+}
+
+func (f *sftpParquetFile) Create(string) (source.ParquetFile, error) {
+	return nil, errParquetReadOnly
+}
+
+func (f *sftpParquetFile) Write([]byte) (int, error) {
+	return 0, errParquetReadOnly
+}
@@ -1,10 +1,22 @@
 package filestore
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 
 	"github.com/pkg/sftp"
 )
@@ -36,3 +48,129 @@ func TestSftpStore_ReadFile(t *testing.T) {
 		t.Errorf("unexpected lines read from file: got %v, want %v", lines, expectedLines)
 	}
 }
+
+func TestSftpAuth(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	assert.Nil(t, err)
+	hostKey := signer.PublicKey()
+	fingerprint := ssh.FingerprintSHA256(hostKey)
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	otherSigner, _ := ssh.NewSignerFromKey(other)
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+
+	callback, err := sftpHostKeyCallback(SftpAuth{})
+	assert.Nil(t, err)
+	assert.Nil(t, callback("example.com:22", addr, hostKey))
+
+	callback, err = sftpHostKeyCallback(SftpAuth{HostKeyFingerprint: strings.TrimPrefix(fingerprint, "SHA256:")})
+	assert.Nil(t, err)
+	assert.Nil(t, callback("example.com:22", addr, hostKey))
+	err = callback("example.com:22", addr, otherSigner.PublicKey())
+	assert.ErrorContains(t, err, ssh.FingerprintSHA256(otherSigner.PublicKey()))
+
+	line := knownhosts.Line([]string{"example.com"}, hostKey)
+	callback, err = sftpHostKeyCallback(SftpAuth{KnownHosts: "# the hosts\n" + line + "\n"})
+	assert.Nil(t, err)
+	assert.Nil(t, callback("example.com:22", addr, hostKey))
+	assert.NotNil(t, callback("example.com:22", addr, otherSigner.PublicKey()))
+	// the key of a host is not trusted for the other hosts
+	assert.NotNil(t, callback("other.com:22", addr, hostKey))
+	assert.NotNil(t, callback("example.com:2222", addr, hostKey))
+	callback, err = sftpHostKeyCallback(SftpAuth{KnownHosts: knownhosts.Line([]string{knownhosts.HashHostname(knownhosts.Normalize("example.com:2222"))}, hostKey)})
+	assert.Nil(t, err)
+	assert.Nil(t, callback("example.com:2222", addr, hostKey))
+	assert.NotNil(t, callback("example.com:22", addr, hostKey))
+	callback, err = sftpHostKeyCallback(SftpAuth{HostKeyFingerprint: fingerprint, KnownHosts: line})
+	assert.Nil(t, err)
+	assert.Nil(t, callback("other.com:22", addr, hostKey))
+	callback, err = sftpHostKeyCallback(SftpAuth{HostKeyFingerprint: fingerprint, KnownHosts: "@revoked " + line})
+	assert.Nil(t, err)
+	assert.ErrorContains(t, callback("example.com:22", addr, hostKey), "revoked")
+	_, err = sftpHostKeyCallback(SftpAuth{KnownHosts: "not a known host"})
+	assert.NotNil(t, err)
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("s3cret"))
+	assert.Nil(t, err)
+	key := string(pem.EncodeToMemory(block))
+	parsed, err := parsePrivateKey(key, "s3cret")
+	assert.Nil(t, err)
+	assert.Equal(t, fingerprint, ssh.FingerprintSHA256(parsed.PublicKey()))
+	_, err = parsePrivateKey(key, "wrong")
+	assert.NotNil(t, err)
+	_, err = NewSftpStoreWithAuth("127.0.0.1", 22, "root", SftpAuth{})
+	assert.NotNil(t, err)
+}
+
+// serveSftp serves the sftp subsystem on a random port, the clients are authenticated by the public key
+func serveSftp(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey) int {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	config.AddHostKey(hostKey)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					channel, requests, _ := ch.Accept()
+					go func() {
+						for req := range requests {
+							req.Reply(req.Type == "subsystem", nil)
+							if req.Type == "subsystem" {
+								server, _ := sftp.NewServer(channel)
+								server.Serve()
+								server.Close()
+							}
+						}
+					}()
+				}
+			}()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestSftpStore_KeyAuth(t *testing.T) {
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, _ := ssh.NewSignerFromKey(hostPriv)
+	_, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	clientKey, _ := ssh.NewSignerFromKey(clientPriv)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(clientPriv, "", []byte("s3cret"))
+	assert.Nil(t, err)
+	port := serveSftp(t, hostKey, clientKey.PublicKey())
+
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "person.csv"), []byte("id,name\n1,Tom\n"), 0o644))
+	key := SftpKey{PrivateKey: string(pem.EncodeToMemory(block)), Passphrase: "s3cret"}
+
+	store, err := NewSftpStoreWithAuth("127.0.0.1", port, "root", SftpAuth{SftpKey: key, HostKeyFingerprint: ssh.FingerprintSHA256(hostKey.PublicKey())})
+	assert.Nil(t, err)
+	lines, err := store.ReadFile(filepath.Join(dir, "person.csv"), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1,Tom"}, lines)
+	assert.Nil(t, store.Close())
+
+	// the host key is not the one known
+	_, err = NewSftpStoreWithAuth("127.0.0.1", port, "root", SftpAuth{SftpKey: key, HostKeyFingerprint: ssh.FingerprintSHA256(clientKey.PublicKey())})
+	assert.ErrorContains(t, err, ssh.FingerprintSHA256(hostKey.PublicKey()))
+	_, err = NewSftpStoreWithAuth("127.0.0.1", port, "root", SftpAuth{Password: "root"})
+	assert.NotNil(t, err)
+}
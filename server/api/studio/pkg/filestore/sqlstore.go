@@ -0,0 +1,507 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+)
+
+const (
+	SQLDriverMySQL    = "mysql"
+	SQLDriverPostgres = "postgres"
+	SQLDriverSQLite   = "sqlite"
+)
+
+// sqlConnectTimeout limits the time to connect the database
+const sqlConnectTimeout = 10 * time.Second
+
+type (
+	/*
+		SQLStore reads the tables and the views of the relational database like the csv files,
+		the header is the names of the columns, and the values are formatted as the csv values
+	*/
+	SQLStore struct {
+		Driver string
+		DB     *sql.DB
+	}
+
+	SQLColumn struct {
+		Name string
+		Type string
+	}
+
+	// SQLRows is a page of the rows selected
+	SQLRows struct {
+		Columns []SQLColumn
+		Rows    [][]string
+	}
+
+	// SQLRowReader streams the rows selected by a cursor, the rows are read page by page
+	SQLRowReader struct {
+		Columns []SQLColumn
+		rows    *sql.Rows
+		dates   []bool
+	}
+)
+
+/*
+NewSQLStore connects the database, the database of sqlite is the path of the file, which is opened read only,
+the file should be under the allowed roots as the local datasources
+*/
+func NewSQLStore(driver, host string, port int, database, username, password, params string, allowedRoots []string) (*SQLStore, error) {
+	var driverName, dsn string
+	switch driver {
+	case SQLDriverMySQL:
+		if port == 0 {
+			port = 3306
+		}
+		cfg := mysql.NewConfig()
+		cfg.User, cfg.Passwd = username, password
+		cfg.Net, cfg.Addr = "tcp", net.JoinHostPort(host, strconv.Itoa(port))
+		cfg.DBName = database
+		cfg.ParseTime, cfg.Loc = true, time.UTC
+		cfg.Timeout = sqlConnectTimeout
+		var err error
+		if dsn, err = mysqlDSN(cfg, params); err != nil {
+			return nil, err
+		}
+		driverName = "mysql"
+	case SQLDriverPostgres:
+		if port == 0 {
+			port = 5432
+		}
+		if err := checkPostgresParams(params); err != nil {
+			return nil, err
+		}
+		u := &url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(username, password),
+			Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+			Path:     "/" + database,
+			RawQuery: params,
+		}
+		driverName, dsn = "postgres", u.String()
+	case SQLDriverSQLite:
+		path, err := sqliteFilePath(database, allowedRoots)
+		if err != nil {
+			return nil, err
+		}
+		driverName, dsn = "sqlite3", "file:"+sqliteURIEscaper.Replace(path)+"?mode=ro"
+	default:
+		return nil, fmt.Errorf("unsupported sql driver %s", driver)
+	}
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sqlConnectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect the database failed: %w", err)
+	}
+	return &SQLStore{Driver: driver, DB: db}, nil
+}
+
+// postgresFileParams are the params of lib/pq which read the files of the server
+var postgresFileParams = []string{"sslkey", "sslcert", "sslrootcert", "passfile"}
+
+/*
+mysqlDSN adds the params to the dsn of the config, the params which let the server read the local files
+or receive the password in clear text are refused, and they are turned off in the dsn returned
+*/
+func mysqlDSN(cfg *mysql.Config, params string) (string, error) {
+	dsn := cfg.FormatDSN()
+	if params == "" {
+		return dsn, nil
+	}
+	sep := "?"
+	// the password may contain ?, while the database name after the last slash is escaped
+	if strings.Contains(dsn[strings.LastIndex(dsn, "/"):], "?") {
+		sep = "&"
+	}
+	merged, err := mysql.ParseDSN(dsn + sep + params)
+	if err != nil {
+		return "", fmt.Errorf("invalid params: %w", err)
+	}
+	if merged.AllowAllFiles || merged.AllowCleartextPasswords {
+		return "", errors.New("the params allowAllFiles and allowCleartextPasswords are not allowed")
+	}
+	merged.AllowAllFiles, merged.AllowCleartextPasswords = false, false
+	return merged.FormatDSN(), nil
+}
+
+// checkPostgresParams refuses the params which read the files of the server
+func checkPostgresParams(params string) error {
+	values, err := url.ParseQuery(params)
+	if err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	for _, name := range postgresFileParams {
+		if values.Has(name) {
+			return fmt.Errorf("the param %s is not allowed", name)
+		}
+	}
+	return nil
+}
+
+var sqliteURIEscaper = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+
+// sqliteFilePath resolves the sqlite file by its symlinks, the file out of the allowed roots and the db of the studio are refused
+func sqliteFilePath(database string, allowedRoots []string) (string, error) {
+	abs, err := filepath.Abs(database)
+	if err != nil {
+		return "", err
+	}
+	// the file is not created if it does not exist
+	path, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	if c := config.GetConfig(); c != nil && c.DB.SqliteDbFilePath != "" {
+		if studioDB, err := filepath.Abs(c.DB.SqliteDbFilePath); err == nil && realPath(studioDB) == path {
+			return "", fmt.Errorf("the file %s is not allowed to be read", database)
+		}
+	}
+	if !underRoots(path, allowedRoots) {
+		return "", fmt.Errorf("the file %s is not allowed to be read", database)
+	}
+	return path, nil
+}
+
+// ListFiles lists the tables and the views, the tables out of the default schema of postgres are prefixed by their schemas
+func (s *SQLStore) ListFiles(string) ([]FileConfig, error) {
+	var query string
+	switch s.Driver {
+	case SQLDriverMySQL:
+		query = "SELECT table_name, table_type FROM information_schema.tables WHERE table_schema = DATABASE() ORDER BY table_name"
+	case SQLDriverPostgres:
+		query = `SELECT CASE WHEN table_schema = 'public' THEN table_name ELSE table_schema || '.' || table_name END, table_type
+			FROM information_schema.tables WHERE table_schema NOT IN ('pg_catalog', 'information_schema') ORDER BY 1`
+	default:
+		query = "SELECT name, type FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	}
+	rows, err := s.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var files []FileConfig
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, err
+		}
+		fileType := "table"
+		if strings.Contains(strings.ToLower(typ), "view") {
+			fileType = "view"
+		}
+		files = append(files, FileConfig{Name: name, Type: fileType})
+	}
+	return files, rows.Err()
+}
+
+// ReadFile reads the table as the csv lines, the first line is the header
+func (s *SQLStore) ReadFile(table string, startLine ...int) ([]string, error) {
+	start, numLines := 0, -1
+	if len(startLine) > 0 {
+		start = startLine[0]
+	}
+	if len(startLine) > 1 {
+		numLines = startLine[1]
+	}
+	offset, limit := start-1, numLines
+	if start == 0 {
+		// the header is the first line
+		offset = 0
+		if limit > 0 {
+			limit--
+		}
+	}
+	var page *SQLRows
+	var err error
+	if numLines == 0 {
+		page = &SQLRows{}
+	} else if page, err = s.ReadRows(table, "", offset, limit); err != nil {
+		return nil, err
+	}
+	var lines []string
+	if start == 0 {
+		header := make([]string, 0, len(page.Columns))
+		for _, c := range page.Columns {
+			header = append(header, c.Name)
+		}
+		lines = append(lines, csvLine(header))
+	}
+	for _, row := range page.Rows {
+		lines = append(lines, csvLine(row))
+	}
+	return lines, nil
+}
+
+// ListPage pages the tables and the views by their names
+func (s *SQLStore) ListPage(_ string, opts ListOptions) (*FilePage, error) {
+	files, err := s.ListFiles("")
+	if err != nil {
+		return nil, err
+	}
+	return pageFiles(files, opts), nil
+}
+
+// Open streams the table as csv, the first line is the header
+func (s *SQLStore) Open(table string) (io.ReadCloser, error) {
+	rows, err := s.OpenRows(table, "")
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		defer rows.Close()
+		w := csv.NewWriter(pw)
+		header := make([]string, 0, len(rows.Columns))
+		for _, c := range rows.Columns {
+			header = append(header, c.Name)
+		}
+		err := w.Write(header)
+		for err == nil {
+			var page [][]string
+			if page, err = rows.Read(500); err == nil {
+				err = w.WriteAll(page)
+			}
+		}
+		if err == io.EOF {
+			w.Flush()
+			err = w.Error()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// OpenRange only reads the table from the beginning, the tables have no byte offsets
+func (s *SQLStore) OpenRange(table string, offset, length int64) (io.ReadCloser, error) {
+	if offset != 0 {
+		return nil, errors.New("the table of the sql datasource can not be read by ranges")
+	}
+	r, err := s.Open(table)
+	if err != nil || length < 0 {
+		return r, err
+	}
+	return &readCloser{Reader: io.LimitReader(r, length), Closer: r}, nil
+}
+
+// Stat finds the table or the view, its size is unknown
+func (s *SQLStore) Stat(table string) (*FileInfo, error) {
+	files, err := s.ListFiles("")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.Name == table {
+			return &FileInfo{Name: f.Name}, nil
+		}
+	}
+	return nil, fmt.Errorf("table %s not existed", table)
+}
+
+func (s *SQLStore) WriteFile(string, io.Reader) error {
+	return errors.New("the sql datasource is read only")
+}
+
+func (s *SQLStore) Close() error {
+	return s.DB.Close()
+}
+
+func (s *SQLStore) Ping() error {
+	return s.DB.Ping()
+}
+
+/*
+ReadRows reads a page of the rows in the table or selected by the query, all the rows after the offset are read if limit is negative,
+the rows of the table are in the order of the database, the query should order the rows itself
+*/
+func (s *SQLStore) ReadRows(table, query string, offset, limit int) (*SQLRows, error) {
+	stmt, err := s.SelectStatement(table, query)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case limit >= 0:
+		stmt += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	case offset > 0 && s.Driver == SQLDriverMySQL:
+		// the limit is required by the offset of mysql
+		stmt += fmt.Sprintf(" LIMIT %d OFFSET %d", uint64(1<<63-1), offset)
+	case offset > 0 && s.Driver == SQLDriverSQLite:
+		stmt += fmt.Sprintf(" LIMIT -1 OFFSET %d", offset)
+	case offset > 0:
+		stmt += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	r, err := s.query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	page := &SQLRows{Columns: r.Columns, Rows: [][]string{}}
+	for {
+		rows, err := r.Read(512)
+		if err == io.EOF {
+			return page, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		page.Rows = append(page.Rows, rows...)
+	}
+}
+
+// OpenRows selects the rows in the table or by the query, the rows are streamed without loading them all
+func (s *SQLStore) OpenRows(table, query string) (*SQLRowReader, error) {
+	stmt, err := s.SelectStatement(table, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.query(stmt)
+}
+
+// CountRows counts the rows in the table or selected by the query
+func (s *SQLStore) CountRows(table, query string) (int64, error) {
+	stmt, err := s.SelectStatement(table, query)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = s.DB.QueryRow("SELECT COUNT(*) FROM (" + stmt + ") AS studio_count").Scan(&count)
+	return count, err
+}
+
+/*
+SelectStatement returns the statement selecting all the columns of the table, or the rows of the query,
+only one SELECT statement is allowed in the query, and it is run as a derived table so that nothing is modified by it
+*/
+func (s *SQLStore) SelectStatement(table, query string) (string, error) {
+	if (table == "") == (query == "") {
+		return "", errors.New("either the table or the query should be given")
+	}
+	if table != "" {
+		parts := strings.Split(table, ".")
+		for i, part := range parts {
+			if part == "" {
+				return "", fmt.Errorf("invalid table name %s", table)
+			}
+			parts[i] = s.quoteIdentifier(part)
+		}
+		return "SELECT * FROM " + strings.Join(parts, "."), nil
+	}
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	lower := strings.ToLower(query)
+	if !strings.HasPrefix(lower, "select") && !strings.HasPrefix(lower, "with") {
+		return "", errors.New("only the SELECT statement is allowed")
+	}
+	if strings.Contains(query, ";") {
+		return "", errors.New("only one statement is allowed")
+	}
+	return "SELECT * FROM (" + query + ") AS studio_rows", nil
+}
+
+func (s *SQLStore) quoteIdentifier(name string) string {
+	if s.Driver == SQLDriverMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (s *SQLStore) query(stmt string) (*SQLRowReader, error) {
+	rows, err := s.DB.Query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	r := &SQLRowReader{rows: rows, dates: make([]bool, len(types))}
+	for i, t := range types {
+		typ := strings.ToLower(t.DatabaseTypeName())
+		r.Columns = append(r.Columns, SQLColumn{Name: t.Name(), Type: typ})
+		r.dates[i] = typ == "date"
+	}
+	return r, nil
+}
+
+// Read reads at most n rows, the null value is empty, io.EOF is returned after the last row
+func (r *SQLRowReader) Read(n int) ([][]string, error) {
+	values := make([]any, len(r.Columns))
+	dest := make([]any, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	var rows [][]string
+	for len(rows) < n && r.rows.Next() {
+		if err := r.rows.Scan(dest...); err != nil {
+			return rows, err
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = sqlValueString(v, r.dates[i])
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		if err := r.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return rows, nil
+}
+
+func (r *SQLRowReader) Close() error {
+	return r.rows.Close()
+}
+
+func sqlValueString(value any, date bool) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		if date {
+			return v.Format("2006-01-02")
+		}
+		return v.UTC().Format(datetimeLayout)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
+
+func csvLine(row []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(row)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,156 @@
+package filestore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSQLiteStore(t *testing.T) *SQLStore {
+	path := filepath.Join(t.TempDir(), "person.db")
+	db, err := sql.Open("sqlite3", path)
+	assert.Nil(t, err)
+	for _, stmt := range []string{
+		"CREATE TABLE person (id INTEGER PRIMARY KEY, name TEXT, score REAL)",
+		"INSERT INTO person VALUES (1, 'Tom, Jr.', 1.5), (2, 'Jerry', NULL), (3, 'Spike', 3)",
+		"CREATE VIEW good_person AS SELECT id, name FROM person WHERE score > 1",
+	} {
+		_, err := db.Exec(stmt)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, db.Close())
+
+	store, err := NewSQLStore(SQLDriverSQLite, "", 0, path, "", "", "", []string{filepath.Dir(path)})
+	assert.Nil(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLStore_SQLiteRoots(t *testing.T) {
+	allowed := t.TempDir()
+	secret := filepath.Join(t.TempDir(), "secret.db")
+	db, err := sql.Open("sqlite3", secret)
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE secret (id INTEGER PRIMARY KEY)")
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+	assert.Nil(t, os.Symlink(secret, filepath.Join(allowed, "link.db")))
+
+	config, _ := json.Marshal(map[string]string{"driver": SQLDriverSQLite, "database": secret})
+	_, err = NewFileStore("sql", string(config), "", "")
+	assert.NotNil(t, err)
+	_, err = NewSQLStore(SQLDriverSQLite, "", 0, secret, "", "", "", []string{allowed})
+	assert.NotNil(t, err)
+	_, err = NewSQLStore(SQLDriverSQLite, "", 0, filepath.Join(allowed, "link.db"), "", "", "", []string{allowed})
+	assert.NotNil(t, err)
+	store, err := NewSQLStore(SQLDriverSQLite, "", 0, secret, "", "", "", []string{filepath.Dir(secret)})
+	assert.Nil(t, err)
+	store.Close()
+}
+
+func TestSQLStore_ListAndRead(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	files, err := store.ListFiles("")
+	assert.Nil(t, err)
+	assert.Equal(t, []FileConfig{{Type: "view", Name: "good_person"}, {Type: "table", Name: "person"}}, files)
+
+	lines, err := store.ReadFile("person", 0, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id,name,score", `1,"Tom, Jr.",1.5`, "2,Jerry,"}, lines)
+
+	lines, err = store.ReadFile("good_person")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id,name", `1,"Tom, Jr."`, "3,Spike"}, lines)
+
+	page, err := store.ReadRows("", "SELECT name FROM person ORDER BY id;", 1, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"Jerry"}, {"Spike"}}, page.Rows)
+	assert.Equal(t, "name", page.Columns[0].Name)
+
+	count, err := store.CountRows("person", "")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+
+	r, err := store.OpenRows("person", "")
+	assert.Nil(t, err)
+	defer r.Close()
+	var rows [][]string
+	for {
+		batch, err := r.Read(2)
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		rows = append(rows, batch...)
+	}
+	assert.Len(t, rows, 3)
+	assert.Equal(t, []string{"3", "Spike", "3"}, rows[2])
+}
+
+func TestSQLStore_SelectStatement(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	for _, query := range []string{
+		"DELETE FROM person",
+		"SELECT 1; DROP TABLE person",
+		"",
+	} {
+		_, err := store.ReadRows("", query, 0, 10)
+		assert.NotNil(t, err, query)
+	}
+	_, err := store.ReadRows("person", "SELECT 1", 0, 10)
+	assert.NotNil(t, err)
+
+	stmt, err := store.SelectStatement(`my"schema.person`, "")
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT * FROM "my""schema"."person"`, stmt)
+
+	// the database is opened read only
+	_, err = store.DB.Exec("DELETE FROM person")
+	assert.NotNil(t, err)
+}
+
+func TestSQLStore_Open(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	r, err := store.Open("person")
+	assert.Nil(t, err)
+	content, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Close())
+	assert.Equal(t, "id,name,score\n1,\"Tom, Jr.\",1.5\n2,Jerry,\n3,Spike,3\n", string(content))
+
+	page, err := store.ListPage("", ListOptions{Prefix: "p", Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, []FileConfig{{Type: "table", Name: "person"}}, page.Files)
+	_, err = store.Stat("missing")
+	assert.NotNil(t, err)
+}
+
+func TestSQLStore_Params(t *testing.T) {
+	for _, params := range []string{"allowAllFiles=true", "charset=utf8mb4&allowAllFiles=1", "allowCleartextPasswords=true", "allowAllFiles=%zz"} {
+		_, err := NewSQLStore(SQLDriverMySQL, "127.0.0.1", 1, "db", "root", "", params, nil)
+		assert.NotNil(t, err, params)
+	}
+	for _, params := range []string{"sslkey=/etc/shadow", "sslmode=require&sslrootcert=/tmp/ca.crt", "sslcert=a", "passfile=a"} {
+		_, err := NewSQLStore(SQLDriverPostgres, "127.0.0.1", 1, "db", "root", "", params, nil)
+		assert.ErrorContains(t, err, "is not allowed", params)
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.User, cfg.Net, cfg.Addr, cfg.DBName = "root", "tcp", "127.0.0.1:3306", "db"
+	dsn, err := mysqlDSN(cfg, "autocommit=1&allowAllFiles=false")
+	assert.Nil(t, err)
+	parsed, err := mysql.ParseDSN(dsn)
+	assert.Nil(t, err)
+	assert.False(t, parsed.AllowAllFiles)
+	assert.False(t, parsed.AllowCleartextPasswords)
+	assert.Equal(t, "1", parsed.Params["autocommit"])
+}
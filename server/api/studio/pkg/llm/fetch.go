@@ -8,6 +8,7 @@ import (
 	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/llm/transformer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -20,9 +21,25 @@ func Fetch(auth *auth.AuthData, req map[string]any, callback func(str string)) (
 	if err != nil {
 		return nil, err
 	}
+	if err := DecryptLLMConfig(&config); err != nil {
+		return nil, err
+	}
 	return FetchWithLLMConfig(&config, req, callback)
 }
 
+// DecryptLLMConfig decrypts the key of the config found in place, the key saved before it is encrypted is plain
+func DecryptLLMConfig(config *db.LLMConfig) error {
+	if !secrets.IsEnvelope(config.Key) {
+		return nil
+	}
+	key, err := secrets.Decrypt(config.Key)
+	if err != nil {
+		return fmt.Errorf("decrypt the key of the llm api error: %v", err)
+	}
+	config.Key = key
+	return nil
+}
+
 func FetchWithLLMConfig(config *db.LLMConfig, req map[string]any, callback func(str string)) (map[string]any, error) {
 	defer func() {
 		if err := recover(); err != nil {
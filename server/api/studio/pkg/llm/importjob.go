@@ -21,6 +21,8 @@ import (
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/base"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/client"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/pdf"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/webhook"
 	"gorm.io/datatypes"
 )
 
@@ -88,7 +90,9 @@ func RunFileJob(job *db.LLMJob) {
 			llmJob.WriteLogFile(fmt.Sprintf("update process error: %v", err), "error")
 			return
 		}
+		notifyJob(job, llmJob.Process)
 	}()
+	notifyJob(job, llmJob.Process)
 	err := llmJob.AddLogFile()
 	if err != nil {
 		llmJob.SetJobFailed(err)
@@ -108,6 +112,11 @@ func RunFileJob(job *db.LLMJob) {
 		llmJob.SetJobFailed(err)
 		return
 	}
+	if err = DecryptLLMConfig(&llmConfig); err != nil {
+		llmJob.WriteLogFile(err.Error(), "error")
+		llmJob.SetJobFailed(err)
+		return
+	}
 	llmJob.LLMConfig = &llmConfig
 	llmJob.Process.Ratio = 0.03
 
@@ -118,6 +127,13 @@ func RunFileJob(job *db.LLMJob) {
 		llmJob.SetJobFailed(err)
 		return
 	}
+	// the password is kept encrypted in the session cache
+	connectInfo.Password, err = secrets.Decrypt(connectInfo.Password)
+	if err != nil {
+		llmJob.WriteLogFile(fmt.Sprintf("decrypt connect info error: %v", err), "error")
+		llmJob.SetJobFailed(err)
+		return
+	}
 	llmJob.AuthData = &connectInfo
 	clientInfo, err := client.NewClient(connectInfo.Address, connectInfo.Port, connectInfo.Username, connectInfo.Password, nebula_go.GetDefaultConf())
 	if err != nil {
@@ -143,6 +159,9 @@ func RunFileJob(job *db.LLMJob) {
 	llmJob.WriteLogFile(fmt.Sprintf("start run file job, file path: %s", job.File), "info")
 
 	filePath := path.Join(config.GetConfig().File.UploadDir, llmJob.LLMJob.File)
+	if llmJob.LLMJob.FilePath != "" {
+		filePath = llmJob.LLMJob.FilePath
+	}
 	text, err := llmJob.ReadFile(filePath)
 	if err != nil {
 		llmJob.WriteLogFile(fmt.Sprintf("read file error: %v", err), "error")
@@ -198,6 +217,21 @@ func (i *ImportJob) SyncProcess(job *db.LLMJob) {
 	}
 }
 
+// notifyJob sends the status and the process of the job to the webhooks
+func notifyJob(job *db.LLMJob, process *base.Process) {
+	webhook.Notify(webhook.KindLLMJob, &webhook.Event{
+		Address:  job.Host,
+		User:     job.UserName,
+		TaskID:   job.JobID,
+		TaskType: job.JobType,
+		Name:     job.File,
+		Space:    job.Space,
+		Status:   string(job.Status),
+		Message:  process.FailedReason,
+		Stats:    process,
+	})
+}
+
 func (i *ImportJob) SetJobFailed(failedErr any) {
 	i.LLMJob.Status = base.LLMStatusFailed
 	i.Process.FailedReason = fmt.Sprintf("%v", failedErr)
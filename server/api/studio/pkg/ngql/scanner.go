@@ -0,0 +1,159 @@
+package ngql
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+const (
+	stateNormal = iota
+	stateSingleQuote
+	stateDoubleQuote
+	stateBacktick
+	stateLineComment
+	stateBlockComment
+)
+
+/*
+Scanner splits an nGQL script into statements.
+
+Statements are separated by the semicolons which are not in quotes or comments,
+comments (#, //, -- and block comments) are dropped from the statements.
+*/
+type Scanner struct {
+	r      *bufio.Reader
+	offset int64
+	text   string
+	err    error
+}
+
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{r: bufio.NewReader(r)}
+}
+
+// Scan advances to the next non-empty statement, it returns false at the end of the script or on error
+func (s *Scanner) Scan() bool {
+	for {
+		text, eof, err := s.next()
+		if err != nil {
+			s.err = err
+			return false
+		}
+		if text != "" {
+			s.text = text
+			return true
+		}
+		if eof {
+			return false
+		}
+	}
+}
+
+// Text returns the statement without the trailing semicolon
+func (s *Scanner) Text() string {
+	return s.text
+}
+
+// Offset returns the number of bytes consumed up to the end of the current statement
+func (s *Scanner) Offset() int64 {
+	return s.offset
+}
+
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+func (s *Scanner) next() (string, bool, error) {
+	var (
+		sb    strings.Builder
+		state = stateNormal
+	)
+	for {
+		b, err := s.r.ReadByte()
+		if err == io.EOF {
+			return strings.TrimSpace(sb.String()), true, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		s.offset++
+
+		switch state {
+		case stateNormal:
+			switch {
+			case b == ';':
+				return strings.TrimSpace(sb.String()), false, nil
+			case b == '\'':
+				state = stateSingleQuote
+			case b == '"':
+				state = stateDoubleQuote
+			case b == '`':
+				state = stateBacktick
+			case b == '#':
+				state = stateLineComment
+				continue
+			case b == '/' && s.peek() == '/':
+				state = stateLineComment
+				continue
+			case b == '/' && s.peek() == '*':
+				s.skip()
+				state = stateBlockComment
+				continue
+			case b == '-' && s.peek() == '-' && s.isLineComment():
+				state = stateLineComment
+				continue
+			}
+		case stateSingleQuote, stateDoubleQuote, stateBacktick:
+			if b == '\\' {
+				sb.WriteByte(b)
+				if next, err := s.r.ReadByte(); err == nil {
+					s.offset++
+					b = next
+				}
+			} else if (state == stateSingleQuote && b == '\'') ||
+				(state == stateDoubleQuote && b == '"') ||
+				(state == stateBacktick && b == '`') {
+				state = stateNormal
+			}
+		case stateLineComment:
+			if b == '\n' {
+				state = stateNormal
+				sb.WriteByte(b)
+			}
+			continue
+		case stateBlockComment:
+			if b == '*' && s.peek() == '/' {
+				s.skip()
+				state = stateNormal
+				sb.WriteByte(' ')
+			}
+			continue
+		}
+		sb.WriteByte(b)
+	}
+}
+
+func (s *Scanner) peek() byte {
+	next, err := s.r.Peek(1)
+	if err != nil {
+		return 0
+	}
+	return next[0]
+}
+
+// isLineComment tells whether the "--" is followed by a space or the line end,
+// so that it is not mistaken for the edge pattern like (a)-->(b)
+func (s *Scanner) isLineComment() bool {
+	next, err := s.r.Peek(2)
+	if err != nil {
+		return len(next) == 1
+	}
+	return next[1] == ' ' || next[1] == '\t' || next[1] == '\n' || next[1] == '\r'
+}
+
+func (s *Scanner) skip() {
+	if _, err := s.r.ReadByte(); err == nil {
+		s.offset++
+	}
+}
@@ -0,0 +1,77 @@
+package ngql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scanAll(script string) ([]string, []int64) {
+	s := NewScanner(strings.NewReader(script))
+	var (
+		stmts   []string
+		offsets []int64
+	)
+	for s.Scan() {
+		stmts = append(stmts, s.Text())
+		offsets = append(offsets, s.Offset())
+	}
+	return stmts, offsets
+}
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "split by semicolon",
+			script: "USE s1;\nINSERT VERTEX t(a) VALUES \"1\":(1);  \n",
+			want:   []string{"USE s1", "INSERT VERTEX t(a) VALUES \"1\":(1)"},
+		},
+		{
+			name:   "semicolon in quotes",
+			script: `INSERT VERTEX t(a) VALUES "1":("a;b"), '2':('c;\'d'); SHOW TAGS`,
+			want:   []string{`INSERT VERTEX t(a) VALUES "1":("a;b"), '2':('c;\'d')`, "SHOW TAGS"},
+		},
+		{
+			name:   "semicolon in backticks",
+			script: "CREATE TAG `a;b`(name string);",
+			want:   []string{"CREATE TAG `a;b`(name string)"},
+		},
+		{
+			name:   "comments",
+			script: "# comment; here\nSHOW SPACES; // another;\n/* block; \n comment */SHOW TAGS;\n-- dash; comment\nSHOW EDGES",
+			want:   []string{"SHOW SPACES", "SHOW TAGS", "SHOW EDGES"},
+		},
+		{
+			name:   "edge pattern is not comment",
+			script: "MATCH (a)-->(b) RETURN a;",
+			want:   []string{"MATCH (a)-->(b) RETURN a"},
+		},
+		{
+			name:   "empty statements",
+			script: ";;\n ; SHOW HOSTS;;",
+			want:   []string{"SHOW HOSTS"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := scanAll(tt.script)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScanner_Offset(t *testing.T) {
+	script := "SHOW SPACES;\nSHOW TAGS;\nSHOW EDGES"
+	stmts, offsets := scanAll(script)
+	assert.Equal(t, []string{"SHOW SPACES", "SHOW TAGS", "SHOW EDGES"}, stmts)
+	assert.Equal(t, []int64{12, 23, int64(len(script))}, offsets)
+
+	// resume from the offset of the first statement
+	rest, _ := scanAll(script[offsets[0]:])
+	assert.Equal(t, stmts[1:], rest)
+}
@@ -0,0 +1,474 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/axgle/mahonia"
+	"github.com/saintfish/chardet"
+)
+
+const (
+	TypeInt      = "int"
+	TypeDouble   = "double"
+	TypeBool     = "bool"
+	TypeDate     = "date"
+	TypeDatetime = "datetime"
+	TypeString   = "string"
+
+	// DefaultSampleSize is the bytes read from the head of the file to detect the settings
+	DefaultSampleSize = 64 << 10
+	// DefaultRows is the rows previewed after the header
+	DefaultRows = 10
+
+	// QuoteNone means the fields are not quoted
+	QuoteNone = "none"
+
+	// detectRecords is the records parsed to tell the delimiter and the quote
+	detectRecords = 50
+)
+
+// ErrBinary is returned for the file which is not text
+var ErrBinary = errors.New("the file is not text")
+
+var (
+	// delimiters are the candidates of the delimiter in the order of the preference when they fit the sample equally
+	delimiters = []rune{',', '\t', ';', '|'}
+	quotes     = []rune{'"', '\''}
+
+	dateLayouts     = []string{"2006-01-02", "2006/01/02"}
+	datetimeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04:05.999999999",
+		"2006/01/02 15:04:05",
+	}
+	// namePattern matches the cells of the header, which are names rather than values
+	namePattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_ .:()/-]*$`)
+)
+
+type (
+	// Options are the settings known of the file, which are detected if they are not set
+	Options struct {
+		// Rows is the most rows previewed after the header
+		Rows int
+		// SampleSize is the bytes read from the head of the file
+		SampleSize int
+		Delimiter  string
+		// Quote is the character quoting the fields, `none` means the fields are not quoted
+		Quote      string
+		WithHeader *bool
+	}
+
+	Column struct {
+		Name string
+		// Type is int, double, bool, date, datetime or string, which is inferred from the values previewed
+		Type string
+	}
+
+	// Result is the settings detected and the rows parsed by them
+	Result struct {
+		Encoding   string
+		Delimiter  string
+		Quote      string
+		WithHeader bool
+		Columns    []Column
+		Rows       [][]string
+		// Truncated tells the file is larger than the sample
+		Truncated bool
+	}
+)
+
+/*
+Detect reads the head of the file, it detects the encoding, the delimiter, the quote and the header which are not given by the options,
+the rows are parsed with the quoted fields across the lines, and the type of each column is inferred from the rows
+*/
+func Detect(r io.Reader, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = DefaultSampleSize
+	}
+	if opts.Rows <= 0 {
+		opts.Rows = DefaultRows
+	}
+	sample, err := io.ReadAll(io.LimitReader(r, int64(opts.SampleSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	truncated := len(sample) > opts.SampleSize
+	if truncated {
+		sample = sample[:opts.SampleSize]
+	}
+	encoding, text, err := decode(sample, truncated)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Encoding: encoding, Truncated: truncated}
+
+	delimiter, quote, err := opts.runes()
+	if err != nil {
+		return nil, err
+	}
+	if delimiter == 0 || opts.Quote == "" {
+		delimiter, quote = detectFormat(text, delimiter, quote, opts.Quote == QuoteNone)
+	}
+	res.Delimiter, res.Quote = string(delimiter), QuoteNone
+	if quote != 0 {
+		res.Quote = string(quote)
+	}
+
+	records, complete := parse(text, delimiter, quote, -1)
+	// the last record may be cut by the sample
+	if truncated && !complete && len(records) > 1 {
+		records = records[:len(records)-1]
+	}
+	if opts.WithHeader != nil {
+		res.WithHeader = *opts.WithHeader
+	} else {
+		res.WithHeader = detectHeader(records)
+	}
+	var header []string
+	if res.WithHeader && len(records) > 0 {
+		header, records = records[0], records[1:]
+	}
+	width := len(header)
+	for _, record := range records {
+		if len(record) > width {
+			width = len(record)
+		}
+	}
+	res.Columns = make([]Column, width)
+	for i := range res.Columns {
+		if i < len(header) {
+			res.Columns[i].Name = header[i]
+		}
+		res.Columns[i].Type = inferType(columnValues(records, i))
+	}
+	if len(records) > opts.Rows {
+		records = records[:opts.Rows]
+	}
+	res.Rows = records
+	return res, nil
+}
+
+// Validate checks that the delimiter and the quote given are single characters
+func (o *Options) Validate() error {
+	_, _, err := o.runes()
+	return err
+}
+
+func (o *Options) runes() (delimiter, quote rune, err error) {
+	if o.Delimiter != "" {
+		if delimiter, err = settingRune("delimiter", o.Delimiter); err != nil {
+			return 0, 0, err
+		}
+	}
+	if o.Quote != "" && o.Quote != QuoteNone {
+		if quote, err = settingRune("quote", o.Quote); err != nil {
+			return 0, 0, err
+		}
+		if quote == delimiter {
+			return 0, 0, errors.New("the quote should differ from the delimiter")
+		}
+	}
+	return delimiter, quote, nil
+}
+
+func settingRune(name, s string) (rune, error) {
+	if s == `\t` {
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("the %s should be a character: %q", name, s)
+	}
+	return r, nil
+}
+
+// decode converts the sample to UTF-8, the encoding is told by the BOM or detected from the bytes
+func decode(sample []byte, truncated bool) (string, string, error) {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return "UTF-8", string(sample[3:]), nil
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return "UTF-16LE", decodeUTF16(sample[2:], false), nil
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return "UTF-16BE", decodeUTF16(sample[2:], true), nil
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return "", "", ErrBinary
+	}
+	valid := sample
+	if truncated {
+		// the last character may be cut by the sample
+		for i := 0; i < utf8.UTFMax-1 && len(valid) > 0 && !utf8.Valid(valid); i++ {
+			valid = valid[:len(valid)-1]
+		}
+	}
+	if utf8.Valid(valid) {
+		return "UTF-8", string(valid), nil
+	}
+	best, err := chardet.NewTextDetector().DetectBest(sample)
+	if err != nil {
+		return "", "", err
+	}
+	decoder := mahonia.NewDecoder(best.Charset)
+	if decoder == nil {
+		return "", "", fmt.Errorf("the charset %s is not supported", best.Charset)
+	}
+	return best.Charset, decoder.ConvertString(string(sample)), nil
+}
+
+func decodeUTF16(b []byte, bigEndian bool) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		if bigEndian {
+			u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+		} else {
+			u = append(u, uint16(b[i+1])<<8|uint16(b[i]))
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+/*
+detectFormat tries the delimiters and the quotes which are not given, the one parses the most records into the same count of fields wins,
+the quote which quotes more fields is preferred, the comma and the double quote are the defaults
+*/
+func detectFormat(text string, delimiter, quote rune, noQuote bool) (rune, rune) {
+	candidateDelimiters := delimiters
+	if delimiter != 0 {
+		candidateDelimiters = []rune{delimiter}
+	}
+	candidateQuotes := quotes
+	switch {
+	case noQuote:
+		candidateQuotes = []rune{0}
+	case quote != 0:
+		candidateQuotes = []rune{quote}
+	}
+	type score struct {
+		consistency float64
+		quoted      int
+		width       int
+	}
+	bestDelimiter, bestQuote := candidateDelimiters[0], candidateQuotes[0]
+	var best *score
+	for _, d := range candidateDelimiters {
+		for _, q := range candidateQuotes {
+			records, _ := parse(text, d, q, detectRecords)
+			if len(records) == 0 {
+				continue
+			}
+			counts := map[int]int{}
+			for _, record := range records {
+				counts[len(record)]++
+			}
+			width, same := 0, 0
+			for w, n := range counts {
+				if n > same || (n == same && w > width) {
+					width, same = w, n
+				}
+			}
+			if width < 2 {
+				continue
+			}
+			s := &score{consistency: float64(same) / float64(len(records)), quoted: countQuoted(text, d, q), width: width}
+			if best == nil || s.consistency > best.consistency ||
+				(s.consistency == best.consistency && (s.quoted > best.quoted || (s.quoted == best.quoted && s.width > best.width))) {
+				best, bestDelimiter, bestQuote = s, d, q
+			}
+		}
+	}
+	return bestDelimiter, bestQuote
+}
+
+// countQuoted counts the fields which start with the quote in the head of the text
+func countQuoted(text string, delimiter, quote rune) int {
+	if quote == 0 {
+		return 0
+	}
+	n := 0
+	atStart := true
+	for i, r := range text {
+		if i > DefaultSampleSize/4 {
+			break
+		}
+		if atStart && r == quote {
+			n++
+		}
+		atStart = r == delimiter || r == '\n'
+	}
+	return n
+}
+
+/*
+parse splits the text into the records, the quoted fields may contain the delimiters, the line breaks and the doubled quotes,
+at most limit records are parsed if it is positive, complete tells the last record ends with a line break
+*/
+func parse(text string, delimiter, quote rune, limit int) (records [][]string, complete bool) {
+	var (
+		record  []string
+		field   strings.Builder
+		quoted  bool
+		inQuote bool
+	)
+	endField := func() {
+		record = append(record, field.String())
+		field.Reset()
+		quoted = false
+	}
+	endRecord := func() {
+		endField()
+		// the empty lines are skipped
+		if len(record) > 1 || record[0] != "" {
+			records = append(records, record)
+		}
+		record = nil
+	}
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if inQuote {
+			if r == quote {
+				if i+1 < len(runes) && runes[i+1] == quote {
+					field.WriteRune(quote)
+					i++
+					continue
+				}
+				inQuote = false
+				continue
+			}
+			field.WriteRune(r)
+			continue
+		}
+		switch {
+		case r == quote && quote != 0 && field.Len() == 0 && !quoted:
+			inQuote, quoted = true, true
+		case r == delimiter:
+			endField()
+		case r == '\r' && i+1 < len(runes) && runes[i+1] == '\n':
+		case r == '\n' || r == '\r':
+			endRecord()
+			if limit > 0 && len(records) >= limit {
+				return records, true
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 || len(record) > 0 || inQuote {
+		endRecord()
+		return records, false
+	}
+	return records, true
+}
+
+func columnValues(records [][]string, i int) []string {
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		if i < len(record) {
+			values = append(values, record[i])
+		}
+	}
+	return values
+}
+
+// inferType is the narrowest type of the values which are not empty, the ints are doubles among the doubles, the dates are datetimes among the datetimes
+func inferType(values []string) string {
+	seen := map[string]bool{}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			seen[valueType(v)] = true
+		}
+	}
+	switch {
+	case len(seen) == 0:
+		return TypeString
+	case len(seen) == 1:
+		for t := range seen {
+			return t
+		}
+	case len(seen) == 2 && seen[TypeInt] && seen[TypeDouble]:
+		return TypeDouble
+	case len(seen) == 2 && seen[TypeDate] && seen[TypeDatetime]:
+		return TypeDatetime
+	}
+	return TypeString
+}
+
+func valueType(v string) string {
+	digits := strings.TrimLeft(v, "+-")
+	// the leading zeros are kept in the strings, e.g. the ids
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return TypeString
+	}
+	if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return TypeInt
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil && strings.ContainsAny(v, "0123456789") && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return TypeDouble
+	}
+	if strings.EqualFold(v, "true") || strings.EqualFold(v, "false") {
+		return TypeBool
+	}
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, v); err == nil {
+			return TypeDate
+		}
+	}
+	for _, layout := range datetimeLayouts {
+		if _, err := time.Parse(layout, v); err == nil {
+			return TypeDatetime
+		}
+	}
+	return TypeString
+}
+
+/*
+detectHeader tells whether the first record is the header, the cells of the header are distinct names which are not values,
+and the types of the columns differ from the header, or the names are not among the values of their columns
+*/
+func detectHeader(records [][]string) bool {
+	if len(records) < 2 {
+		return false
+	}
+	header, body := records[0], records[1:]
+	names := map[string]bool{}
+	for _, cell := range header {
+		cell = strings.TrimSpace(cell)
+		if cell == "" || names[cell] || valueType(cell) != TypeString {
+			return false
+		}
+		names[cell] = true
+	}
+	for i := range header {
+		if inferType(columnValues(body, i)) != TypeString {
+			return true
+		}
+	}
+	for i, cell := range header {
+		if !namePattern.MatchString(strings.TrimSpace(cell)) {
+			return false
+		}
+		for _, v := range columnValues(body, i) {
+			if v == cell {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/axgle/mahonia"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	csv := "id;name;score;active;birthday;note\n" +
+		"1;Tom;90.5;true;2000-01-02;\"likes;semicolons\"\n" +
+		"2;Jerry;85;false;2001-03-04;\"says \"\"hi\"\"\nacross lines\"\n" +
+		"3;Spike;77;TRUE;2002-05-06;\n"
+	res, err := Detect(strings.NewReader(csv), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, "UTF-8", res.Encoding)
+	assert.Equal(t, ";", res.Delimiter)
+	assert.Equal(t, `"`, res.Quote)
+	assert.True(t, res.WithHeader)
+	assert.Equal(t, []Column{
+		{Name: "id", Type: TypeInt},
+		{Name: "name", Type: TypeString},
+		{Name: "score", Type: TypeDouble},
+		{Name: "active", Type: TypeBool},
+		{Name: "birthday", Type: TypeDate},
+		{Name: "note", Type: TypeString},
+	}, res.Columns)
+	assert.Len(t, res.Rows, 3)
+	assert.Equal(t, "likes;semicolons", res.Rows[0][5])
+	assert.Equal(t, "says \"hi\"\nacross lines", res.Rows[1][5])
+	assert.False(t, res.Truncated)
+
+	// the settings given are not detected
+	withHeader := false
+	res, err = Detect(strings.NewReader(csv), Options{Delimiter: ",", Quote: QuoteNone, WithHeader: &withHeader, Rows: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, ",", res.Delimiter)
+	assert.Equal(t, QuoteNone, res.Quote)
+	assert.False(t, res.WithHeader)
+	assert.Len(t, res.Rows, 2)
+
+	_, err = Detect(strings.NewReader(csv), Options{Delimiter: ";;"})
+	assert.NotNil(t, err)
+}
+
+func TestDetect_NoHeader(t *testing.T) {
+	tsv := "1001\t2023-01-02 10:00:00\t0.5\n1002\t2023-01-03T11:30:00\t7\n0003\t2023-01-04\t-1\n"
+	res, err := Detect(strings.NewReader(tsv), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, "\t", res.Delimiter)
+	assert.False(t, res.WithHeader)
+	assert.Equal(t, []string{TypeString, TypeDatetime, TypeDouble}, []string{res.Columns[0].Type, res.Columns[1].Type, res.Columns[2].Type})
+	assert.Len(t, res.Rows, 3)
+
+	// the names which are among the values are not the header
+	res, err = Detect(strings.NewReader("a|b\nc|d\na|e\n"), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, "|", res.Delimiter)
+	assert.False(t, res.WithHeader)
+}
+
+func TestDetect_Encoding(t *testing.T) {
+	text := "名称,城市\n张三,北京\n李四,上海\n王五,广州\n赵六,深圳\n"
+	gbk := mahonia.NewEncoder("GB18030").ConvertString(text)
+	res, err := Detect(strings.NewReader(gbk), Options{})
+	assert.Nil(t, err)
+	assert.NotEqual(t, "UTF-8", res.Encoding)
+	assert.True(t, res.WithHeader)
+	assert.Equal(t, "名称", res.Columns[0].Name)
+	assert.Equal(t, []string{"张三", "北京"}, res.Rows[0])
+
+	res, err = Detect(strings.NewReader("\xEF\xBB\xBFa,b\n1,2\n"), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, "UTF-8", res.Encoding)
+	assert.Equal(t, "a", res.Columns[0].Name)
+
+	_, err = Detect(strings.NewReader("PAR1\x00\x01\x02"), Options{})
+	assert.Equal(t, ErrBinary, err)
+}
+
+func TestDetect_Truncated(t *testing.T) {
+	csv := "id,text\n" + strings.Repeat("1,\"a long\nquoted field\"\n", 100)
+	res, err := Detect(strings.NewReader(csv), Options{SampleSize: 100, Rows: 100})
+	assert.Nil(t, err)
+	assert.True(t, res.Truncated)
+	for _, row := range res.Rows {
+		assert.Equal(t, []string{"1", "a long\nquoted field"}, row)
+	}
+}
@@ -0,0 +1,222 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// EnvKey is the environment variable of the master key, which is preferred to the key and the key file of the config
+	EnvKey = "STUDIO_SECRETS_KEY"
+
+	// envelopeVersion prefixes the envelope `v1:{key id}:{base64 of the nonce and the sealed secret}`
+	envelopeVersion = "v1"
+
+	// legacyKey is the AES-CBC key of the secrets saved before the envelopes, it is the master key if none is configured
+	legacyKey = "6b6579736f6d6574616c6b6579736f6d"
+)
+
+type (
+	// Keyring encrypts the secrets by AES-GCM with the current master key, the secrets are decrypted by the key whose id is in the envelope,
+	// the previous keys are kept to decrypt the secrets which are not rotated yet
+	Keyring struct {
+		current *masterKey
+		keys    map[string]*masterKey
+	}
+
+	masterKey struct {
+		id   string
+		aead cipher.AEAD
+	}
+)
+
+var (
+	mu             sync.RWMutex
+	defaultKeyring = mustKeyring(legacyKey)
+)
+
+func mustKeyring(key string) *Keyring {
+	k, err := NewKeyring(key)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// NewKeyring derives the AES-256 keys from the master keys by SHA-256, the legacy key is always kept to decrypt the secrets saved without a configured key
+func NewKeyring(current string, previous ...string) (*Keyring, error) {
+	if current == "" {
+		return nil, errors.New("the master key is required")
+	}
+	k := &Keyring{keys: map[string]*masterKey{}}
+	for i, key := range append([]string{current, legacyKey}, previous...) {
+		if key == "" {
+			continue
+		}
+		mk, err := newMasterKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			k.current = mk
+		}
+		if _, ok := k.keys[mk.id]; !ok {
+			k.keys[mk.id] = mk
+		}
+	}
+	return k, nil
+}
+
+func newMasterKey(key string) (*masterKey, error) {
+	derived := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// the id tells the key of the envelope without revealing it
+	id := sha256.Sum256(derived[:])
+	return &masterKey{id: hex.EncodeToString(id[:4]), aead: aead}, nil
+}
+
+// KeyID is the id of the current master key
+func (k *Keyring) KeyID() string {
+	return k.current.id
+}
+
+// Encrypt seals the secret into the envelope of the current master key
+func (k *Keyring) Encrypt(plain string) (string, error) {
+	nonce := make([]byte, k.current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	sealed := k.current.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return strings.Join([]string{envelopeVersion, k.current.id, base64.StdEncoding.EncodeToString(sealed)}, ":"), nil
+}
+
+// Decrypt opens the envelope by the key of its id, the secret which is not an envelope is decrypted by the legacy AES-CBC key
+func (k *Keyring) Decrypt(encrypted string) (string, error) {
+	if encrypted == "" {
+		return "", nil
+	}
+	if !IsEnvelope(encrypted) {
+		plain, err := utils.Decrypt(encrypted, []byte(legacyKey))
+		if err != nil {
+			return "", err
+		}
+		return string(plain), nil
+	}
+	parts := strings.SplitN(encrypted, ":", 3)
+	if len(parts) != 3 {
+		return "", errors.New("invalid secret envelope")
+	}
+	mk, ok := k.keys[parts[1]]
+	if !ok {
+		return "", fmt.Errorf("the master key %s of the secret is not configured", parts[1])
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %v", err)
+	}
+	size := mk.aead.NonceSize()
+	if len(sealed) < size {
+		return "", errors.New("invalid secret envelope")
+	}
+	plain, err := mk.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %v", err)
+	}
+	return string(plain), nil
+}
+
+// Rotate re-encrypts the secret by the current master key, it returns false if the secret is empty or already encrypted by the current key
+func (k *Keyring) Rotate(encrypted string) (string, bool, error) {
+	if encrypted == "" || strings.HasPrefix(encrypted, envelopeVersion+":"+k.current.id+":") {
+		return encrypted, false, nil
+	}
+	plain, err := k.Decrypt(encrypted)
+	if err != nil {
+		return "", false, err
+	}
+	rotated, err := k.Encrypt(plain)
+	if err != nil {
+		return "", false, err
+	}
+	return rotated, true, nil
+}
+
+// IsEnvelope tells whether the secret is sealed by a master key, the others are saved by the legacy key
+func IsEnvelope(s string) bool {
+	return strings.HasPrefix(s, envelopeVersion+":")
+}
+
+/*
+LoadKeyring reads the master key from the environment variable, the key or the key file of the config in order,
+the legacy key is the master key if none is configured, which should only be used for trial
+*/
+func LoadKeyring(c config.SecretsConfig) (*Keyring, error) {
+	key := os.Getenv(EnvKey)
+	if key == "" {
+		key = c.Key
+	}
+	if key == "" && c.KeyFile != "" {
+		data, err := os.ReadFile(c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read the master key file error: %v", err)
+		}
+		key = strings.TrimSpace(string(data))
+		if key == "" {
+			return nil, fmt.Errorf("the master key file %s is empty", c.KeyFile)
+		}
+	}
+	if key == "" {
+		logx.Infof("no master key is configured, the secrets are encrypted by the built-in key, set %s or Secrets.Key to protect them", EnvKey)
+		key = legacyKey
+	}
+	return NewKeyring(key, c.PreviousKeys...)
+}
+
+// Init loads the keyring of the config as the default one
+func Init(c config.SecretsConfig) error {
+	k, err := LoadKeyring(c)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	defaultKeyring = k
+	return nil
+}
+
+// Default is the keyring loaded by Init, which is of the legacy key before it
+func Default() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultKeyring
+}
+
+// Encrypt seals the secret by the default keyring
+func Encrypt(plain string) (string, error) {
+	return Default().Encrypt(plain)
+}
+
+// Decrypt opens the secret by the default keyring
+func Decrypt(encrypted string) (string, error) {
+	return Default().Decrypt(encrypted)
+}
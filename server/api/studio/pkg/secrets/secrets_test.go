@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+)
+
+func TestKeyring(t *testing.T) {
+	old, err := NewKeyring("old master key")
+	assert.Nil(t, err)
+	encrypted, err := old.Encrypt("s3cret")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, "v1:"+old.KeyID()+":"))
+	plain, err := old.Decrypt(encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cret", plain)
+
+	// the secret sealed by the key removed can not be decrypted
+	current, err := NewKeyring("new master key")
+	assert.Nil(t, err)
+	_, err = current.Decrypt(encrypted)
+	assert.NotNil(t, err)
+
+	current, err = NewKeyring("new master key", "old master key")
+	assert.Nil(t, err)
+	plain, err = current.Decrypt(encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cret", plain)
+
+	// the tampered secret is not opened
+	tampered := encrypted[:len(encrypted)-4] + "AAA="
+	_, err = current.Decrypt(tampered)
+	assert.NotNil(t, err)
+
+	rotated, ok, err := current.Rotate(encrypted)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, strings.HasPrefix(rotated, "v1:"+current.KeyID()+":"))
+	_, ok, err = current.Rotate(rotated)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	plain, err = current.Decrypt(rotated)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cret", plain)
+
+	// the secrets of the legacy key are decrypted and rotated
+	legacy, err := utils.Encrypt([]byte("legacy"), []byte(legacyKey))
+	assert.Nil(t, err)
+	plain, err = current.Decrypt(legacy)
+	assert.Nil(t, err)
+	assert.Equal(t, "legacy", plain)
+	rotated, ok, err = current.Rotate(legacy)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, IsEnvelope(rotated))
+
+	plain, err = current.Decrypt("")
+	assert.Nil(t, err)
+	assert.Equal(t, "", plain)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "master.key")
+	assert.Nil(t, os.WriteFile(keyFile, []byte("file master key\n"), 0o600))
+	fromFile, err := NewKeyring("file master key")
+	assert.Nil(t, err)
+	fromConfig, err := NewKeyring("config master key")
+	assert.Nil(t, err)
+	fromEnv, err := NewKeyring("env master key")
+	assert.Nil(t, err)
+	builtIn, err := NewKeyring(legacyKey)
+	assert.Nil(t, err)
+
+	k, err := LoadKeyring(config.SecretsConfig{KeyFile: keyFile})
+	assert.Nil(t, err)
+	assert.Equal(t, fromFile.KeyID(), k.KeyID())
+
+	k, err = LoadKeyring(config.SecretsConfig{Key: "config master key", KeyFile: keyFile})
+	assert.Nil(t, err)
+	assert.Equal(t, fromConfig.KeyID(), k.KeyID())
+
+	t.Setenv(EnvKey, "env master key")
+	k, err = LoadKeyring(config.SecretsConfig{Key: "config master key"})
+	assert.Nil(t, err)
+	assert.Equal(t, fromEnv.KeyID(), k.KeyID())
+
+	t.Setenv(EnvKey, "")
+	k, err = LoadKeyring(config.SecretsConfig{})
+	assert.Nil(t, err)
+	assert.Equal(t, builtIn.KeyID(), k.KeyID())
+
+	_, err = LoadKeyring(config.SecretsConfig{KeyFile: filepath.Join(t.TempDir(), "missing.key")})
+	assert.NotNil(t, err)
+}
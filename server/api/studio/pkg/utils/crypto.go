@@ -47,6 +47,10 @@ func Decrypt(encoded string, key []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to decode ciphertext: %v", err)
 	}
 
+	if len(ciphertext) < 2*aes.BlockSize || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid ciphertext length: %d", len(ciphertext))
+	}
+
 	// Split the initialization vector (IV) and the ciphertext.
 	iv := ciphertext[:aes.BlockSize]
 	ciphertext = ciphertext[aes.BlockSize:]
@@ -2,10 +2,12 @@ package utils
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 func CreateDir(dir string) error {
@@ -75,3 +77,45 @@ func ReadPartFile(path string) ([]string, error) {
 
 	return topLines, nil
 }
+
+/*
+TailFile reads the complete lines of the file written after the offset, at most maxBytes are read each time,
+it returns the offset to read from next time, the offset beyond the end of the file is read from the start
+*/
+func TailFile(path string, offset, maxBytes int64) ([]string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	if offset < 0 || offset > info.Size() {
+		offset = 0
+	}
+	size := info.Size() - offset
+	if size > maxBytes {
+		size = maxBytes
+	}
+	buf := make([]byte, size)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return nil, offset, err
+	}
+	buf = buf[:n]
+
+	end := bytes.LastIndexByte(buf, '\n')
+	if end < 0 {
+		if int64(n) < maxBytes {
+			// the last line is still being written
+			return []string{}, offset, nil
+		}
+		// the line is longer than maxBytes, it is split
+		return []string{string(buf)}, offset + int64(n), nil
+	}
+	lines := strings.Split(string(buf[:end+1]), "\n")
+	return lines[:len(lines)-1], offset + int64(end) + 1, nil
+}
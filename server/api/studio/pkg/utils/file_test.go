@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		offset   int64
+		maxBytes int64
+		lines    []string
+		next     int64
+	}{
+		{name: "empty", content: "", maxBytes: 10, lines: []string{}, next: 0},
+		{name: "complete lines", content: "a\nbb\n", maxBytes: 100, lines: []string{"a", "bb"}, next: 5},
+		{name: "partial line", content: "a\nbb", maxBytes: 100, lines: []string{"a"}, next: 2},
+		{name: "only partial line", content: "a\nbb", offset: 2, maxBytes: 100, lines: []string{}, next: 2},
+		{name: "from offset", content: "a\nbb\nccc\n", offset: 2, maxBytes: 100, lines: []string{"bb", "ccc"}, next: 9},
+		{name: "at the end", content: "a\nbb\n", offset: 5, maxBytes: 100, lines: []string{}, next: 5},
+		{name: "beyond the end", content: "a\nbb\n", offset: 6, maxBytes: 100, lines: []string{"a", "bb"}, next: 5},
+		{name: "negative offset", content: "a\n", offset: -1, maxBytes: 100, lines: []string{"a"}, next: 2},
+		{name: "max bytes", content: "a\nbb\nccc\n", maxBytes: 6, lines: []string{"a", "bb"}, next: 5},
+		{name: "long line split", content: "abcdef\n", maxBytes: 4, lines: []string{"abcd"}, next: 4},
+		{name: "empty lines", content: "\n\na\n", maxBytes: 100, lines: []string{"", "", "a"}, next: 4},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "a.log")
+			assert.Nil(t, os.WriteFile(path, []byte(tc.content), 0o644))
+			lines, next, err := TailFile(path, tc.offset, tc.maxBytes)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.lines, lines)
+			assert.Equal(t, tc.next, next)
+		})
+	}
+
+	_, next, err := TailFile(filepath.Join(t.TempDir(), "not-exist.log"), 3, 10)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(3), next)
+}
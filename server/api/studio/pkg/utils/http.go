@@ -18,9 +18,11 @@ var (
 	ReserveResponseRoutes = []string{
 		"/api-nebula/db/",
 		"/api/import-tasks",
+		"/api/export-tasks",
 	}
 	IgnoreHandlerBodyPatterns = []*regexp.Regexp{
 		regexp.MustCompile(`^/api/import-tasks/\w+/download`),
+		regexp.MustCompile(`^/api/export-tasks/\w+/download`),
 	}
 )
 
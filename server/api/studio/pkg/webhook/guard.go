@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// the ranges not covered by the methods of net.IP
+var internalNets = mustParseCIDRs("0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15")
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+/*
+CheckURL checks the url of a webhook of a user, whose host must not be resolved to the loopback, private or link-local addresses
+(e.g. the metadata service of the cloud), unless the host or the address is in the allowed hosts
+*/
+func CheckURL(rawURL string, allowedHosts []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid webhook url: %s", rawURL)
+	}
+	host := u.Hostname()
+	if hostAllowed(host, allowedHosts) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve the webhook host %s error: %s", host, err)
+	}
+	for _, addr := range addrs {
+		if err := checkIP(addr.IP, allowedHosts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hostAllowed tells whether the host name or the address is one of the allowed hosts, which can also be the CIDRs
+func hostAllowed(host string, allowedHosts []string) bool {
+	ip := net.ParseIP(host)
+	for _, h := range allowedHosts {
+		h = strings.TrimSpace(h)
+		if strings.EqualFold(h, host) {
+			return true
+		}
+		if _, n, err := net.ParseCIDR(h); err == nil && ip != nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkIP(ip net.IP, allowedHosts []string) error {
+	if ip == nil || !isInternal(ip) || hostAllowed(ip.String(), allowedHosts) {
+		return nil
+	}
+	return fmt.Errorf("the webhook can not reach the internal address %s", ip)
+}
+
+func isInternal(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, n := range internalNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+userClient sends the requests to the webhooks of the users, the addresses are checked when they are dialed,
+so neither a host resolved to another address after it is saved nor a redirect can reach the internal network,
+and the proxy of the environment is not used for it would dial instead
+*/
+func userClient(timeout time.Duration, allowedHosts []string) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	guarded := &net.Dialer{
+		Timeout:   dialer.Timeout,
+		KeepAlive: dialer.KeepAlive,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return checkIP(net.ParseIP(host), allowedHosts)
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if hostAllowed(host, allowedHosts) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/idx"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// KindTask is the kind of the events of the import, export and nGQL tasks
+	KindTask = "task"
+	// KindLLMJob is the kind of the events of the LLM import jobs
+	KindLLMJob = "llm_job"
+
+	HeaderEvent     = "X-Studio-Event"
+	HeaderDelivery  = "X-Studio-Delivery"
+	HeaderTimestamp = "X-Studio-Timestamp"
+	// HeaderSignature is `sha256=` followed by the hex HMAC-SHA256 of `{timestamp}.{body}` keyed by the secret
+	HeaderSignature = "X-Studio-Signature"
+
+	queueSize = 1024
+	workers   = 4
+)
+
+type (
+	// Event is the payload posted to the webhooks when a task or a job changes its status
+	Event struct {
+		// Event is `{kind}.{status}` in lower case, e.g. task.success or llm_job.failed
+		Event    string      `json:"event"`
+		Time     int64       `json:"time"`
+		Address  string      `json:"address"`
+		User     string      `json:"user"`
+		TaskID   string      `json:"taskId"`
+		TaskType string      `json:"taskType"`
+		Name     string      `json:"name"`
+		Space    string      `json:"space"`
+		Status   string      `json:"status"`
+		Message  string      `json:"message"`
+		Stats    interface{} `json:"stats,omitempty"`
+	}
+
+	delivery struct {
+		id       string
+		url      string
+		secret   string
+		event    string
+		payload  []byte
+		attempts int
+		// user tells the delivery is to a webhook of a user, which can not reach the internal network
+		user bool
+	}
+
+	dispatcher struct {
+		conf    config.WebhookConfig
+		keyring *secrets.Keyring
+		client  *http.Client
+		// userClient is for the webhooks of the users
+		userClient *http.Client
+		queue      chan *delivery
+	}
+)
+
+var (
+	mu sync.RWMutex
+	d  *dispatcher
+)
+
+/*
+Start runs the workers delivering the events, the events are dropped before it is started,
+the secrets of the webhooks of the users are decrypted by the keyring
+*/
+func Start(conf config.WebhookConfig, keyring *secrets.Keyring) {
+	mu.Lock()
+	defer mu.Unlock()
+	if d != nil {
+		return
+	}
+	timeout := time.Duration(conf.Timeout) * time.Second
+	d = &dispatcher{
+		conf:       conf,
+		keyring:    keyring,
+		client:     &http.Client{Timeout: timeout},
+		userClient: userClient(timeout, conf.AllowedHosts),
+		queue:      make(chan *delivery, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go d.run()
+	}
+}
+
+// Notify sends the event to the global webhooks and the webhooks of the user, the requests are sent in the background
+func Notify(kind string, e *Event) {
+	mu.RLock()
+	dp := d
+	mu.RUnlock()
+	if dp == nil {
+		return
+	}
+	e.Event = kind + "." + strings.ToLower(e.Status)
+	e.Time = time.Now().UnixMilli()
+	payload, err := json.Marshal(e)
+	if err != nil {
+		logx.Errorf("[webhook] marshal the event %s of %s error: %s", e.Event, e.TaskID, err)
+		return
+	}
+	for _, global := range dp.conf.Global {
+		if Match(global.Events, e.Event) {
+			dp.enqueue(e, "", global.URL, global.Secret, payload)
+		}
+	}
+	var hooks []*db.Webhook
+	if err := db.CtxDB.Where("host = ? AND username = ? AND enabled = ?", e.Address, e.User, true).Find(&hooks).Error; err != nil {
+		logx.Errorf("[webhook] find the webhooks of %s error: %s", e.User, err)
+		return
+	}
+	for _, hook := range hooks {
+		if !Match(SplitEvents(hook.Events), e.Event) {
+			continue
+		}
+		secret := ""
+		if hook.Secret != "" {
+			plain, err := dp.keyring.Decrypt(hook.Secret)
+			if err != nil {
+				logx.Errorf("[webhook] decrypt the secret of the webhook %s error: %s", hook.BID, err)
+				continue
+			}
+			secret = plain
+		}
+		dp.enqueue(e, hook.BID, hook.URL, secret, payload)
+	}
+}
+
+// Match tells whether the event is matched by any of the patterns, `*` matches the rest of the event, and all the events are matched without patterns
+func Match(patterns []string, event string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if p == event || (strings.HasSuffix(p, "*") && strings.HasPrefix(event, strings.TrimSuffix(p, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitEvents splits the comma separated patterns saved
+func SplitEvents(events string) []string {
+	var patterns []string
+	for _, p := range strings.Split(events, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// Sign returns the value of the signature header
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (dp *dispatcher) enqueue(e *Event, webhookID, url, secret string, payload []byte) {
+	dl := &delivery{id: idx.Generate(), url: url, secret: secret, event: e.Event, payload: payload, user: webhookID != ""}
+	record := &db.WebhookDelivery{
+		BID:       dl.id,
+		WebhookID: webhookID,
+		Host:      e.Address,
+		Username:  e.User,
+		URL:       url,
+		Event:     e.Event,
+		Payload:   string(payload),
+		Status:    db.WebhookDeliveryPending,
+	}
+	if err := db.CtxDB.Create(record).Error; err != nil {
+		logx.Errorf("[webhook] save the delivery of %s error: %s", e.Event, err)
+	}
+	select {
+	case dp.queue <- dl:
+	default:
+		dp.finish(dl, 0, fmt.Errorf("the delivery queue is full"))
+	}
+}
+
+func (dp *dispatcher) run() {
+	for dl := range dp.queue {
+		dl.attempts++
+		code, err := dp.post(dl)
+		if err != nil && dl.attempts <= dp.conf.MaxRetries {
+			// the worker is not blocked by the wait
+			wait := time.Duration(dp.conf.RetryInterval) * time.Second << (dl.attempts - 1)
+			dp.update(dl, code, err, db.WebhookDeliveryPending)
+			time.AfterFunc(wait, func() { dp.queue <- dl })
+			continue
+		}
+		dp.finish(dl, code, err)
+	}
+}
+
+func (dp *dispatcher) post(dl *delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, dl.url, bytes.NewReader(dl.payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nebula-studio-webhook")
+	req.Header.Set(HeaderEvent, dl.event)
+	req.Header.Set(HeaderDelivery, dl.id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if dl.secret != "" {
+		req.Header.Set(HeaderSignature, Sign(dl.secret, timestamp, dl.payload))
+	}
+	client := dp.client
+	if dl.user {
+		client = dp.userClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	// the body is not saved, which could leak the response of a server not meant to be read
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (dp *dispatcher) finish(dl *delivery, code int, err error) {
+	status := db.WebhookDeliverySuccess
+	if err != nil {
+		status = db.WebhookDeliveryFailed
+		logx.Errorf("[webhook] deliver %s to %s failed after %d attempts: %s", dl.event, dl.url, dl.attempts, err)
+	}
+	dp.update(dl, code, err, status)
+}
+
+func (dp *dispatcher) update(dl *delivery, code int, err error, status string) {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	if err := db.CtxDB.Model(&db.WebhookDelivery{}).Where("b_id = ?", dl.id).Updates(map[string]interface{}{
+		"status":        status,
+		"attempts":      dl.attempts,
+		"response_code": code,
+		"error":         message,
+	}).Error; err != nil {
+		logx.Errorf("[webhook] update the delivery %s error: %s", dl.id, err)
+	}
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+	db "github.com/vesoft-inc/nebula-studio/server/api/studio/internal/model"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMatch(t *testing.T) {
+	assert.True(t, Match(nil, "task.success"))
+	assert.True(t, Match([]string{"task.*"}, "task.failed"))
+	assert.True(t, Match([]string{"llm_job.failed", "task.success"}, "task.success"))
+	assert.False(t, Match([]string{"task.*"}, "llm_job.failed"))
+	assert.False(t, Match([]string{"task.success"}, "task.successful"))
+	assert.Equal(t, []string{"task.*", "llm_job.failed"}, SplitEvents(" task.*, ,llm_job.failed"))
+}
+
+func TestNotify(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "studio.db")), &gorm.Config{})
+	assert.Nil(t, err)
+	assert.Nil(t, gdb.AutoMigrate(&db.Webhook{}, &db.WebhookDelivery{}))
+	db.CtxDB = gdb
+
+	keyring, err := secrets.NewKeyring("6b6579736f6d6574696d65736c6f6e67")
+	assert.Nil(t, err)
+	var (
+		calls  int32
+		bodies = make(chan *http.Request, 4)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first request fails to be retried
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		r.Header.Set("X-Verified", strconv.FormatBool(Sign("s3cret", timestamp, body) == r.Header.Get(HeaderSignature)))
+		r.Header.Set("X-Body", string(body))
+		bodies <- r
+	}))
+	defer server.Close()
+
+	secret, err := keyring.Encrypt("s3cret")
+	assert.Nil(t, err)
+	assert.Nil(t, gdb.Create(&db.Webhook{BID: "hook", Host: "127.0.0.1:9669", Username: "root", URL: server.URL, Secret: secret, Events: "task.*", Enabled: true}).Error)
+	assert.Nil(t, gdb.Create(&db.Webhook{BID: "llm", Host: "127.0.0.1:9669", Username: "root", URL: server.URL, Events: "llm_job.*", Enabled: true}).Error)
+
+	// the test server listens on the loopback address, which the webhooks of the users can reach only if it is allowed
+	Start(config.WebhookConfig{MaxRetries: 2, Timeout: 5, AllowedHosts: []string{"127.0.0.1"}}, keyring)
+	defer func() { d = nil }()
+	Notify(KindTask, &Event{Address: "127.0.0.1:9669", User: "root", TaskID: "task1", Name: "import", Space: "basketball", Status: "Success"})
+
+	select {
+	case r := <-bodies:
+		assert.Equal(t, "task.success", r.Header.Get(HeaderEvent))
+		assert.Equal(t, "true", r.Header.Get("X-Verified"))
+		var e Event
+		assert.Nil(t, json.Unmarshal([]byte(r.Header.Get("X-Body")), &e))
+		assert.Equal(t, "task1", e.TaskID)
+		assert.Equal(t, "basketball", e.Space)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event is not delivered")
+	}
+
+	var delivery db.WebhookDelivery
+	assert.Eventually(t, func() bool {
+		return gdb.Where("webhook_id = ?", "hook").First(&delivery).Error == nil && delivery.Status == db.WebhookDeliverySuccess
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseCode)
+
+	var count int64
+	gdb.Model(&db.WebhookDelivery{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCheckURL(t *testing.T) {
+	assert.Nil(t, CheckURL("https://93.184.216.34/hooks", nil))
+	assert.Nil(t, CheckURL("http://127.0.0.1:8080/hooks", []string{"127.0.0.1"}))
+	assert.Nil(t, CheckURL("http://10.0.1.5/hooks", []string{"10.0.1.0/24"}))
+	assert.Nil(t, CheckURL("http://localhost/hooks", []string{"LOCALHOST"}))
+
+	for _, u := range []string{
+		"ftp://93.184.216.34/hooks",
+		"http:///hooks",
+		"http://127.0.0.1:8080/hooks",
+		"http://localhost/hooks",
+		"http://10.0.0.1/hooks",
+		"http://192.168.1.1/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hooks",
+		"http://[fd00:ec2::254]/hooks",
+		"http://[::ffff:127.0.0.1]/hooks",
+		"http://0.0.0.0/hooks",
+		"http://100.64.0.1/hooks",
+		"http://10.0.2.5/hooks",
+	} {
+		assert.NotNil(t, CheckURL(u, []string{"10.0.1.0/24"}), u)
+	}
+}
+
+func TestUserClient(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	// the address is checked when it is dialed, e.g. after the host is resolved to another address
+	_, err := userClient(5*time.Second, nil).Post(server.URL, "application/json", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+	resp, err := userClient(5*time.Second, []string{"127.0.0.1"}).Post(server.URL, "application/json", nil)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,218 @@
+package importtask
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/base"
+	fileutils "github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ws/utils"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	subscribeMsgType   = "import_task_subscribe"
+	unsubscribeMsgType = "import_task_unsubscribe"
+	progressMsgType    = "import_task_progress"
+
+	// maxLogBytes limits the log lines pushed in one message
+	maxLogBytes = 64 * 1024
+)
+
+var (
+	mu sync.Mutex
+	// subscriptions are the tasks followed by the clients, keyed by the client id and the task id
+	subscriptions = make(map[string]chan struct{})
+)
+
+/*
+Middleware pushes the progress of the import task to the client who subscribes it:
+  - the stats are pushed when they are refreshed, and the new lines of the log are pushed with them
+  - the log is read from the logOffset in the subscription, so that the client can resume it after reconnecting
+  - the last message has ended set, then the subscription is done
+*/
+func Middleware(next utils.TNext) utils.TNext {
+	return func(msgReceived *utils.MessageReceive, c *utils.Client) *utils.MessagePost {
+		if next == nil || msgReceived == nil {
+			return nil
+		}
+		switch msgReceived.Body.MsgType {
+		case subscribeMsgType:
+			return subscribe(msgReceived, c)
+		case unsubscribeMsgType:
+			taskID, _ := msgReceived.Body.Content["id"].(string)
+			stopSubscription(c.ID+"/"+taskID, nil)
+			return newMessage(msgReceived, map[string]any{
+				"code":    base.Success,
+				"message": "Success",
+			})
+		}
+		return next(msgReceived, c)
+	}
+}
+
+func subscribe(msgReceived *utils.MessageReceive, c *utils.Client) *utils.MessagePost {
+	taskID, _ := msgReceived.Body.Content["id"].(string)
+	offset, _ := msgReceived.Body.Content["logOffset"].(float64)
+	clientInfo, ok := c.GetClientInfo().(*auth.AuthData)
+	if !ok {
+		return newError(msgReceived, "invalid client info")
+	}
+	host := clientInfo.Address + ":" + strconv.Itoa(clientInfo.Port)
+	taskInfo, err := importer.FindImportTask(taskID, host, clientInfo.Username)
+	if err != nil {
+		return newError(msgReceived, err.Error())
+	}
+
+	// subscribe before reading the current state, so that no event is missed in between
+	events, unsubscribe := importer.SubscribeTask(taskID)
+	current, err := importer.CurrentTaskEvent(taskInfo)
+	if err != nil {
+		unsubscribe()
+		return newError(msgReceived, err.Error())
+	}
+
+	key := c.ID + "/" + taskID
+	stop := make(chan struct{})
+	mu.Lock()
+	if prev, ok := subscriptions[key]; ok {
+		close(prev)
+	}
+	subscriptions[key] = stop
+	mu.Unlock()
+
+	follower := &taskFollower{
+		client:  c,
+		msg:     msgReceived,
+		logPath: filepath.Join(config.GetConfig().File.TasksDir, taskID, importer.TaskLogName(taskInfo.TaskType)),
+		offset:  int64(offset),
+	}
+	go func() {
+		defer unsubscribe()
+		defer stopSubscription(key, stop)
+		if ended := follower.push(current); ended {
+			return
+		}
+		for {
+			select {
+			case event := <-events:
+				if ended := follower.push(event); ended {
+					return
+				}
+			case <-stop:
+				return
+			case <-c.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// stopSubscription ends the subscription of the key, it is only ended if it is the given one when stop is not nil
+func stopSubscription(key string, stop chan struct{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	cur, ok := subscriptions[key]
+	if !ok || (stop != nil && cur != stop) {
+		return
+	}
+	if stop == nil {
+		close(cur)
+	}
+	delete(subscriptions, key)
+}
+
+type taskFollower struct {
+	client  *utils.Client
+	msg     *utils.MessageReceive
+	logPath string
+	offset  int64
+}
+
+// push sends the task with the new log lines, the log is sent in several messages if it is too long
+func (f *taskFollower) push(event *importer.TaskEvent) (ended bool) {
+	lines, next := f.readLog(f.offset)
+	for {
+		// the next lines are read ahead, so that only the last message is marked ended
+		var moreLines []string
+		moreNext := next
+		if next != f.offset {
+			moreLines, moreNext = f.readLog(next)
+		}
+		caughtUp := len(moreLines) == 0
+		f.offset = next
+		data := map[string]any{
+			"task":      event.Task,
+			"logs":      lines,
+			"logOffset": f.offset,
+			"ended":     event.Ended && caughtUp,
+		}
+		if !f.send(data) {
+			return true
+		}
+		if caughtUp {
+			return event.Ended
+		}
+		lines, next = moreLines, moreNext
+	}
+}
+
+func (f *taskFollower) readLog(offset int64) ([]string, int64) {
+	lines, next, err := fileutils.TailFile(f.logPath, offset, maxLogBytes)
+	if err != nil && !os.IsNotExist(err) {
+		logx.Errorf("[WebSocket import task]: read log %s error: %v", f.logPath, err)
+	}
+	if lines == nil {
+		lines = []string{}
+	}
+	return lines, next
+}
+
+func (f *taskFollower) send(data map[string]any) bool {
+	msgPost := newMessage(f.msg, map[string]any{
+		"code":    base.Success,
+		"message": "Success",
+		"data":    data,
+	})
+	msgPost.Body.MsgType = progressMsgType
+	msgSend, err := json.Marshal(msgPost)
+	if err != nil {
+		logx.Errorf("[WebSocket import task]: %v", err)
+		return false
+	}
+	select {
+	case <-f.client.Done():
+		return false
+	default:
+	}
+	f.client.SendMessage(msgSend)
+	return true
+}
+
+func newMessage(msgReceived *utils.MessageReceive, content map[string]any) *utils.MessagePost {
+	return &utils.MessagePost{
+		Header: utils.MessagePostHeader{
+			MsgId:    msgReceived.Header.MsgId,
+			SendTime: time.Now().UnixMilli(),
+		},
+		Body: utils.MessagePostBody{
+			MsgType: msgReceived.Body.MsgType,
+			Content: content,
+		},
+	}
+}
+
+func newError(msgReceived *utils.MessageReceive, message string) *utils.MessagePost {
+	return newMessage(msgReceived, map[string]any{
+		"code":    base.Error,
+		"message": message,
+	})
+}
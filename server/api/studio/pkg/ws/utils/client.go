@@ -39,6 +39,8 @@ type Client struct {
 	Conn *websocket.Conn
 	// Buffered channel of outbound messages.
 	send chan []byte
+	// closed when the client is unregistered from the hub
+	done chan struct{}
 	// message received middleware
 	dispatcher TNext
 	// after destroy callback
@@ -57,6 +59,7 @@ func NewClient(hub *Hub, conn *websocket.Conn, clientType string, clientInfo any
 		Conn:       conn,
 		clientInfo: clientInfo,
 		send:       make(chan []byte, bufSize),
+		done:       make(chan struct{}),
 		dispatcher: noopDispatcher,
 	}, nil
 }
@@ -73,6 +76,11 @@ func (c *Client) SetClientInfo(clientInfo any) {
 	c.clientInfo = clientInfo
 }
 
+// Done is closed when the client is destroyed, the work pushing messages to the client should stop then
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
 func (c *Client) RegisterMiddleware(mds []TMiddleware) {
 	next := noopDispatcher
 	for i := len(mds) - 1; i >= 0; i-- {
@@ -41,6 +41,7 @@ func (h *Hub) Run() {
 			if _, ok := h.clients[client.ID]; ok {
 				delete(h.clients, client.ID)
 				close(client.send)
+				close(client.done)
 				afterDestroy := client.AfterDestroy
 				if afterDestroy != nil {
 					go afterDestroy()
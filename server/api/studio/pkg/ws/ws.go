@@ -6,6 +6,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ws/middlewares/batch_ngql"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ws/middlewares/importtask"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ws/middlewares/llm"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ws/middlewares/logger"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ws/middlewares/ngql"
@@ -43,6 +44,7 @@ func ServeWebSocket(hub *utils.Hub, w http.ResponseWriter, r *http.Request, clie
 		batch_ngql.Middleware,
 		ngql.Middleware,
 		llm.Middleware,
+		importtask.Middleware,
 	})
 	client.Serve()
 }
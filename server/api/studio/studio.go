@@ -9,12 +9,15 @@ import (
 	"github.com/vesoft-inc/go-pkg/middleware"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/config"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/handler"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/service/importer"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/internal/svc"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/auth"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/client"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/llm"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/logging"
 	studioMiddleware "github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/middleware"
+	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/secrets"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/server"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/utils"
 	"github.com/vesoft-inc/nebula-studio/server/api/studio/pkg/ws"
@@ -31,6 +34,8 @@ var (
 	//go:embed assets/*
 	embedAssets embed.FS
 	configFile  = flag.String("f", "etc/studio-api.yaml", "the config file")
+	// rotateSecrets re-encrypts the secrets saved by the current master key and exits, the previous keys should be configured to decrypt them
+	rotateSecrets = flag.Bool("rotate-secrets", false, "re-encrypt the secrets saved by the current master key and exit")
 )
 
 func main() {
@@ -51,7 +56,18 @@ func main() {
 	if err := c.InitConfig(); err != nil {
 		zap.L().Fatal("init config failed", zap.Error(err))
 	}
+	if err := secrets.Init(c.Secrets); err != nil {
+		zap.L().Fatal("init secrets failed", zap.Error(err))
+	}
 	server.InitDB(&c, nil)
+	if *rotateSecrets {
+		rotated, err := service.RotateSecrets()
+		if err != nil {
+			zap.L().Fatal("rotate secrets failed", zap.Error(err))
+		}
+		fmt.Printf("%d secrets are rotated\n", rotated)
+		return
+	}
 
 	svcCtx := svc.NewServiceContext(c)
 	opts := []rest.RunOption{
@@ -65,6 +81,8 @@ func main() {
 
 	defer server.Stop()
 	waitForCalled := proc.AddWrapUpListener(func() {
+		// stop the running tasks and save their progress before the clients are closed
+		importer.GetTaskMgr().InterruptTasks()
 		client.ClearClients()
 	})
 	defer waitForCalled()
@@ -105,6 +123,11 @@ func main() {
 		return svcCtx.ResponseHandler.GetStatusBody(nil, nil, err)
 	})
 	go llm.InitSchedule()
+	go service.StartImportScheduler(svcCtx)
+	service.StartWebhooks(svcCtx)
+	if c.Import.AutoResume {
+		go service.ResumeInterruptedTasks(svcCtx)
+	}
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
 }